@@ -3,15 +3,100 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"gocli/internal/shell"
 )
 
+// Коды завершения при ошибках запуска, как в bash.
+const (
+	statusUsage    = 2   // Неверные аргументы командной строки
+	statusNotFound = 127 // Файл скрипта не найден
+)
+
+// main запускает shell в одном из режимов:
+//
+//	gocli                        - интерактивный REPL (или скрипт из stdin, если это не терминал)
+//	gocli script.sh [args...]    - выполнение скрипта с позиционными параметрами
+//	gocli -c 'cmd' [name [args]] - выполнение команд из строки; name задает $0
+//
+// Интерактивный shell перед первым приглашением выполняет файл инициализации
+// ($GOCLI_RC или ~/.goclirc); --rcfile file задает другой файл, --norc отключает его.
+//
+// Код завершения процесса - код завершения shell'а (аргумент exit или код последней команды).
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run разбирает аргументы командной строки, выполняет shell в нужном режиме
+// и возвращает код завершения.
+func run(args []string) int {
 	sh := shell.NewShell()
 
-	if err := sh.Run(); err != nil {
+	// Параметры файла инициализации идут перед остальными аргументами, как в bash
+	for len(args) > 0 {
+		if args[0] == "--norc" {
+			sh.SetRCFile("")
+			args = args[1:]
+		} else if args[0] == "--rcfile" {
+			if len(args) < 2 {
+				fmt.Fprintln(os.Stderr, "Error: --rcfile: option requires an argument")
+				return statusUsage
+			}
+			sh.SetRCFile(args[1])
+			args = args[2:]
+		} else {
+			break
+		}
+	}
+
+	var (
+		status int
+		err    error
+	)
+	switch {
+	case len(args) > 0 && args[0] == "-c":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: -c: option requires an argument")
+			return statusUsage
+		}
+		if len(args) > 2 {
+			sh.SetArgs(args[2], args[3:])
+		}
+		status, err = sh.RunCommand(args[1])
+
+	case len(args) > 0 && strings.HasPrefix(args[0], "-"):
+		fmt.Fprintf(os.Stderr, "Error: %s: invalid option\n", args[0])
+		fmt.Fprintln(os.Stderr, "Usage: gocli [--norc | --rcfile file] [script [args...] | -c command [name [args...]]]")
+		return statusUsage
+
+	case len(args) > 0:
+		file, openErr := os.Open(args[0])
+		if openErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", openErr)
+			return statusNotFound
+		}
+		defer file.Close()
+		sh.SetArgs(args[0], args[1:])
+		status, err = sh.RunScript(file)
+
+	case isTerminal(os.Stdin):
+		status, err = sh.Run()
+
+	default:
+		// Ввод перенаправлен (echo 'cmd' | gocli): читаем его как скрипт, без приглашений
+		status, err = sh.RunScript(os.Stdin)
+	}
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
+	return status
+}
+
+// isTerminal проверяет, что файл - терминал (символьное устройство).
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
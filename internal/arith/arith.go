@@ -0,0 +1,344 @@
+// Package arith реализует вычисление арифметических выражений shell'а ($((...))).
+// Поддерживаются целые 64-битные числа, операторы с приоритетами как в C,
+// переменные (значение переменной само вычисляется как выражение),
+// присваивания (=, +=, ...), инкремент и декремент, условный оператор и битовые операции.
+package arith
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxDepth ограничивает вложенность вычисления значений переменных,
+// чтобы переменная, ссылающаяся на саму себя (x=x), не приводила к бесконечной рекурсии.
+const maxDepth = 64
+
+// ErrDivisionByZero возвращается при делении или взятии остатка по нулю.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Variables предоставляет вычислителю доступ к переменным shell'а.
+type Variables interface {
+	// Get возвращает значение переменной и флаг существования.
+	Get(name string) (string, bool)
+	// Set устанавливает значение переменной.
+	Set(name, value string)
+}
+
+// Error описывает ошибку вычисления выражения.
+type Error struct {
+	Expr string // Исходное выражение
+	Err  error  // Причина ошибки
+}
+
+// Error возвращает текстовое описание ошибки в формате "выражение: причина".
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", strings.TrimSpace(e.Expr), e.Err)
+}
+
+// Unwrap возвращает причину ошибки.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Evaluate вычисляет арифметическое выражение expr.
+// Переменные читаются и изменяются через vars; неустановленная или пустая переменная равна 0.
+// Возвращает значение выражения или *Error при синтаксической ошибке,
+// делении на ноль и других ошибках вычисления.
+func Evaluate(expr string, vars Variables) (int64, error) {
+	value, err := (&evaluator{vars: vars}).evaluate(expr)
+	if err != nil {
+		var arithErr *Error
+		if errors.As(err, &arithErr) {
+			return 0, err
+		}
+		return 0, &Error{Expr: expr, Err: err}
+	}
+	return value, nil
+}
+
+// evaluator вычисляет дерево выражения.
+type evaluator struct {
+	vars  Variables
+	depth int // Текущая глубина вычисления значений переменных
+}
+
+// evaluate разбирает и вычисляет выражение.
+func (ev *evaluator) evaluate(expr string) (int64, error) {
+	root, err := parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	return ev.eval(root)
+}
+
+// eval вычисляет узел дерева выражения.
+func (ev *evaluator) eval(n node) (int64, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return n.value, nil
+	case *variableNode:
+		return ev.variable(n.name)
+	case *unaryNode:
+		return ev.evalUnary(n)
+	case *incDecNode:
+		old, err := ev.variable(n.name)
+		if err != nil {
+			return 0, err
+		}
+		ev.vars.Set(n.name, strconv.FormatInt(old+n.delta, 10))
+		if n.prefix {
+			return old + n.delta, nil
+		}
+		return old, nil
+	case *binaryNode:
+		return ev.evalBinary(n)
+	case *assignNode:
+		return ev.evalAssign(n)
+	case *ternaryNode:
+		cond, err := ev.eval(n.cond)
+		if err != nil {
+			return 0, err
+		}
+		if cond != 0 {
+			return ev.eval(n.then)
+		}
+		return ev.eval(n.otherwise)
+	default:
+		return 0, fmt.Errorf("unknown expression node %T", n)
+	}
+}
+
+// variable возвращает значение переменной. Значение вычисляется как выражение,
+// поэтому переменная может содержать как число, так и другое выражение (например, имя переменной).
+func (ev *evaluator) variable(name string) (int64, error) {
+	value, _ := ev.vars.Get(name)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n, nil
+	}
+
+	if ev.depth >= maxDepth {
+		return 0, fmt.Errorf("expression recursion level exceeded (error token is %q)", name)
+	}
+	ev.depth++
+	defer func() { ev.depth-- }()
+
+	// Ошибка, уже содержащая выражение (из более глубокого уровня), передается без изменений
+	result, err := ev.evaluate(value)
+	var arithErr *Error
+	if errors.As(err, &arithErr) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, &Error{Expr: value, Err: err}
+	}
+	return result, nil
+}
+
+// evalUnary вычисляет унарный оператор.
+func (ev *evaluator) evalUnary(n *unaryNode) (int64, error) {
+	value, err := ev.eval(n.operand)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "-":
+		return -value, nil
+	case "!":
+		return boolToInt(value == 0), nil
+	case "~":
+		return ^value, nil
+	default:
+		return value, nil
+	}
+}
+
+// evalBinary вычисляет бинарный оператор.
+// Операторы &&, || вычисляются сокращенно: правый операнд не вычисляется,
+// если результат определяется левым (это важно для присваиваний в правой части).
+func (ev *evaluator) evalBinary(n *binaryNode) (int64, error) {
+	left, err := ev.eval(n.left)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "&&":
+		if left == 0 {
+			return 0, nil
+		}
+	case "||":
+		if left != 0 {
+			return 1, nil
+		}
+	}
+
+	right, err := ev.eval(n.right)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return boolToInt(right != 0), nil
+	case ",":
+		return right, nil
+	default:
+		return apply(n.op, left, right)
+	}
+}
+
+// evalAssign вычисляет присваивание и сохраняет результат в переменную.
+func (ev *evaluator) evalAssign(n *assignNode) (int64, error) {
+	value, err := ev.eval(n.value)
+	if err != nil {
+		return 0, err
+	}
+
+	if n.op != "=" {
+		current, err := ev.variable(n.name)
+		if err != nil {
+			return 0, err
+		}
+		value, err = apply(strings.TrimSuffix(n.op, "="), current, value)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	ev.vars.Set(n.name, strconv.FormatInt(value, 10))
+	return value, nil
+}
+
+// apply применяет арифметический, битовый или оператор сравнения к двум значениям.
+func apply(op string, left, right int64) (int64, error) {
+	switch op {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/", "%":
+		if right == 0 {
+			return 0, ErrDivisionByZero
+		}
+		if op == "/" {
+			return left / right, nil
+		}
+		return left % right, nil
+	case "**":
+		if right < 0 {
+			return 0, errors.New("exponent less than 0")
+		}
+		return power(left, right), nil
+	case "<<":
+		return left << (uint64(right) & 63), nil
+	case ">>":
+		return left >> (uint64(right) & 63), nil
+	case "&":
+		return left & right, nil
+	case "|":
+		return left | right, nil
+	case "^":
+		return left ^ right, nil
+	case "==":
+		return boolToInt(left == right), nil
+	case "!=":
+		return boolToInt(left != right), nil
+	case "<":
+		return boolToInt(left < right), nil
+	case ">":
+		return boolToInt(left > right), nil
+	case "<=":
+		return boolToInt(left <= right), nil
+	case ">=":
+		return boolToInt(left >= right), nil
+	default:
+		return 0, fmt.Errorf("unknown operator %q", op)
+	}
+}
+
+// power возводит base в неотрицательную степень exp быстрым возведением в степень.
+func power(base, exp int64) int64 {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
+// parseNumber разбирает числовую константу: десятичную (10), восьмеричную (017),
+// шестнадцатеричную (0x1F) или в произвольной системе счисления base#digits (2#101, 16#ff).
+func parseNumber(text string) (int64, error) {
+	base := 10
+	digits := text
+
+	switch {
+	case strings.Contains(text, "#"):
+		parts := strings.SplitN(text, "#", 2)
+		b, err := strconv.Atoi(parts[0])
+		if err != nil || b < 2 || b > 64 {
+			return 0, fmt.Errorf("invalid arithmetic base (error token is %q)", text)
+		}
+		base, digits = b, parts[1]
+	case strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X"):
+		base, digits = 16, text[2:]
+	case len(text) > 1 && text[0] == '0':
+		base, digits = 8, text[1:]
+	}
+
+	if digits == "" {
+		return 0, fmt.Errorf("invalid number (error token is %q)", text)
+	}
+
+	var value int64
+	for _, r := range digits {
+		digit := digitValue(r, base)
+		if digit < 0 || digit >= base {
+			return 0, fmt.Errorf("value too great for base (error token is %q)", text)
+		}
+		value = value*int64(base) + int64(digit)
+	}
+	return value, nil
+}
+
+// digitValue возвращает значение цифры r как в bash: 0-9, затем a-z, A-Z, @ и _.
+// Для оснований не больше 36 строчные и прописные буквы равнозначны.
+func digitValue(r rune, base int) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'a' && r <= 'z':
+		return int(r-'a') + 10
+	case r >= 'A' && r <= 'Z':
+		if base <= 36 {
+			return int(r-'A') + 10
+		}
+		return int(r-'A') + 36
+	case r == '@':
+		return 62
+	case r == '_':
+		return 63
+	default:
+		return -1
+	}
+}
+
+// boolToInt преобразует логическое значение в 1 или 0.
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,156 @@
+package arith
+
+import (
+	"errors"
+	"testing"
+)
+
+// mapVariables - хранилище переменных для тестов.
+type mapVariables map[string]string
+
+func (m mapVariables) Get(name string) (string, bool) {
+	value, ok := m[name]
+	return value, ok
+}
+
+func (m mapVariables) Set(name, value string) {
+	m[name] = value
+}
+
+// TestEvaluate проверяет вычисление выражений: приоритеты операторов,
+// системы счисления, сравнения, логические и битовые операции.
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected int64
+	}{
+		{"", 0},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"7 / 2", 3},
+		{"-7 % 3", -1},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", 4},
+		{"0x1F", 31},
+		{"010", 8},
+		{"2#101", 5},
+		{"16#ff", 255},
+		{"64#_", 63},
+		{"1 < 2", 1},
+		{"2 <= 1", 0},
+		{"3 == 3 && 4 != 5", 1},
+		{"0 || 0", 0},
+		{"!0", 1},
+		{"~0", -1},
+		{"6 & 3 | 8 ^ 1", 11},
+		{"1 << 4 >> 2", 4},
+		{"1 ? 2 : 3", 2},
+		{"0 ? 2 : 1 ? 4 : 5", 4},
+		{"1, 2, 3", 3},
+		{"1 + 2 == 3", 1},
+		{"1 | 2 == 2", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Evaluate(tt.expr, mapVariables{})
+			if err != nil {
+				t.Fatalf("Evaluate(%q) error = %v", tt.expr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Evaluate(%q) = %d, expected %d", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvaluate_Variables проверяет чтение переменных, присваивания, инкремент и декремент.
+func TestEvaluate_Variables(t *testing.T) {
+	vars := mapVariables{"x": "5", "ref": "x * 2", "empty": ""}
+
+	tests := []struct {
+		expr     string
+		expected int64
+		variable string
+		value    string
+	}{
+		{"x + 1", 6, "x", "5"},
+		{"ref + 1", 11, "ref", "x * 2"},
+		{"empty + unset", 0, "unset", ""},
+		{"y = 3", 3, "y", "3"},
+		{"x += 2", 7, "x", "7"},
+		{"x <<= 1", 14, "x", "14"},
+		{"x++", 14, "x", "15"},
+		{"++x", 16, "x", "16"},
+		{"x--", 16, "x", "15"},
+		{"a = b = 4", 4, "a", "4"},
+		{"0 && (z = 1)", 0, "z", ""},
+		{"1 || (z = 1)", 1, "z", ""},
+		{"1 ? (w = 1) : (w = 2)", 1, "w", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Evaluate(tt.expr, vars)
+			if err != nil {
+				t.Fatalf("Evaluate(%q) error = %v", tt.expr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Evaluate(%q) = %d, expected %d", tt.expr, got, tt.expected)
+			}
+			if vars[tt.variable] != tt.value {
+				t.Errorf("after %q %s = %q, expected %q", tt.expr, tt.variable, vars[tt.variable], tt.value)
+			}
+		})
+	}
+}
+
+// TestEvaluate_Errors проверяет ошибки вычисления.
+func TestEvaluate_Errors(t *testing.T) {
+	tests := []string{
+		"1 +",
+		"(1 + 2",
+		"1 2",
+		"2 ** -1",
+		"08",
+		"2#3",
+		"1 ? 2",
+		"3 = 4",
+		"++1",
+		"$x",
+		"self",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			vars := mapVariables{"self": "self + 1"}
+			_, err := Evaluate(expr, vars)
+			if err == nil {
+				t.Fatalf("Evaluate(%q) expected error", expr)
+			}
+			var arithErr *Error
+			if !errors.As(err, &arithErr) {
+				t.Errorf("Evaluate(%q) error type = %T, expected *Error", expr, err)
+			}
+		})
+	}
+
+	// Ошибка в значении переменной сообщается один раз, с выражением, где она возникла
+	vars := mapVariables{"self": "self + 1", "a": "b", "b": "1 +"}
+	messages := map[string]string{
+		"self": `self + 1: expression recursion level exceeded (error token is "self")`,
+		"a":    "1 +: syntax error: operand expected",
+	}
+	for expr, expected := range messages {
+		if _, err := Evaluate(expr, vars); err == nil || err.Error() != expected {
+			t.Errorf("Evaluate(%q) error = %v, expected %q", expr, err, expected)
+		}
+	}
+
+	for _, expr := range []string{"1 / 0", "5 % (2 - 2)", "x /= 0"} {
+		if _, err := Evaluate(expr, mapVariables{"x": "1"}); !errors.Is(err, ErrDivisionByZero) {
+			t.Errorf("Evaluate(%q) error = %v, expected ErrDivisionByZero", expr, err)
+		}
+	}
+}
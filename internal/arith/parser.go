@@ -0,0 +1,283 @@
+package arith
+
+import (
+	"fmt"
+)
+
+// node представляет узел дерева арифметического выражения.
+type node interface{}
+
+// numberNode - числовая константа.
+type numberNode struct {
+	value int64
+}
+
+// variableNode - ссылка на переменную shell'а.
+type variableNode struct {
+	name string
+}
+
+// unaryNode - унарный оператор (+, -, !, ~).
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+// incDecNode - инкремент или декремент переменной (++x, x++, --x, x--).
+type incDecNode struct {
+	name   string
+	delta  int64
+	prefix bool // Префиксная форма возвращает новое значение, постфиксная - старое
+}
+
+// binaryNode - бинарный оператор, включая запятую.
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+// assignNode - присваивание (=) или составное присваивание (+=, <<=, ...).
+type assignNode struct {
+	name  string
+	op    string
+	value node
+}
+
+// ternaryNode - условный оператор cond ? then : otherwise.
+type ternaryNode struct {
+	cond, then, otherwise node
+}
+
+// binaryPrecedence задает приоритеты бинарных операторов как в C:
+// чем больше число, тем сильнее связывает оператор.
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"|":  3,
+	"^":  4,
+	"&":  5,
+	"==": 6, "!=": 6,
+	"<": 7, ">": 7, "<=": 7, ">=": 7,
+	"<<": 8, ">>": 8,
+	"+": 9, "-": 9,
+	"*": 10, "/": 10, "%": 10,
+	"**": 11,
+}
+
+// assignmentOperators содержит операторы присваивания.
+var assignmentOperators = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"<<=": true, ">>=": true, "&=": true, "^=": true, "|=": true,
+}
+
+// parser строит дерево выражения методом рекурсивного спуска с учетом приоритетов.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse разбирает выражение целиком. Пустое выражение равно нулю.
+func parse(expr string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().typ == tokenEOF {
+		return &numberNode{value: 0}, nil
+	}
+
+	root, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.typ != tokenEOF {
+		return nil, fmt.Errorf("syntax error in expression (error token is %q)", tok.value)
+	}
+	return root, nil
+}
+
+// peek возвращает текущий токен.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// peekOperator проверяет, что текущий токен - оператор op.
+func (p *parser) peekOperator(op string) bool {
+	tok := p.peek()
+	return tok.typ == tokenOperator && tok.value == op
+}
+
+// next возвращает текущий токен и переходит к следующему.
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseComma разбирает последовательность выражений через запятую.
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseAssignment()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator(",") {
+		p.next()
+		right, err := p.parseAssignment()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: ",", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAssignment разбирает присваивание (правоассоциативное) или условное выражение.
+func (p *parser) parseAssignment() (node, error) {
+	if p.peek().typ == tokenName {
+		next := p.tokens[p.pos+1]
+		if next.typ == tokenOperator && assignmentOperators[next.value] {
+			name := p.next().value
+			op := p.next().value
+			value, err := p.parseAssignment()
+			if err != nil {
+				return nil, err
+			}
+			return &assignNode{name: name, op: op, value: value}, nil
+		}
+	}
+	return p.parseTernary()
+}
+
+// parseTernary разбирает условный оператор cond ? then : otherwise.
+func (p *parser) parseTernary() (node, error) {
+	cond, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekOperator("?") {
+		return cond, nil
+	}
+	p.next()
+
+	then, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekOperator(":") {
+		return nil, fmt.Errorf("syntax error: `:' expected for conditional expression")
+	}
+	p.next()
+
+	otherwise, err := p.parseAssignment()
+	if err != nil {
+		return nil, err
+	}
+	return &ternaryNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// parseBinary разбирает бинарные операторы с приоритетом не ниже minPrecedence.
+// Оператор ** правоассоциативен, остальные - левоассоциативны.
+func (p *parser) parseBinary(minPrecedence int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		precedence, ok := binaryPrecedence[tok.value]
+		if tok.typ != tokenOperator || !ok || precedence < minPrecedence {
+			return left, nil
+		}
+		p.next()
+
+		nextPrecedence := precedence + 1
+		if tok.value == "**" {
+			nextPrecedence = precedence
+		}
+		right, err := p.parseBinary(nextPrecedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: tok.value, left: left, right: right}
+	}
+}
+
+// parseUnary разбирает унарные операторы и префиксные ++/--.
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.typ == tokenOperator {
+		switch tok.value {
+		case "+", "-", "!", "~":
+			p.next()
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: tok.value, operand: operand}, nil
+		case "++", "--":
+			p.next()
+			name := p.next()
+			if name.typ != tokenName {
+				return nil, fmt.Errorf("syntax error: %s requires a variable", tok.value)
+			}
+			return &incDecNode{name: name.value, delta: incDecDelta(tok.value), prefix: true}, nil
+		}
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix разбирает операнд и постфиксные ++/--.
+func (p *parser) parsePostfix() (node, error) {
+	operand, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	if variable, ok := operand.(*variableNode); ok && (p.peekOperator("++") || p.peekOperator("--")) {
+		op := p.next().value
+		return &incDecNode{name: variable.name, delta: incDecDelta(op)}, nil
+	}
+	return operand, nil
+}
+
+// parsePrimary разбирает число, переменную или выражение в скобках.
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch {
+	case tok.typ == tokenNumber:
+		value, err := parseNumber(tok.value)
+		if err != nil {
+			return nil, err
+		}
+		return &numberNode{value: value}, nil
+	case tok.typ == tokenName:
+		return &variableNode{name: tok.value}, nil
+	case tok.typ == tokenOperator && tok.value == "(":
+		inner, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekOperator(")") {
+			return nil, fmt.Errorf("syntax error: missing `)'")
+		}
+		p.next()
+		return inner, nil
+	case tok.typ == tokenEOF:
+		return nil, fmt.Errorf("syntax error: operand expected")
+	default:
+		return nil, fmt.Errorf("syntax error: operand expected (error token is %q)", tok.value)
+	}
+}
+
+// incDecDelta возвращает изменение значения для оператора ++ или --.
+func incDecDelta(op string) int64 {
+	if op == "++" {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,69 @@
+package arith
+
+import (
+	"testing"
+)
+
+// TestTokenize проверяет разбиение выражения на токены с выбором самого длинного оператора.
+func TestTokenize(t *testing.T) {
+	tokens, err := tokenize("x<<=0x1F**2 !=y++")
+	if err != nil {
+		t.Fatalf("tokenize() error = %v", err)
+	}
+
+	expected := []token{
+		{typ: tokenName, value: "x"},
+		{typ: tokenOperator, value: "<<="},
+		{typ: tokenNumber, value: "0x1F"},
+		{typ: tokenOperator, value: "**"},
+		{typ: tokenNumber, value: "2"},
+		{typ: tokenOperator, value: "!="},
+		{typ: tokenName, value: "y"},
+		{typ: tokenOperator, value: "++"},
+		{typ: tokenEOF},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("tokenize() returned %d tokens, expected %d: %v", len(tokens), len(expected), tokens)
+	}
+	for i := range expected {
+		if tokens[i] != expected[i] {
+			t.Errorf("token %d = %v, expected %v", i, tokens[i], expected[i])
+		}
+	}
+
+	if _, err := tokenize("1 @ 2"); err == nil {
+		t.Error("expected error for invalid operator")
+	}
+}
+
+// TestParse проверяет структуру дерева: приоритеты и ассоциативность операторов.
+func TestParse(t *testing.T) {
+	root, err := parse("a = 1 + 2 * 3")
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+
+	assign, ok := root.(*assignNode)
+	if !ok || assign.name != "a" || assign.op != "=" {
+		t.Fatalf("root = %#v, expected assignment to a", root)
+	}
+	sum, ok := assign.value.(*binaryNode)
+	if !ok || sum.op != "+" {
+		t.Fatalf("assignment value = %#v, expected +", assign.value)
+	}
+	if product, ok := sum.right.(*binaryNode); !ok || product.op != "*" {
+		t.Errorf("right operand of + = %#v, expected *", sum.right)
+	}
+
+	// ** правоассоциативен: 2 ** (3 ** 2)
+	root, err = parse("2 ** 3 ** 2")
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if pow, ok := root.(*binaryNode); !ok {
+		t.Errorf("root = %#v, expected binary node", root)
+	} else if _, ok := pow.right.(*binaryNode); !ok {
+		t.Errorf("** should be right-associative, got %#v", root)
+	}
+}
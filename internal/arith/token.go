@@ -0,0 +1,83 @@
+package arith
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenType определяет тип токена арифметического выражения.
+type tokenType int
+
+const (
+	tokenNumber   tokenType = iota // Числовая константа (10, 0x1F, 017, 2#101)
+	tokenName                      // Имя переменной
+	tokenOperator                  // Оператор или скобка
+	tokenEOF                       // Конец выражения
+)
+
+// token представляет токен арифметического выражения.
+type token struct {
+	typ   tokenType
+	value string
+}
+
+// operators содержит операторы выражений; многосимвольные операторы идут первыми,
+// чтобы при разборе выбирался самый длинный подходящий оператор.
+var operators = []string{
+	"<<=", ">>=",
+	"**", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||",
+	"+=", "-=", "*=", "/=", "%=", "&=", "^=", "|=",
+	"+", "-", "*", "/", "%", "<", ">", "=", "!", "~", "&", "^", "|", "?", ":", ",", "(", ")",
+}
+
+// tokenize разбивает арифметическое выражение на токены.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && isNumberChar(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, value: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenName, value: string(runes[start:i])})
+		default:
+			op := matchOperator(string(runes[i:]))
+			if op == "" {
+				return nil, fmt.Errorf("syntax error: invalid arithmetic operator (error token is %q)", string(runes[i:]))
+			}
+			tokens = append(tokens, token{typ: tokenOperator, value: op})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{typ: tokenEOF}), nil
+}
+
+// matchOperator возвращает оператор, с которого начинается строка s, или пустую строку.
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// isNumberChar проверяет, может ли символ входить в запись числа
+// (включая шестнадцатеричные цифры и форму base#digits).
+func isNumberChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '@' || r == '#'
+}
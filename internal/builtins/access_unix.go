@@ -0,0 +1,10 @@
+//go:build !windows
+
+package builtins
+
+import "syscall"
+
+// accessible проверяет право доступа mode к файлу для текущего пользователя (access(2)).
+func accessible(path string, mode uint32) bool {
+	return syscall.Access(path, mode) == nil
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package builtins
+
+import "os"
+
+// accessible проверяет право доступа mode к файлу по битам прав владельца:
+// в Windows нет access(2), и права других пользователей не проверяются.
+func accessible(path string, mode uint32) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return uint32(info.Mode().Perm())&(mode<<6) != 0
+}
@@ -0,0 +1,60 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+
+	"gocli/internal/jobs"
+)
+
+const BgCommandName = "bg"
+
+// BgCommand реализует встроенную команду bg.
+// Возобновляет приостановленные процессы задания в фоне (сигналом SIGCONT).
+type BgCommand struct {
+	table *jobs.Table // Таблица заданий shell'а
+}
+
+// NewBgCommand создает новый экземпляр команды bg для таблицы заданий table.
+func NewBgCommand(table *jobs.Table) *BgCommand {
+	return &BgCommand{table: table}
+}
+
+// Name возвращает имя команды bg.
+func (b *BgCommand) Name() string {
+	return BgCommandName
+}
+
+// Execute выполняет команду bg.
+// Без аргументов выбирает текущее задание. Для каждого задания выводит
+// строку "[N]+ команда &" и отправляет его процессам SIGCONT.
+func (b *BgCommand) Execute(args []string, _ map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	exitCode := 0
+	for _, spec := range args {
+		job, err := b.table.Find(spec)
+		if err != nil {
+			fmt.Fprintf(stderr, "bg: %v\n", err)
+			exitCode = 1
+			continue
+		}
+
+		if job.State() == jobs.Done {
+			fmt.Fprintf(stderr, "bg: job %d has already completed\n", job.ID)
+			exitCode = 1
+			continue
+		}
+
+		if err := job.Continue(); err != nil {
+			fmt.Fprintf(stderr, "bg: %v\n", err)
+			exitCode = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "[%d]%c %s &\n", job.ID, b.table.Marker(job), job.Command)
+	}
+
+	return exitCode
+}
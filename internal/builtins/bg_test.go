@@ -0,0 +1,43 @@
+package builtins
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gocli/internal/jobs"
+)
+
+// TestBgCommand_Execute тестирует возобновление выполняющегося задания командой bg.
+func TestBgCommand_Execute(t *testing.T) {
+	table := jobs.NewTable()
+	release := make(chan struct{})
+	defer close(release)
+	startTestJob(table, "sleep 10", 0, release)
+
+	command := NewBgCommand(table)
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{"%1"}, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("BgCommand.Execute() exitCode = %d, stderr = %q", exitCode, stderr.String())
+	}
+	if got := stdout.String(); got != "[1]+ sleep 10 &\n" {
+		t.Errorf("BgCommand.Execute() output = %q, expected %q", got, "[1]+ sleep 10 &\n")
+	}
+}
+
+// TestBgCommand_ExecuteCompleted тестирует bg для завершившегося задания.
+func TestBgCommand_ExecuteCompleted(t *testing.T) {
+	table := jobs.NewTable()
+	table.Start("true", func(job *jobs.Job) int { return 0 }).Wait()
+
+	command := NewBgCommand(table)
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{}, nil, nil, &stdout, &stderr); exitCode != 1 {
+		t.Errorf("BgCommand.Execute() exitCode = %d, expected 1", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "already completed") {
+		t.Errorf("stderr = %q, expected 'already completed'", stderr.String())
+	}
+}
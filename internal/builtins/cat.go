@@ -25,7 +25,7 @@ func (c *CatCommand) Name() string {
 // Execute выполняет команду cat.
 // Если аргументы не переданы, читает из стандартного ввода.
 // Иначе читает и выводит содержимое указанных файлов.
-func (c *CatCommand) Execute(args []string, _ map[string]string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+func (c *CatCommand) Execute(args []string, env map[string]string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
 	if len(args) == 0 {
 		_, err := io.Copy(stdout, stdin)
 		if err != nil {
@@ -36,7 +36,7 @@ func (c *CatCommand) Execute(args []string, _ map[string]string, stdin io.Reader
 	}
 
 	for _, filename := range args {
-		file, err := os.Open(filename)
+		file, err := OpenFile(env[PwdVariable], filename, os.O_RDONLY, 0)
 		if err != nil {
 			fmt.Fprintf(stderr, "cat: %s: %v\n", filename, err)
 			return 1
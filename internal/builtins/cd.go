@@ -1,14 +1,26 @@
 package builtins
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+
+	"gocli/internal/environment"
 )
 
-type CdCommand struct{}
+type CdCommand struct {
+	environment *environment.Environment // Окружение с рабочим каталогом shell'а (может быть nil)
+}
 
-func NewCdCommand() *CdCommand { return &CdCommand{} }
+// NewCdCommand создает команду cd. Команда меняет рабочий каталог shell'а, хранящийся
+// в окружении env, и записывает в него переменные PWD и OLDPWD; рабочий каталог процесса
+// не меняется. При env == nil команда меняет рабочий каталог процесса.
+func NewCdCommand(env *environment.Environment) *CdCommand {
+	return &CdCommand{environment: env}
+}
 
 func (c *CdCommand) Name() string { return "cd" }
 
@@ -28,9 +40,54 @@ func (c *CdCommand) Execute(args []string, _ map[string]string, _ io.Reader, _ i
 		return 1
 	}
 
-	if err := os.Chdir(target); err != nil {
+	if c.environment == nil {
+		if err := os.Chdir(target); err != nil {
+			fmt.Fprintf(stderr, "cd: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	oldDir := c.environment.Dir()
+	dir, err := changeDir(oldDir, target)
+	if err != nil {
 		fmt.Fprintf(stderr, "cd: %v\n", err)
 		return 1
 	}
+
+	c.environment.SetDir(dir)
+	c.environment.Set("OLDPWD", oldDir)
+	c.environment.Set(PwdVariable, dir)
 	return 0
 }
+
+// changeDir проверяет, что в каталог target (относительно dir) можно перейти,
+// и возвращает его абсолютный путь. Путь строится логически, как в bash:
+// .. убирает предыдущий компонент пути, а не переходит в родителя символической ссылки.
+func changeDir(dir, target string) (string, error) {
+	path := ResolvePath(dir, target)
+	if !filepath.IsAbs(path) {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		path = abs
+	}
+	path = filepath.Clean(path)
+
+	info, err := os.Stat(path)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return "", fmt.Errorf("%s: %w", target, pathErr.Err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s: not a directory", target)
+	}
+	if !accessible(path, accessExecute) {
+		return "", fmt.Errorf("%s: permission denied", target)
+	}
+	return path, nil
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"gocli/internal/environment"
 )
 
 func TestCdToExplicitDirectory(t *testing.T) {
@@ -18,7 +20,7 @@ func TestCdToExplicitDirectory(t *testing.T) {
 
 	tmpDir := t.TempDir()
 
-	cmd := NewCdCommand()
+	cmd := NewCdCommand(nil)
 	var stderr bytes.Buffer
 
 	exitCode := cmd.Execute(
@@ -57,7 +59,7 @@ func TestCdWithoutArgumentsGoesHome(t *testing.T) {
 		t.Fatalf("cannot get home directory: %v", err)
 	}
 
-	cmd := NewCdCommand()
+	cmd := NewCdCommand(nil)
 	var stderr bytes.Buffer
 
 	exitCode := cmd.Execute(
@@ -83,7 +85,7 @@ func TestCdWithoutArgumentsGoesHome(t *testing.T) {
 }
 
 func TestCdTooManyArguments(t *testing.T) {
-	cmd := NewCdCommand()
+	cmd := NewCdCommand(nil)
 	var stderr bytes.Buffer
 
 	exitCode := cmd.Execute(
@@ -102,3 +104,79 @@ func TestCdTooManyArguments(t *testing.T) {
 		t.Fatalf("expected error message in stderr")
 	}
 }
+
+func TestCdUpdatesPwdAndOldPwd(t *testing.T) {
+	origWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("cannot get working directory: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(origWd)
+	}()
+
+	tmpDir := t.TempDir()
+	env := environment.NewEnvironment()
+	cmd := NewCdCommand(env)
+	var stderr bytes.Buffer
+
+	if exitCode := cmd.Execute([]string{tmpDir}, nil, nil, nil, &stderr); exitCode != 0 {
+		t.Fatalf("cd returned non-zero exit code: %d, stderr=%s", exitCode, stderr.String())
+	}
+
+	if pwd, _ := env.Get("PWD"); pwd != tmpDir {
+		t.Errorf("expected PWD=%s, got %s", tmpDir, pwd)
+	}
+	if oldPwd, _ := env.Get("OLDPWD"); oldPwd != origWd {
+		t.Errorf("expected OLDPWD=%s, got %s", origWd, oldPwd)
+	}
+
+	// Неудачная смена каталога не меняет переменные
+	if exitCode := cmd.Execute([]string{filepath.Join(tmpDir, "missing")}, nil, nil, nil, &stderr); exitCode == 0 {
+		t.Fatal("expected non-zero exit code for missing directory")
+	}
+	if pwd, _ := env.Get("PWD"); pwd != tmpDir {
+		t.Errorf("PWD changed after failed cd: %s", pwd)
+	}
+}
+
+// TestCdChangesShellDirectory проверяет, что cd с окружением меняет рабочий каталог
+// shell'а в окружении, а не каталог процесса, и отсчитывает относительный путь от него.
+func TestCdChangesShellDirectory(t *testing.T) {
+	origWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("cannot get working directory: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "sub"), 0o755); err != nil {
+		t.Fatalf("cannot create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "file"), nil, 0o644); err != nil {
+		t.Fatalf("cannot create file: %v", err)
+	}
+
+	env := environment.NewEnvironment()
+	cmd := NewCdCommand(env)
+	var stderr bytes.Buffer
+
+	for _, target := range []string{tmpDir, "sub", ".."} {
+		if exitCode := cmd.Execute([]string{target}, nil, nil, nil, &stderr); exitCode != 0 {
+			t.Fatalf("cd %s returned non-zero exit code: %d, stderr=%s", target, exitCode, stderr.String())
+		}
+	}
+	if dir := env.Dir(); dir != tmpDir {
+		t.Errorf("shell directory = %s, expected %s", dir, tmpDir)
+	}
+	if newWd, _ := os.Getwd(); newWd != origWd {
+		t.Errorf("process working directory changed to %s", newWd)
+	}
+
+	// Переход в файл - ошибка
+	stderr.Reset()
+	if exitCode := cmd.Execute([]string{"file"}, nil, nil, nil, &stderr); exitCode == 0 {
+		t.Fatal("expected non-zero exit code for a file")
+	}
+	if expected := "cd: file: not a directory\n"; stderr.String() != expected {
+		t.Errorf("stderr = %q, expected %q", stderr.String(), expected)
+	}
+}
@@ -0,0 +1,133 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gocli/internal/environment"
+)
+
+const DeclareCommandName = "declare"
+
+// DeclareCommand реализует встроенную команду declare.
+// Объявляет переменные (внутри функции - локальные) и выводит переменные и функции shell'а.
+type DeclareCommand struct {
+	environment *environment.Environment // Окружение с переменными и функциями shell'а
+}
+
+// NewDeclareCommand создает новый экземпляр команды declare для окружения env.
+func NewDeclareCommand(env *environment.Environment) *DeclareCommand {
+	return &DeclareCommand{environment: env}
+}
+
+// Name возвращает имя команды declare.
+func (d *DeclareCommand) Name() string {
+	return DeclareCommandName
+}
+
+// Execute выполняет команду declare.
+// Флаг -f выводит определения функций (всех или указанных), -F - только их имена.
+// Без флагов аргументы name[=value] объявляют переменные: внутри функции они
+// локальны, как при local. Без аргументов выводятся все переменные и функции.
+// Код возврата равен 1, если указанная функция не найдена или имя переменной некорректно.
+func (d *DeclareCommand) Execute(args []string, _ map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	functions, names := false, false
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		for _, flag := range args[0][1:] {
+			switch flag {
+			case 'f':
+				functions = true
+			case 'F':
+				names = true
+			default:
+				fmt.Fprintf(stderr, "declare: -%c: invalid option\n", flag)
+				fmt.Fprintln(stderr, "declare: usage: declare [-fF] [name[=value] ...]")
+				return 2
+			}
+		}
+		args = args[1:]
+	}
+
+	if functions || names {
+		return d.printFunctions(args, names, stdout)
+	}
+	if len(args) > 0 {
+		return declareVariables(d.environment, DeclareCommandName, args, stderr)
+	}
+
+	variables := d.environment.GetAllMap()
+	for _, name := range slices.Sorted(maps.Keys(variables)) {
+		fmt.Fprintf(stdout, "%s=%s\n", name, quoteValue(variables[name]))
+	}
+	return d.printFunctions(nil, false, stdout)
+}
+
+// printFunctions выводит функции с именами names (все функции, если имен нет):
+// определения целиком либо, при onlyNames, строки "declare -f name".
+func (d *DeclareCommand) printFunctions(names []string, onlyNames bool, stdout io.Writer) int {
+	if len(names) == 0 {
+		for _, fn := range d.environment.Functions() {
+			names = append(names, fn.Name)
+		}
+	}
+
+	exitCode := 0
+	for _, name := range names {
+		fn, exists := d.environment.Function(name)
+		if !exists {
+			exitCode = 1
+			continue
+		}
+		if onlyNames {
+			fmt.Fprintf(stdout, "declare -f %s\n", fn.Name)
+		} else {
+			fmt.Fprintln(stdout, fn.String())
+		}
+	}
+	return exitCode
+}
+
+// declareVariables объявляет переменные из аргументов name[=value] во внутренней
+// области видимости функции (или в окружении сессии вне функций).
+// Переменная без значения получает пустое значение. Некорректные имена
+// выводятся в stderr от имени команды command, и код возврата становится равным 1.
+func declareVariables(env *environment.Environment, command string, args []string, stderr io.Writer) int {
+	exitCode := 0
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		if !isValidName(name) {
+			fmt.Fprintf(stderr, "%s: `%s': not a valid identifier\n", command, arg)
+			exitCode = 1
+			continue
+		}
+		env.Declare(name, value)
+	}
+	return exitCode
+}
+
+// isValidName проверяет, что строка - корректное имя переменной:
+// буквы, цифры и подчеркивания, начиная с буквы или подчеркивания.
+func isValidName(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// quoteValue возвращает значение переменной в виде, пригодном для ввода в shell:
+// значения со специальными символами заключаются в одинарные кавычки.
+func quoteValue(value string) string {
+	safe := value != "" && strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_-+=.,:/@%", r)
+	}) == -1
+	if safe {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
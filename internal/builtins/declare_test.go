@@ -0,0 +1,117 @@
+package builtins
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// newTestFunction создает функцию с телом { echo name; }.
+func newTestFunction(name string) *parser.Function {
+	echo := &parser.Command{Name: "echo", Args: []*parser.Argument{{Value: name}}}
+	body := &parser.List{Items: []*parser.AndOr{{Pipelines: []parser.Node{echo}}}}
+	return &parser.Function{Name: name, Body: &parser.Group{Body: body}}
+}
+
+// TestDeclareCommand_Execute тестирует вывод функций (-f, -F) и объявление переменных.
+func TestDeclareCommand_Execute(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.SetFunction(newTestFunction("greet"))
+	env.SetFunction(newTestFunction("build"))
+	command := NewDeclareCommand(env)
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expectedOut  string
+	}{
+		{
+			name:         "print all functions",
+			args:         []string{"-f"},
+			expectedCode: 0,
+			expectedOut:  "build () { echo build; }\ngreet () { echo greet; }\n",
+		},
+		{
+			name:         "print function",
+			args:         []string{"-f", "greet"},
+			expectedCode: 0,
+			expectedOut:  "greet () { echo greet; }\n",
+		},
+		{
+			name:         "print function names",
+			args:         []string{"-F"},
+			expectedCode: 0,
+			expectedOut:  "declare -f build\ndeclare -f greet\n",
+		},
+		{
+			name:         "missing function",
+			args:         []string{"-f", "missing", "greet"},
+			expectedCode: 1,
+			expectedOut:  "greet () { echo greet; }\n",
+		},
+		{
+			name:         "declare variables",
+			args:         []string{"a=1", "b"},
+			expectedCode: 0,
+		},
+		{
+			name:         "invalid identifier",
+			args:         []string{"1a=1"},
+			expectedCode: 1,
+		},
+		{
+			name:         "invalid flag",
+			args:         []string{"-x"},
+			expectedCode: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := command.Execute(tt.args, nil, nil, &stdout, &stderr)
+
+			if exitCode != tt.expectedCode {
+				t.Errorf("exitCode = %d, expected %d (stderr %q)", exitCode, tt.expectedCode, stderr.String())
+			}
+			if stdout.String() != tt.expectedOut {
+				t.Errorf("stdout = %q, expected %q", stdout.String(), tt.expectedOut)
+			}
+		})
+	}
+
+	if value, exists := env.Get("a"); !exists || value != "1" {
+		t.Errorf("a = %q, %v, expected %q", value, exists, "1")
+	}
+	if value, exists := env.Get("b"); !exists || value != "" {
+		t.Errorf("b = %q, %v, expected empty variable", value, exists)
+	}
+}
+
+// TestDeclareCommand_ExecuteWithoutArgs тестирует вывод переменных и функций без аргументов:
+// значения со специальными символами выводятся в одинарных кавычках.
+func TestDeclareCommand_ExecuteWithoutArgs(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("DECLARE_PLAIN", "value")
+	env.Set("DECLARE_QUOTED", "it's a value")
+	env.SetFunction(newTestFunction("greet"))
+
+	var stdout, stderr bytes.Buffer
+	if exitCode := NewDeclareCommand(env).Execute(nil, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("exitCode = %d, expected 0", exitCode)
+	}
+
+	output := stdout.String()
+	for _, line := range []string{"DECLARE_PLAIN=value\n", `DECLARE_QUOTED='it'\''s a value'` + "\n"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("stdout = %q, expected line %q", output, line)
+		}
+	}
+	if !strings.HasSuffix(output, "greet () { echo greet; }\n") {
+		t.Errorf("stdout = %q, expected functions at the end", output)
+	}
+}
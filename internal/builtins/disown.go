@@ -0,0 +1,55 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+
+	"gocli/internal/jobs"
+)
+
+const DisownCommandName = "disown"
+
+// DisownCommand реализует встроенную команду disown.
+// Удаляет задания из таблицы: они продолжают выполняться, но shell их больше не отслеживает.
+type DisownCommand struct {
+	table *jobs.Table // Таблица заданий shell'а
+}
+
+// NewDisownCommand создает новый экземпляр команды disown для таблицы заданий table.
+func NewDisownCommand(table *jobs.Table) *DisownCommand {
+	return &DisownCommand{table: table}
+}
+
+// Name возвращает имя команды disown.
+func (d *DisownCommand) Name() string {
+	return DisownCommandName
+}
+
+// Execute выполняет команду disown.
+// Без аргументов удаляет текущее задание, с флагом -a - все задания,
+// иначе - задания по указанным спецификациям.
+func (d *DisownCommand) Execute(args []string, _ map[string]string, _ io.Reader, _ io.Writer, stderr io.Writer) int {
+	if len(args) == 1 && args[0] == "-a" {
+		for _, job := range d.table.List() {
+			d.table.Remove(job)
+		}
+		return 0
+	}
+
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	exitCode := 0
+	for _, spec := range args {
+		job, err := d.table.Find(spec)
+		if err != nil {
+			fmt.Fprintf(stderr, "disown: %v\n", err)
+			exitCode = 1
+			continue
+		}
+		d.table.Remove(job)
+	}
+
+	return exitCode
+}
@@ -0,0 +1,41 @@
+package builtins
+
+import (
+	"bytes"
+	"testing"
+
+	"gocli/internal/jobs"
+)
+
+// TestDisownCommand_Execute тестирует удаление заданий из таблицы командой disown.
+func TestDisownCommand_Execute(t *testing.T) {
+	table := jobs.NewTable()
+	release := make(chan struct{})
+	defer close(release)
+	startTestJob(table, "first", 0, release)
+	second := startTestJob(table, "second", 0, release)
+	startTestJob(table, "third", 0, release)
+
+	command := NewDisownCommand(table)
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{"%1"}, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("disown %%1 exitCode = %d, stderr = %q", exitCode, stderr.String())
+	}
+	if exitCode := command.Execute([]string{}, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("disown exitCode = %d, stderr = %q", exitCode, stderr.String())
+	}
+	if list := table.List(); len(list) != 1 || list[0] != second {
+		t.Errorf("table after disown = %v, expected only second job", list)
+	}
+
+	if exitCode := command.Execute([]string{"-a"}, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("disown -a exitCode = %d", exitCode)
+	}
+	if len(table.List()) != 0 {
+		t.Errorf("table after disown -a = %v, expected empty", table.List())
+	}
+	if exitCode := command.Execute([]string{"%1"}, nil, nil, &stdout, &stderr); exitCode != 1 {
+		t.Errorf("disown of unknown job exitCode = %d, expected 1", exitCode)
+	}
+}
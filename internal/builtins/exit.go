@@ -3,14 +3,19 @@ package builtins
 import (
 	"fmt"
 	"io"
-	"os"
 	"strconv"
 )
 
 const ExitCommandName = "exit"
 
+// StatusVariable - переменная с кодом возврата последней команды ($?). Исполнитель
+// передает ее команде exit: без аргумента shell завершается с этим кодом.
+const StatusVariable = "?"
+
 // ExitCommand реализует встроенную команду exit.
-// Завершает работу shell'а с указанным кодом возврата.
+// Команда лишь вычисляет код завершения: сам выход из shell'а (или подоболочки)
+// выполняет исполнитель, который вызывает ее для разбора аргумента, чтобы shell
+// мог завершиться с этим кодом штатно.
 type ExitCommand struct{}
 
 // NewExitCommand создает новый экземпляр команды exit.
@@ -23,36 +28,35 @@ func (e *ExitCommand) Name() string {
 	return ExitCommandName
 }
 
-// Execute выполняет команду exit.
-// Если передан аргумент, использует его как код возврата.
-// Иначе завершает работу с кодом 0.
+// Execute выполняет команду exit и возвращает код завершения shell'а.
 //
 // Поведение:
-//   - Без аргументов: завершает с кодом 0 (успех)
-//   - С числовым аргументом: завершает с указанным кодом (0-255)
-//   - С невалидным аргументом: выводит ошибку в stderr и завершает с кодом 2
+//   - Без аргументов: код последней команды из env["?"] (0, если он не передан)
+//   - С числовым аргументом: указанный код (0-255)
+//   - С невалидным аргументом: выводит ошибку в stderr, код 2
+//   - С несколькими аргументами: выводит ошибку в stderr, код 1 (shell не завершается)
 //   - Коды выхода вне диапазона 0-255: приводятся к диапазону 0-255 (по модулю 256)
-func (e *ExitCommand) Execute(args []string, _ map[string]string, _ io.Reader, _ io.Writer, stderr io.Writer) int {
-	exitCode := 0
-
-	if len(args) > 0 {
-		code, err := strconv.Atoi(args[0])
-		if err != nil {
-			// Невалидный аргумент: выводим ошибку и завершаем с кодом 2
-			fmt.Fprintf(stderr, "exit: %s: numeric argument required\n", args[0])
-			os.Exit(2)
-			return 2
-		}
-
-		// Приводим код к диапазону 0-255 (стандартный диапазон кодов выхода)
-		// Отрицательные числа и числа > 255 приводятся к диапазону
-		exitCode = code % 256
-		if exitCode < 0 {
-			exitCode += 256
-		}
+func (e *ExitCommand) Execute(args []string, env map[string]string, _ io.Reader, _ io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		status, _ := strconv.Atoi(env[StatusVariable])
+		return status
+	}
+	if len(args) > 1 {
+		fmt.Fprintln(stderr, "exit: too many arguments")
+		return 1
 	}
 
-	os.Exit(exitCode)
+	code, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "exit: %s: numeric argument required\n", args[0])
+		return 2
+	}
 
+	// Приводим код к диапазону 0-255 (стандартный диапазон кодов выхода)
+	// Отрицательные числа и числа > 255 приводятся к диапазону
+	exitCode := code % 256
+	if exitCode < 0 {
+		exitCode += 256
+	}
 	return exitCode
 }
@@ -1,6 +1,8 @@
 package builtins
 
 import (
+	"bytes"
+	"io"
 	"testing"
 )
 
@@ -15,47 +17,52 @@ func TestExitCommand_Name(t *testing.T) {
 	}
 }
 
-// TestExitCommand_ExecuteWithNoArgs тестирует команду exit без аргументов.
-// Примечание: Мы не можем реально тестировать os.Exit(), так как это завершает процесс.
-// Этот тест только проверяет, что структура команды корректна.
-func TestExitCommand_ExecuteWithNoArgs(t *testing.T) {
-	command := NewExitCommand()
-	if command.Name() != ExitCommandName {
-		t.Errorf("ExitCommand.Name() = %s, expected %s", command.Name(), ExitCommandName)
+// TestExitCommand_Execute тестирует вычисление кода завершения командой exit:
+// без аргументов, с кодом, с кодом вне диапазона 0-255 и с невалидным аргументом.
+func TestExitCommand_Execute(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expected       int
+		expectedStderr string
+	}{
+		{"no args", nil, 0, ""},
+		{"exit code", []string{"3"}, 3, ""},
+		{"wraps above 255", []string{"300"}, 44, ""},
+		{"wraps 256", []string{"256"}, 0, ""},
+		{"negative", []string{"-1"}, 255, ""},
+		{"invalid", []string{"abc"}, 2, "exit: abc: numeric argument required\n"},
+		{"too many arguments", []string{"1", "2"}, 1, "exit: too many arguments\n"},
 	}
-}
 
-// TestExitCommand_ExecuteWithExitCode тестирует команду exit с аргументом кода выхода.
-// Примечание: Мы не можем реально тестировать os.Exit(), так как это завершает процесс.
-// Этот тест только проверяет, что структура команды корректна.
-func TestExitCommand_ExecuteWithExitCode(t *testing.T) {
-	command := NewExitCommand()
-	if command.Name() != ExitCommandName {
-		t.Errorf("ExitCommand.Name() = %s, expected %s", command.Name(), ExitCommandName)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			code := NewExitCommand().Execute(tt.args, nil, nil, io.Discard, &stderr)
+			if code != tt.expected {
+				t.Errorf("ExitCommand.Execute(%v) = %d, expected %d", tt.args, code, tt.expected)
+			}
+			if stderr.String() != tt.expectedStderr {
+				t.Errorf("ExitCommand.Execute(%v) stderr = %q, expected %q", tt.args, stderr.String(), tt.expectedStderr)
+			}
+		})
 	}
 }
 
-// TestExitCommand_ExecuteWithInvalidExitCode тестирует команду exit с недопустимым кодом выхода.
-// Примечание: Мы не можем реально тестировать os.Exit(), так как это завершает процесс.
-// Этот тест только проверяет, что структура команды корректна.
-//
-// Ожидаемое поведение при невалидном аргументе:
-//   - Команда выводит ошибку в stderr: "exit: <arg>: numeric argument required"
-//   - Завершает процесс с кодом 2
-//
-// Ожидаемое поведение при коде вне диапазона 0-255:
-//   - Код приводится к диапазону 0-255 (по модулю 256)
-//   - Например: exit 256 -> код 0, exit -1 -> код 255, exit 300 -> код 44
-func TestExitCommand_ExecuteWithInvalidExitCode(t *testing.T) {
-	command := NewExitCommand()
-	if command.Name() != ExitCommandName {
-		t.Errorf("ExitCommand.Name() = %s, expected %s", command.Name(), ExitCommandName)
+// TestExitCommand_ExecuteLastStatus тестирует exit без аргументов: код завершения -
+// код последней команды из переменной "?", как у команды exit, выполняемой shell'ом.
+func TestExitCommand_ExecuteLastStatus(t *testing.T) {
+	env := map[string]string{StatusVariable: "7"}
+	if code := NewExitCommand().Execute(nil, env, nil, io.Discard, io.Discard); code != 7 {
+		t.Errorf("ExitCommand.Execute() = %d, expected 7", code)
+	}
+	if code := NewExitCommand().Execute([]string{"3"}, env, nil, io.Discard, io.Discard); code != 3 {
+		t.Errorf("ExitCommand.Execute(3) = %d, expected 3", code)
 	}
 }
 
 // TestExitCommand_Integration тестирует команду exit в контролируемом режиме.
-// Проверяет, что команда exit может быть создана и имеет правильное имя.
-// Мы не можем тестировать реальное выполнение, так как это вызывает os.Exit().
+// Проверяет, что команда exit может быть создана, имеет правильное имя и реализует Builtin.
 func TestExitCommand_Integration(t *testing.T) {
 
 	command := NewExitCommand()
@@ -0,0 +1,58 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+
+	"gocli/internal/jobs"
+)
+
+const FgCommandName = "fg"
+
+// FgCommand реализует встроенную команду fg.
+// Переводит фоновое задание на передний план: ждет его завершения.
+type FgCommand struct {
+	table *jobs.Table // Таблица заданий shell'а
+}
+
+// NewFgCommand создает новый экземпляр команды fg для таблицы заданий table.
+func NewFgCommand(table *jobs.Table) *FgCommand {
+	return &FgCommand{table: table}
+}
+
+// Name возвращает имя команды fg.
+func (f *FgCommand) Name() string {
+	return FgCommandName
+}
+
+// Execute выполняет команду fg.
+// Без аргументов выбирает текущее задание. Выводит текст команды задания,
+// возобновляет его процессы и ждет завершения. Код возврата - код возврата задания.
+func (f *FgCommand) Execute(args []string, _ map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 1 {
+		fmt.Fprintln(stderr, "fg: too many arguments")
+		return 1
+	}
+
+	spec := ""
+	if len(args) == 1 {
+		spec = args[0]
+	}
+
+	job, err := f.table.Find(spec)
+	if err != nil {
+		fmt.Fprintf(stderr, "fg: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(stdout, job.Command)
+	if job.State() == jobs.Running {
+		// Процессы могли быть приостановлены извне (kill -STOP);
+		// ошибка возобновления не мешает дождаться задания
+		_ = job.Continue()
+	}
+
+	status := job.Wait()
+	f.table.Remove(job)
+	return status
+}
@@ -0,0 +1,44 @@
+package builtins
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gocli/internal/jobs"
+)
+
+// TestFgCommand_Execute тестирует ожидание текущего задания командой fg.
+// Проверяет вывод текста команды, код возврата задания и удаление его из таблицы.
+func TestFgCommand_Execute(t *testing.T) {
+	table := jobs.NewTable()
+	release := make(chan struct{})
+	startTestJob(table, "make build", 4, release)
+	close(release)
+
+	command := NewFgCommand(table)
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{}, nil, nil, &stdout, &stderr); exitCode != 4 {
+		t.Errorf("FgCommand.Execute() exitCode = %d, expected 4", exitCode)
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "make build" {
+		t.Errorf("FgCommand.Execute() output = %q, expected %q", got, "make build")
+	}
+	if len(table.List()) != 0 {
+		t.Errorf("job should be removed after fg, table = %v", table.List())
+	}
+}
+
+// TestFgCommand_ExecuteNoJob тестирует fg без заданий.
+func TestFgCommand_ExecuteNoJob(t *testing.T) {
+	command := NewFgCommand(jobs.NewTable())
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{}, nil, nil, &stdout, &stderr); exitCode != 1 {
+		t.Errorf("FgCommand.Execute() exitCode = %d, expected 1", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "no current job") {
+		t.Errorf("stderr = %q, expected 'no current job'", stderr.String())
+	}
+}
@@ -27,7 +27,7 @@ func (g *GrepCommand) Name() string {
 
 // Execute выполняет команду grep.
 // Поддерживает флаги: -w (слово целиком), -i (регистронезависимый поиск), -A (строки после совпадения).
-func (g *GrepCommand) Execute(args []string, _ map[string]string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+func (g *GrepCommand) Execute(args []string, env map[string]string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
 	// Парсинг флагов с использованием стандартной библиотеки flag
 	fs := flag.NewFlagSet("grep", flag.ContinueOnError)
 	fs.SetOutput(stderr) // Перенаправляем вывод ошибок flag в stderr
@@ -88,7 +88,7 @@ func (g *GrepCommand) Execute(args []string, _ map[string]string, stdin io.Reade
 	// Обрабатываем каждый файл
 	exitCode := 0
 	for _, filename := range filenames {
-		file, err := os.Open(filename)
+		file, err := OpenFile(env[PwdVariable], filename, os.O_RDONLY, 0)
 		if err != nil {
 			fmt.Fprintf(stderr, "grep: %s: %v\n", filename, err)
 			exitCode = 1
@@ -0,0 +1,92 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gocli/internal/jobs"
+)
+
+const JobsCommandName = "jobs"
+
+// JobsCommand реализует встроенную команду jobs.
+// Выводит список фоновых заданий shell'а.
+type JobsCommand struct {
+	table *jobs.Table // Таблица заданий shell'а
+}
+
+// NewJobsCommand создает новый экземпляр команды jobs для таблицы заданий table.
+func NewJobsCommand(table *jobs.Table) *JobsCommand {
+	return &JobsCommand{table: table}
+}
+
+// Name возвращает имя команды jobs.
+func (j *JobsCommand) Name() string {
+	return JobsCommandName
+}
+
+// Execute выполняет команду jobs.
+// Без аргументов выводит все задания, иначе - задания по указанным спецификациям.
+// Флаг -l добавляет PID процессов, флаг -p выводит только PID.
+// Завершившиеся задания после вывода удаляются из таблицы, как в bash.
+func (j *JobsCommand) Execute(args []string, _ map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	long, pidsOnly := false, false
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		for _, flag := range args[0][1:] {
+			switch flag {
+			case 'l':
+				long = true
+			case 'p':
+				pidsOnly = true
+			default:
+				fmt.Fprintf(stderr, "jobs: -%c: invalid option\n", flag)
+				return 2
+			}
+		}
+		args = args[1:]
+	}
+
+	list := j.table.List()
+	if len(args) > 0 {
+		list = list[:0:0]
+		for _, spec := range args {
+			job, err := j.table.Find(spec)
+			if err != nil {
+				fmt.Fprintf(stderr, "jobs: %v\n", err)
+				return 1
+			}
+			list = append(list, job)
+		}
+	}
+
+	for _, job := range list {
+		switch {
+		case pidsOnly:
+			for _, pid := range job.Pids() {
+				fmt.Fprintln(stdout, pid)
+			}
+		case long:
+			fmt.Fprintf(stdout, "%s  %s\n", j.table.Format(job), formatPids(job.Pids()))
+		default:
+			fmt.Fprintln(stdout, j.table.Format(job))
+		}
+	}
+
+	for _, job := range list {
+		if job.State() == jobs.Done {
+			j.table.RemoveReported(job)
+		}
+	}
+
+	return 0
+}
+
+// formatPids возвращает список PID через пробел.
+func formatPids(pids []int) string {
+	parts := make([]string, len(pids))
+	for i, pid := range pids {
+		parts[i] = fmt.Sprint(pid)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,77 @@
+package builtins
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gocli/internal/jobs"
+)
+
+// startTestJob запускает в таблице задание без процессов, которое завершается
+// с кодом status после закрытия release.
+func startTestJob(table *jobs.Table, command string, status int, release <-chan struct{}) *jobs.Job {
+	return table.Start(command, func(*jobs.Job) int {
+		<-release
+		return status
+	})
+}
+
+// TestJobsCommand_Execute тестирует вывод списка заданий.
+// Проверяет формат строки задания и удаление завершившихся заданий после вывода.
+func TestJobsCommand_Execute(t *testing.T) {
+	table := jobs.NewTable()
+	release := make(chan struct{})
+	defer close(release)
+
+	startTestJob(table, "sleep 10", 0, release)
+	done := table.Start("echo hi", func(job *jobs.Job) int { return 0 })
+	done.Wait()
+
+	command := NewJobsCommand(table)
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{}, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("JobsCommand.Execute() exitCode = %d, stderr = %q", exitCode, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("JobsCommand.Execute() output = %q, expected 2 lines", stdout.String())
+	}
+	if !strings.HasPrefix(lines[0], "[1]-  Running") || !strings.HasSuffix(lines[0], "sleep 10 &") {
+		t.Errorf("running job line = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[2]+  Done") || !strings.HasSuffix(lines[1], "echo hi") {
+		t.Errorf("done job line = %q", lines[1])
+	}
+
+	if len(table.List()) != 1 {
+		t.Errorf("done job should be removed after jobs, table = %v", table.List())
+	}
+}
+
+// TestJobsCommand_ExecuteErrors тестирует ошибки команды jobs.
+func TestJobsCommand_ExecuteErrors(t *testing.T) {
+	command := NewJobsCommand(jobs.NewTable())
+
+	var stdout, stderr bytes.Buffer
+	if exitCode := command.Execute([]string{"%1"}, nil, nil, &stdout, &stderr); exitCode != 1 {
+		t.Errorf("jobs %%1 exitCode = %d, expected 1", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "no such job") {
+		t.Errorf("stderr = %q, expected 'no such job'", stderr.String())
+	}
+
+	stderr.Reset()
+	if exitCode := command.Execute([]string{"-x"}, nil, nil, &stdout, &stderr); exitCode != 2 {
+		t.Errorf("jobs -x exitCode = %d, expected 2", exitCode)
+	}
+}
+
+// TestJobsCommand_Name тестирует получение имени команды jobs.
+func TestJobsCommand_Name(t *testing.T) {
+	if name := NewJobsCommand(jobs.NewTable()).Name(); name != "jobs" {
+		t.Errorf("JobsCommand.Name() = %s, expected jobs", name)
+	}
+}
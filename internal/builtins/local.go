@@ -0,0 +1,48 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"gocli/internal/environment"
+)
+
+const LocalCommandName = "local"
+
+// LocalCommand реализует встроенную команду local.
+// Объявляет переменные в области видимости вызванной функции: после выхода
+// из функции они удаляются, а одноименные внешние переменные снова становятся видимыми.
+type LocalCommand struct {
+	environment *environment.Environment // Окружение с областями видимости функций
+}
+
+// NewLocalCommand создает новый экземпляр команды local для окружения env.
+func NewLocalCommand(env *environment.Environment) *LocalCommand {
+	return &LocalCommand{environment: env}
+}
+
+// Name возвращает имя команды local.
+func (l *LocalCommand) Name() string {
+	return LocalCommandName
+}
+
+// Execute выполняет команду local name[=value] ...
+// Переменная без значения получает пустое значение. Без аргументов выводит
+// локальные переменные функции. Вне функции команда завершается с кодом 1.
+func (l *LocalCommand) Execute(args []string, _ map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if !l.environment.InScope() {
+		fmt.Fprintln(stderr, "local: can only be used in a function")
+		return 1
+	}
+
+	if len(args) == 0 {
+		variables := l.environment.ScopeVariables()
+		for _, name := range slices.Sorted(maps.Keys(variables)) {
+			fmt.Fprintf(stdout, "%s=%s\n", name, quoteValue(variables[name]))
+		}
+		return 0
+	}
+	return declareVariables(l.environment, LocalCommandName, args, stderr)
+}
@@ -0,0 +1,48 @@
+package builtins
+
+import (
+	"bytes"
+	"testing"
+
+	"gocli/internal/environment"
+)
+
+// TestLocalCommand_Execute тестирует объявление локальных переменных функции
+// и ошибку при вызове вне функции.
+func TestLocalCommand_Execute(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("x", "global")
+	command := NewLocalCommand(env)
+
+	var stdout, stderr bytes.Buffer
+	if exitCode := command.Execute([]string{"x=1"}, nil, nil, &stdout, &stderr); exitCode != 1 {
+		t.Errorf("exitCode outside function = %d, expected 1", exitCode)
+	}
+	if stderr.String() != "local: can only be used in a function\n" {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+
+	env.PushScope()
+	stderr.Reset()
+	if exitCode := command.Execute([]string{"x=local value", "y", "-z"}, nil, nil, &stdout, &stderr); exitCode != 1 {
+		t.Errorf("exitCode = %d, expected 1 for invalid identifier", exitCode)
+	}
+	if stderr.String() != "local: `-z': not a valid identifier\n" {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+
+	if exitCode := command.Execute(nil, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Errorf("exitCode = %d, expected 0", exitCode)
+	}
+	if expected := "x='local value'\ny=''\n"; stdout.String() != expected {
+		t.Errorf("stdout = %q, expected %q", stdout.String(), expected)
+	}
+
+	env.PopScope()
+	if value, _ := env.Get("x"); value != "global" {
+		t.Errorf("x = %q after function, expected %q", value, "global")
+	}
+	if _, exists := env.Get("y"); exists {
+		t.Error("y should not exist after function")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"sort"
 )
 
@@ -14,31 +13,57 @@ func NewLsCommand() *LsCommand { return &LsCommand{} }
 
 func (l *LsCommand) Name() string { return "ls" }
 
-func (l *LsCommand) Execute(args []string, _ map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
-	var target string
+// Execute выводит содержимое каталогов и имена файлов, заданных операндами (по умолчанию
+// текущего каталога). Как в ls, сначала выводятся файлы, затем каталоги; при нескольких
+// операндах содержимому каталога предшествует заголовок "каталог:". Несуществующий
+// операнд сообщается в stderr, остальные выводятся, а код возврата равен 2.
+func (l *LsCommand) Execute(args []string, env map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
 	if len(args) == 0 {
-		target = "."
-	} else if len(args) == 1 {
-		target = args[0]
-	} else {
-		fmt.Fprintln(stderr, "ls: too many arguments")
-		return 1
+		args = []string{"."}
 	}
 
-	info, err := os.Stat(target)
-	if err != nil {
-		fmt.Fprintf(stderr, "ls: %v\n", err)
-		return 2
+	status := 0
+	var files, dirs []string
+	for _, target := range args {
+		info, err := os.Stat(ResolvePath(env[PwdVariable], target))
+		if err != nil {
+			fmt.Fprintf(stderr, "ls: %v\n", restorePath(err, target))
+			status = 2
+			continue
+		}
+		if info.IsDir() {
+			dirs = append(dirs, target)
+		} else {
+			files = append(files, target)
+		}
+	}
+	sort.Strings(files)
+	sort.Strings(dirs)
+
+	for _, file := range files {
+		fmt.Fprintln(stdout, file)
 	}
-	if !info.IsDir() {
-		fmt.Fprintln(stdout, filepath.Base(target))
-		return 0
+	for i, dir := range dirs {
+		if len(args) > 1 {
+			if i > 0 || len(files) > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "%s:\n", dir)
+		}
+		if !l.list(env[PwdVariable], dir, stdout, stderr) {
+			status = 2
+		}
 	}
+	return status
+}
 
-	entries, err := os.ReadDir(target)
+// list выводит отсортированные имена элементов каталога dir. Возвращает false,
+// если каталог не удалось прочитать.
+func (l *LsCommand) list(pwd, dir string, stdout, stderr io.Writer) bool {
+	entries, err := os.ReadDir(ResolvePath(pwd, dir))
 	if err != nil {
-		fmt.Fprintf(stderr, "ls: %v\n", err)
-		return 2
+		fmt.Fprintf(stderr, "ls: %v\n", restorePath(err, dir))
+		return false
 	}
 
 	names := make([]string, 0, len(entries))
@@ -49,5 +74,5 @@ func (l *LsCommand) Execute(args []string, _ map[string]string, _ io.Reader, std
 	for _, n := range names {
 		fmt.Fprintln(stdout, n)
 	}
-	return 0
+	return true
 }
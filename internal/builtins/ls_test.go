@@ -3,6 +3,7 @@ package builtins
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -85,23 +86,39 @@ func TestLsCurrentDirectory(t *testing.T) {
 	}
 }
 
-func TestLsTooManyArguments(t *testing.T) {
+// TestLsMultipleOperands проверяет вывод нескольких операндов (например, результата
+// раскрытия ls *.go): сначала файлы, затем каталоги с заголовками, а несуществующий
+// операнд сообщается в stderr и дает код возврата 2.
+func TestLsMultipleOperands(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "sub/c.txt", "sub2/d.txt"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, name)), 0755); err != nil {
+			t.Fatalf("cannot create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, name), nil, 0644); err != nil {
+			t.Fatalf("cannot create file: %v", err)
+		}
+	}
+
 	cmd := NewLsCommand()
-	var stderr bytes.Buffer
+	var stdout, stderr bytes.Buffer
 
 	exitCode := cmd.Execute(
-		[]string{"a", "b"},
-		nil,
-		nil,
+		[]string{"sub2", "b.txt", "missing", "sub", "a.txt"},
+		map[string]string{PwdVariable: tmpDir},
 		nil,
+		&stdout,
 		&stderr,
 	)
 
-	if exitCode == 0 {
-		t.Fatalf("expected non-zero exit code")
+	if exitCode != 2 {
+		t.Errorf("expected exit code 2, got %d", exitCode)
 	}
-
-	if stderr.Len() == 0 {
-		t.Fatalf("expected error message in stderr")
+	expected := "a.txt\nb.txt\n\nsub:\nc.txt\n\nsub2:\nd.txt\n"
+	if stdout.String() != expected {
+		t.Errorf("stdout = %q, expected %q", stdout.String(), expected)
+	}
+	if !strings.Contains(stderr.String(), "missing") {
+		t.Errorf("expected error about missing operand in stderr, got %q", stderr.String())
 	}
 }
@@ -0,0 +1,38 @@
+package builtins
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// PwdVariable - переменная с рабочим каталогом shell'а. Исполнитель передает в ней
+// встроенным командам свой рабочий каталог: относительные пути в аргументах команд
+// отсчитываются от него, а не от рабочего каталога процесса.
+const PwdVariable = "PWD"
+
+// ResolvePath возвращает путь name относительно рабочего каталога dir.
+// Абсолютный путь и любой путь при пустом dir (рабочий каталог процесса) не меняются.
+func ResolvePath(dir, name string) string {
+	if dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+// OpenFile открывает файл name относительно рабочего каталога dir, как os.OpenFile.
+// В сообщении об ошибке остается исходное имя файла.
+func OpenFile(dir, name string, flag int, perm os.FileMode) (*os.File, error) {
+	file, err := os.OpenFile(ResolvePath(dir, name), flag, perm)
+	return file, restorePath(err, name)
+}
+
+// restorePath заменяет путь в ошибке файловой операции исходным именем файла.
+func restorePath(err error, name string) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		pathErr.Path = name
+	}
+	return err
+}
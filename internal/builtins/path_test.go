@@ -0,0 +1,72 @@
+package builtins
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResolvePath проверяет разрешение путей относительно рабочего каталога shell'а.
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		dir      string
+		name     string
+		expected string
+	}{
+		{"/work", "file.txt", filepath.Join("/work", "file.txt")},
+		{"/work", "../file.txt", filepath.Join("/", "file.txt")},
+		{"", "file.txt", "file.txt"},
+	}
+	for _, tt := range tests {
+		if got := ResolvePath(tt.dir, tt.name); got != tt.expected {
+			t.Errorf("ResolvePath(%q, %q) = %q, expected %q", tt.dir, tt.name, got, tt.expected)
+		}
+	}
+
+	abs := filepath.Join(t.TempDir(), "file.txt")
+	if got := ResolvePath("/work", abs); got != abs {
+		t.Errorf("ResolvePath(%q, %q) = %q, expected %q", "/work", abs, got, abs)
+	}
+}
+
+// TestFileBuiltinsUseShellDirectory проверяет, что команды, читающие файлы, отсчитывают
+// относительные пути от каталога из переменной PWD, а в сообщениях об ошибках
+// остается исходное имя файла.
+func TestFileBuiltinsUseShellDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data.txt"), []byte("hello world\n"), 0o644); err != nil {
+		t.Fatalf("cannot write file: %v", err)
+	}
+	env := map[string]string{PwdVariable: dir}
+
+	tests := []struct {
+		command  Builtin
+		args     []string
+		expected string
+	}{
+		{NewCatCommand(), []string{"data.txt"}, "hello world\n"},
+		{NewWcCommand(), []string{"data.txt"}, "1 2 12 data.txt\n"},
+		{NewGrepCommand(), []string{"world", "data.txt"}, "data.txt:hello world\n"},
+		{NewLsCommand(), nil, "data.txt\n"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if status := tt.command.Execute(tt.args, env, nil, &stdout, &stderr); status != 0 {
+			t.Errorf("%s %v status = %d, stderr = %q", tt.command.Name(), tt.args, status, stderr.String())
+		}
+		if stdout.String() != tt.expected {
+			t.Errorf("%s %v output = %q, expected %q", tt.command.Name(), tt.args, stdout.String(), tt.expected)
+		}
+	}
+
+	if status := NewTestCommand().Execute([]string{"-f", "data.txt"}, env, nil, nil, nil); status != 0 {
+		t.Errorf("test -f data.txt status = %d, expected 0", status)
+	}
+
+	var stderr bytes.Buffer
+	NewCatCommand().Execute([]string{"missing.txt"}, env, nil, &bytes.Buffer{}, &stderr)
+	if expected := "cat: missing.txt: open missing.txt: no such file or directory\n"; stderr.String() != expected {
+		t.Errorf("cat stderr = %q, expected %q", stderr.String(), expected)
+	}
+}
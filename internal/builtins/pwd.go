@@ -23,8 +23,14 @@ func (p *PwdCommand) Name() string {
 }
 
 // Execute выполняет команду pwd.
-// Выводит абсолютный путь к текущей рабочей директории.
-func (p *PwdCommand) Execute(_ []string, _ map[string]string, _ io.Reader, stdout io.Writer, _ io.Writer) int {
+// Выводит абсолютный путь к текущей рабочей директории: рабочий каталог shell'а
+// из переменной PWD в env, а если она не задана - рабочий каталог процесса.
+func (p *PwdCommand) Execute(_ []string, env map[string]string, _ io.Reader, stdout io.Writer, _ io.Writer) int {
+	if dir := env[PwdVariable]; dir != "" {
+		fmt.Fprintln(stdout, dir)
+		return 0
+	}
+
 	dir, err := os.Getwd()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "pwd: %v\n", err)
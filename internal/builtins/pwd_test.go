@@ -29,6 +29,21 @@ func TestPwdCommand_Execute(t *testing.T) {
 	}
 }
 
+// TestPwdCommand_ExecuteWithShellDirectory тестирует вывод рабочего каталога shell'а:
+// если в env передана переменная PWD, команда выводит ее, а не каталог процесса.
+func TestPwdCommand_ExecuteWithShellDirectory(t *testing.T) {
+	command := NewPwdCommand()
+	dir := t.TempDir()
+
+	var stdout bytes.Buffer
+	if exitCode := command.Execute(nil, map[string]string{PwdVariable: dir}, nil, &stdout, &bytes.Buffer{}); exitCode != 0 {
+		t.Errorf("PwdCommand.Execute() exitCode = %d, expected 0", exitCode)
+	}
+	if output := strings.TrimSpace(stdout.String()); output != dir {
+		t.Errorf("PwdCommand.Execute() output = %q, expected %q", output, dir)
+	}
+}
+
 // TestPwdCommand_Name тестирует получение имени команды pwd.
 // Проверяет, что команда возвращает корректное имя "pwd".
 func TestPwdCommand_Name(t *testing.T) {
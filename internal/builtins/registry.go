@@ -23,12 +23,26 @@ func NewRegistry() *Registry {
 	registry.Register(NewPwdCommand())
 	registry.Register(NewExitCommand())
 	registry.Register(NewGrepCommand())
-	registry.Register(NewCdCommand())
+	registry.Register(NewCdCommand(nil))
 	registry.Register(NewLsCommand())
+	registry.Register(NewTestCommand())
+	registry.Register(NewBracketCommand())
 
 	return registry
 }
 
+// Clone возвращает копию реестра с теми же командами.
+// Регистрация команд в копии не влияет на исходный реестр.
+func (r *Registry) Clone() *Registry {
+	clone := &Registry{
+		commands: make(map[string]Builtin, len(r.commands)),
+	}
+	for name, command := range r.commands {
+		clone.commands[name] = command
+	}
+	return clone
+}
+
 // Register регистрирует новую встроенную команду в реестре.
 // Если команда с таким именем уже существует, она будет перезаписана.
 func (r *Registry) Register(command Builtin) {
@@ -10,7 +10,7 @@ func TestRegistry_Register(t *testing.T) {
 	registry := NewRegistry()
 
 	// Проверяем, что все встроенные команды зарегистрированы
-	expectedCommands := []string{"cat", "echo", "wc", "pwd", "exit", "grep"}
+	expectedCommands := []string{"cat", "echo", "wc", "pwd", "exit", "grep", "test", "["}
 
 	for _, cmdName := range expectedCommands {
 		if !registry.IsBuiltin(cmdName) {
@@ -77,13 +77,13 @@ func TestRegistry_List(t *testing.T) {
 	registry := NewRegistry()
 	commands := registry.List()
 
-	expectedCount := 8 // cat, echo, wc, pwd, exit, grep
+	expectedCount := 10 // cat, echo, wc, pwd, exit, grep, cd, ls, test, [
 	if len(commands) != expectedCount {
 		t.Errorf("Registry.List() returned %d commands, expected %d", len(commands), expectedCount)
 	}
 
 	// Проверяем, что все ожидаемые команды присутствуют
-	expectedCommands := []string{"cat", "echo", "wc", "pwd", "exit", "grep"}
+	expectedCommands := []string{"cat", "echo", "wc", "pwd", "exit", "grep", "test", "["}
 	for _, expected := range expectedCommands {
 		found := false
 		for _, cmd := range commands {
@@ -97,3 +97,22 @@ func TestRegistry_List(t *testing.T) {
 		}
 	}
 }
+
+// TestRegistry_Clone тестирует копирование реестра: копия содержит те же команды,
+// а регистрация в копии не влияет на исходный реестр.
+func TestRegistry_Clone(t *testing.T) {
+	registry := NewRegistry()
+	clone := registry.Clone()
+
+	if len(clone.List()) != len(registry.List()) {
+		t.Errorf("Registry.Clone() has %d commands, expected %d", len(clone.List()), len(registry.List()))
+	}
+
+	clone.Register(NewShoptCommand(nil))
+	if registry.IsBuiltin(ShoptCommandName) {
+		t.Error("command registered in clone should not appear in original registry")
+	}
+	if !clone.IsBuiltin(ShoptCommandName) {
+		t.Error("command should be registered in clone")
+	}
+}
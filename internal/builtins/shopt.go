@@ -0,0 +1,128 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gocli/internal/environment"
+)
+
+const ShoptCommandName = "shopt"
+
+// ShoptCommand реализует встроенную команду shopt.
+// Включает, выключает и выводит параметры shell'а (например, nullglob и failglob).
+type ShoptCommand struct {
+	environment *environment.Environment // Окружение, хранящее параметры shell'а
+}
+
+// NewShoptCommand создает новый экземпляр команды shopt для окружения env.
+func NewShoptCommand(env *environment.Environment) *ShoptCommand {
+	return &ShoptCommand{environment: env}
+}
+
+// Name возвращает имя команды shopt.
+func (s *ShoptCommand) Name() string {
+	return ShoptCommandName
+}
+
+// Execute выполняет команду shopt.
+// Флаг -s включает указанные параметры, -u - выключает. Без флагов выводит состояние
+// параметров (всех или указанных); -s и -u без имен выводят только включенные или выключенные.
+// Флаг -q подавляет вывод, -p выводит параметры в виде команд shopt.
+// При запросе состояния код возврата равен 1, если хотя бы один параметр выключен.
+func (s *ShoptCommand) Execute(args []string, _ map[string]string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	set, unset, quiet, reusable := false, false, false, false
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		for _, flag := range args[0][1:] {
+			switch flag {
+			case 's':
+				set = true
+			case 'u':
+				unset = true
+			case 'q':
+				quiet = true
+			case 'p':
+				reusable = true
+			default:
+				fmt.Fprintf(stderr, "shopt: -%c: invalid option\n", flag)
+				fmt.Fprintln(stderr, "shopt: usage: shopt [-pqsu] [optname ...]")
+				return 2
+			}
+		}
+		args = args[1:]
+	}
+
+	if set && unset {
+		fmt.Fprintln(stderr, "shopt: cannot set and unset shell options simultaneously")
+		return 1
+	}
+
+	for _, name := range args {
+		if !s.isOption(name) {
+			fmt.Fprintf(stderr, "shopt: %s: invalid shell option name\n", name)
+			return 1
+		}
+	}
+
+	if (set || unset) && len(args) > 0 {
+		for _, name := range args {
+			s.environment.SetOption(name, set)
+		}
+		return 0
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = environment.Options()
+	}
+
+	exitCode := 0
+	for _, name := range names {
+		enabled := s.environment.Option(name)
+		if !enabled {
+			exitCode = 1
+		}
+		// -s и -u без имен выводят только параметры в соответствующем состоянии
+		if len(args) == 0 && ((set && !enabled) || (unset && enabled)) {
+			continue
+		}
+		if !quiet {
+			s.printOption(stdout, name, enabled, reusable)
+		}
+	}
+
+	if len(args) == 0 {
+		return 0
+	}
+	return exitCode
+}
+
+// printOption выводит состояние параметра в формате bash:
+// "name<TAB>on" либо, с флагом -p, "shopt -s name".
+func (s *ShoptCommand) printOption(stdout io.Writer, name string, enabled, reusable bool) {
+	if reusable {
+		flag := "-u"
+		if enabled {
+			flag = "-s"
+		}
+		fmt.Fprintf(stdout, "shopt %s %s\n", flag, name)
+		return
+	}
+
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	fmt.Fprintf(stdout, "%-15s\t%s\n", name, state)
+}
+
+// isOption проверяет, поддерживается ли параметр с именем name.
+func (s *ShoptCommand) isOption(name string) bool {
+	for _, option := range environment.Options() {
+		if option == name {
+			return true
+		}
+	}
+	return false
+}
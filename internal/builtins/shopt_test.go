@@ -0,0 +1,101 @@
+package builtins
+
+import (
+	"bytes"
+	"testing"
+
+	"gocli/internal/environment"
+)
+
+// TestShoptCommand_Execute тестирует включение, выключение и вывод параметров shell'а.
+func TestShoptCommand_Execute(t *testing.T) {
+	env := environment.NewEnvironment()
+	command := NewShoptCommand(env)
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expectedOut  string
+	}{
+		{
+			name:         "list all options",
+			args:         []string{},
+			expectedCode: 0,
+			expectedOut:  "failglob       \toff\nnullglob       \toff\n",
+		},
+		{
+			name:         "enable option",
+			args:         []string{"-s", "nullglob"},
+			expectedCode: 0,
+		},
+		{
+			name:         "query enabled option",
+			args:         []string{"nullglob"},
+			expectedCode: 0,
+			expectedOut:  "nullglob       \ton\n",
+		},
+		{
+			name:         "query disabled option",
+			args:         []string{"failglob"},
+			expectedCode: 1,
+			expectedOut:  "failglob       \toff\n",
+		},
+		{
+			name:         "list enabled options",
+			args:         []string{"-s"},
+			expectedCode: 0,
+			expectedOut:  "nullglob       \ton\n",
+		},
+		{
+			name:         "reusable output",
+			args:         []string{"-p", "nullglob", "failglob"},
+			expectedCode: 1,
+			expectedOut:  "shopt -s nullglob\nshopt -u failglob\n",
+		},
+		{
+			name:         "quiet query",
+			args:         []string{"-q", "nullglob"},
+			expectedCode: 0,
+		},
+		{
+			name:         "disable option",
+			args:         []string{"-u", "nullglob"},
+			expectedCode: 0,
+		},
+		{
+			name:         "quiet query of disabled option",
+			args:         []string{"-q", "nullglob"},
+			expectedCode: 1,
+		},
+		{
+			name:         "invalid option name",
+			args:         []string{"-s", "nonexistent"},
+			expectedCode: 1,
+		},
+		{
+			name:         "invalid flag",
+			args:         []string{"-x"},
+			expectedCode: 2,
+		},
+		{
+			name:         "set and unset simultaneously",
+			args:         []string{"-s", "-u", "nullglob"},
+			expectedCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := command.Execute(tt.args, nil, nil, &stdout, &stderr)
+
+			if exitCode != tt.expectedCode {
+				t.Errorf("exitCode = %d, expected %d (stderr %q)", exitCode, tt.expectedCode, stderr.String())
+			}
+			if stdout.String() != tt.expectedOut {
+				t.Errorf("stdout = %q, expected %q", stdout.String(), tt.expectedOut)
+			}
+		})
+	}
+}
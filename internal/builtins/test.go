@@ -0,0 +1,341 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	TestCommandName    = "test"
+	BracketCommandName = "["
+)
+
+// Режимы доступа к файлу для проверок -r, -w и -x (как в access(2)).
+const (
+	accessRead    = 4
+	accessWrite   = 2
+	accessExecute = 1
+)
+
+// testUnaryOperators - унарные операторы test: проверки файлов и строк.
+var testUnaryOperators = map[string]bool{
+	"-e": true, "-f": true, "-d": true, "-r": true, "-w": true, "-x": true, "-s": true,
+	"-L": true, "-h": true, "-p": true, "-S": true, "-b": true, "-c": true,
+	"-n": true, "-z": true,
+}
+
+// testBinaryOperators - бинарные операторы test: сравнения строк, целых чисел и файлов.
+var testBinaryOperators = map[string]bool{
+	"=": true, "==": true, "!=": true, "<": true, ">": true,
+	"-eq": true, "-ne": true, "-lt": true, "-le": true, "-gt": true, "-ge": true,
+	"-nt": true, "-ot": true, "-ef": true,
+}
+
+// TestCommand реализует встроенные команды test и [.
+// Вычисляет условное выражение и завершается с кодом 0, если оно истинно,
+// 1, если ложно, и 2 при ошибке в выражении.
+type TestCommand struct {
+	name string // test или [; команда [ требует закрывающего аргумента ]
+}
+
+// NewTestCommand создает новый экземпляр команды test.
+func NewTestCommand() *TestCommand {
+	return &TestCommand{name: TestCommandName}
+}
+
+// NewBracketCommand создает новый экземпляр команды [ - формы test с закрывающей ].
+func NewBracketCommand() *TestCommand {
+	return &TestCommand{name: BracketCommandName}
+}
+
+// Name возвращает имя команды: test или [.
+func (t *TestCommand) Name() string {
+	return t.name
+}
+
+// Execute выполняет команду test (или [ ... ]).
+// Поддерживаются проверки файлов (-e, -f, -d, -r, -w, -x, -s, -L, ...), строк (-n, -z,
+// =, !=, <, >), сравнения целых чисел (-eq, -ne, -lt, -le, -gt, -ge), сравнения файлов
+// (-nt, -ot, -ef), отрицание !, логические -a и -o и скобки ( ).
+// Выражения из 0-4 аргументов разбираются по правилам POSIX в зависимости от их числа.
+func (t *TestCommand) Execute(args []string, env map[string]string, _ io.Reader, _ io.Writer, stderr io.Writer) int {
+	if t.name == BracketCommandName {
+		if len(args) == 0 || args[len(args)-1] != "]" {
+			fmt.Fprintf(stderr, "%s: missing `]'\n", t.name)
+			return 2
+		}
+		args = args[:len(args)-1]
+	}
+
+	result, err := evaluateTest(env[PwdVariable], args)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", t.name, err)
+		return 2
+	}
+	if !result {
+		return 1
+	}
+	return 0
+}
+
+// evaluateTest вычисляет выражение test. Для 0-4 аргументов смысл выражения
+// определяется числом аргументов (так, в test -f один аргумент - непустая строка,
+// а не оператор), более длинные выражения разбираются с приоритетами: ! выше -a, -a выше -o.
+// Относительные пути в проверках файлов отсчитываются от рабочего каталога dir.
+func evaluateTest(dir string, args []string) (bool, error) {
+	switch len(args) {
+	case 0:
+		return false, nil
+	case 1:
+		return args[0] != "", nil
+	case 2:
+		if args[0] == "!" {
+			return args[1] == "", nil
+		}
+		if IsTestUnaryOperator(args[0]) {
+			return TestUnary(dir, args[0], args[1])
+		}
+		return false, fmt.Errorf("%s: unary operator expected", args[0])
+	case 3:
+		if IsTestBinaryOperator(args[1]) {
+			return TestBinary(dir, args[1], args[0], args[2])
+		}
+		if args[0] == "!" {
+			result, err := evaluateTest(dir, args[1:])
+			return !result, err
+		}
+	case 4:
+		if args[0] == "!" {
+			result, err := evaluateTest(dir, args[1:])
+			return !result, err
+		}
+		if args[0] == "(" && args[3] == ")" {
+			return evaluateTest(dir, args[1:3])
+		}
+	}
+
+	parser := &testParser{dir: dir, args: args}
+	result, err := parser.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if parser.pos < len(parser.args) {
+		return false, errors.New("too many arguments")
+	}
+	return result, nil
+}
+
+// testParser разбирает и вычисляет выражение test из произвольного числа аргументов.
+type testParser struct {
+	dir  string // Рабочий каталог для относительных путей
+	args []string
+	pos  int
+}
+
+// parseOr разбирает выражения, соединенные -o.
+func (p *testParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	for err == nil && p.pos < len(p.args) && p.args[p.pos] == "-o" {
+		p.pos++
+		var right bool
+		right, err = p.parseAnd()
+		result = result || right
+	}
+	return result, err
+}
+
+// parseAnd разбирает выражения, соединенные -a.
+func (p *testParser) parseAnd() (bool, error) {
+	result, err := p.parseNot()
+	for err == nil && p.pos < len(p.args) && p.args[p.pos] == "-a" {
+		p.pos++
+		var right bool
+		right, err = p.parseNot()
+		result = result && right
+	}
+	return result, err
+}
+
+// parseNot разбирает отрицание ! expr.
+func (p *testParser) parseNot() (bool, error) {
+	if p.pos+1 < len(p.args) && p.args[p.pos] == "!" {
+		p.pos++
+		result, err := p.parseNot()
+		return !result, err
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary разбирает простое выражение: сравнение arg1 op arg2, унарную
+// проверку op arg, выражение в скобках или строку (истинна, если не пуста).
+func (p *testParser) parsePrimary() (bool, error) {
+	if p.pos >= len(p.args) {
+		return false, errors.New("argument expected")
+	}
+
+	arg := p.args[p.pos]
+	switch {
+	case p.pos+2 < len(p.args) && IsTestBinaryOperator(p.args[p.pos+1]):
+		p.pos += 3
+		return TestBinary(p.dir, p.args[p.pos-2], arg, p.args[p.pos-1])
+	case arg == "(":
+		p.pos++
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if p.pos >= len(p.args) || p.args[p.pos] != ")" {
+			return false, errors.New("`)' expected")
+		}
+		p.pos++
+		return result, nil
+	case p.pos+1 < len(p.args) && IsTestUnaryOperator(arg):
+		p.pos += 2
+		return TestUnary(p.dir, arg, p.args[p.pos-1])
+	default:
+		p.pos++
+		return arg != "", nil
+	}
+}
+
+// IsTestUnaryOperator проверяет, является ли строка унарным оператором test.
+func IsTestUnaryOperator(op string) bool {
+	return testUnaryOperators[op]
+}
+
+// IsTestBinaryOperator проверяет, является ли строка бинарным оператором test.
+func IsTestBinaryOperator(op string) bool {
+	return testBinaryOperators[op]
+}
+
+// TestUnary вычисляет унарную проверку test: свойство файла (-e, -f, -d, ...)
+// или строки (-n, -z). Символические ссылки разыменовываются всеми проверками, кроме -L и -h.
+// Относительный путь к файлу отсчитывается от рабочего каталога dir (пустой - каталог процесса).
+func TestUnary(dir, op, operand string) (bool, error) {
+	switch op {
+	case "-n":
+		return operand != "", nil
+	case "-z":
+		return operand == "", nil
+	}
+
+	path := ResolvePath(dir, operand)
+	switch op {
+	case "-L", "-h":
+		info, err := os.Lstat(path)
+		return err == nil && info.Mode()&os.ModeSymlink != 0, nil
+	case "-r":
+		return accessible(path, accessRead), nil
+	case "-w":
+		return accessible(path, accessWrite), nil
+	case "-x":
+		return accessible(path, accessExecute), nil
+	}
+
+	if !IsTestUnaryOperator(op) {
+		return false, fmt.Errorf("%s: unary operator expected", op)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, nil
+	}
+	mode := info.Mode()
+	switch op {
+	case "-f":
+		return mode.IsRegular(), nil
+	case "-d":
+		return mode.IsDir(), nil
+	case "-s":
+		return info.Size() > 0, nil
+	case "-p":
+		return mode&os.ModeNamedPipe != 0, nil
+	case "-S":
+		return mode&os.ModeSocket != 0, nil
+	case "-b":
+		return mode&os.ModeDevice != 0 && mode&os.ModeCharDevice == 0, nil
+	case "-c":
+		return mode&os.ModeCharDevice != 0, nil
+	default: // -e
+		return true, nil
+	}
+}
+
+// TestBinary вычисляет бинарную проверку test: left op right.
+// Строки сравниваются побайтово, целые числа - как десятичные числа
+// (нечисловой операнд - ошибка), файлы - по времени изменения (-nt, -ot)
+// или по совпадению устройства и inode (-ef); относительные пути к файлам
+// отсчитываются от рабочего каталога dir.
+func TestBinary(dir, op, left, right string) (bool, error) {
+	switch op {
+	case "=", "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	case "<":
+		return left < right, nil
+	case ">":
+		return left > right, nil
+	case "-eq", "-ne", "-lt", "-le", "-gt", "-ge":
+		return compareIntegers(op, left, right)
+	case "-nt", "-ot", "-ef":
+		return compareFiles(op, ResolvePath(dir, left), ResolvePath(dir, right)), nil
+	default:
+		return false, fmt.Errorf("%s: binary operator expected", op)
+	}
+}
+
+// compareIntegers сравнивает операнды как целые числа.
+func compareIntegers(op, left, right string) (bool, error) {
+	a, err := parseTestInteger(left)
+	if err != nil {
+		return false, err
+	}
+	b, err := parseTestInteger(right)
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case "-eq":
+		return a == b, nil
+	case "-ne":
+		return a != b, nil
+	case "-lt":
+		return a < b, nil
+	case "-le":
+		return a <= b, nil
+	case "-gt":
+		return a > b, nil
+	default: // -ge
+		return a >= b, nil
+	}
+}
+
+// parseTestInteger разбирает целочисленный операнд; пробелы вокруг числа допускаются.
+func parseTestInteger(s string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: integer expression expected", s)
+	}
+	return n, nil
+}
+
+// compareFiles сравнивает файлы. Для -nt файл новее, если его время изменения больше
+// или если второго файла нет; -ot - обратная проверка.
+func compareFiles(op, left, right string) bool {
+	leftInfo, leftErr := os.Stat(left)
+	rightInfo, rightErr := os.Stat(right)
+
+	switch op {
+	case "-nt":
+		return leftErr == nil && (rightErr != nil || leftInfo.ModTime().After(rightInfo.ModTime()))
+	case "-ot":
+		return rightErr == nil && (leftErr != nil || leftInfo.ModTime().Before(rightInfo.ModTime()))
+	default: // -ef
+		return leftErr == nil && rightErr == nil && os.SameFile(leftInfo, rightInfo)
+	}
+}
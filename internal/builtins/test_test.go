@@ -0,0 +1,117 @@
+package builtins
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestTestCommand_Execute тестирует вычисление выражений команды test.
+func TestTestCommand_Execute(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	empty := filepath.Join(dir, "empty.txt")
+	older := filepath.Join(dir, "older.txt")
+	missing := filepath.Join(dir, "missing")
+	if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+		t.Fatalf("cannot create file: %v", err)
+	}
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatalf("cannot create file: %v", err)
+	}
+	if err := os.WriteFile(older, nil, 0o644); err != nil {
+		t.Fatalf("cannot create file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatalf("cannot change file time: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+	}{
+		{"no arguments", []string{}, 1},
+		{"non-empty string", []string{"abc"}, 0},
+		{"empty string", []string{""}, 1},
+		{"operator as string", []string{"-f"}, 0},
+		{"regular file", []string{"-f", file}, 0},
+		{"directory is not regular file", []string{"-f", dir}, 1},
+		{"directory", []string{"-d", dir}, 0},
+		{"file exists", []string{"-e", file}, 0},
+		{"missing file", []string{"-e", missing}, 1},
+		{"readable file", []string{"-r", file}, 0},
+		{"missing file is not readable", []string{"-r", missing}, 1},
+		{"non-empty file", []string{"-s", file}, 0},
+		{"empty file", []string{"-s", empty}, 1},
+		{"newer file", []string{file, "-nt", older}, 0},
+		{"older file", []string{older, "-nt", file}, 1},
+		{"newer than missing", []string{file, "-nt", missing}, 0},
+		{"older than", []string{older, "-ot", file}, 0},
+		{"same file", []string{file, "-ef", file}, 0},
+		{"zero length", []string{"-z", ""}, 0},
+		{"non-zero length", []string{"-n", "a"}, 0},
+		{"strings equal", []string{"a", "=", "a"}, 0},
+		{"strings differ", []string{"a", "!=", "b"}, 0},
+		{"strings less", []string{"a", "<", "b"}, 0},
+		{"integers equal", []string{"10", "-eq", "10"}, 0},
+		{"integers less", []string{"2", "-lt", "10"}, 0},
+		{"integers greater", []string{"2", "-gt", "10"}, 1},
+		{"negative integers", []string{"-3", "-le", "-3"}, 0},
+		{"not integer", []string{"a", "-eq", "1"}, 2},
+		{"negation", []string{"!", "-e", missing}, 0},
+		{"negated string", []string{"!", ""}, 0},
+		{"and", []string{"-f", file, "-a", "-d", dir}, 0},
+		{"and false", []string{"-f", file, "-a", "-f", dir}, 1},
+		{"or", []string{"-f", dir, "-o", "-d", dir}, 0},
+		{"and binds tighter than or", []string{"a", "-o", "", "-a", ""}, 0},
+		{"negation in compound expression", []string{"!", "-f", dir, "-a", "1", "-eq", "1"}, 0},
+		{"parentheses", []string{"(", "a", "-o", "", ")", "-a", ""}, 1},
+		{"parenthesized unary", []string{"(", "-n", "a", ")"}, 0},
+		{"unknown unary operator", []string{"-q", "a"}, 2},
+		{"unknown binary operator", []string{"a", "b", "c", "d", "e"}, 2},
+		{"unclosed parenthesis", []string{"(", "a", "-a", "b"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			code := NewTestCommand().Execute(tt.args, nil, nil, nil, &stderr)
+			if code != tt.expectedCode {
+				t.Errorf("test %q = %d, expected %d (stderr: %q)", tt.args, code, tt.expectedCode, stderr.String())
+			}
+		})
+	}
+}
+
+// TestBracketCommand_Execute тестирует форму [ ... ]: закрывающая ] обязательна.
+func TestBracketCommand_Execute(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedStderr string
+	}{
+		{"true expression", []string{"1", "-lt", "2", "]"}, 0, ""},
+		{"false expression", []string{"a", "=", "b", "]"}, 1, ""},
+		{"empty expression", []string{"]"}, 1, ""},
+		{"missing bracket", []string{"a", "=", "a"}, 2, "[: missing `]'\n"},
+		{"error in expression", []string{"x", "-gt", "1", "]"}, 2, "[: x: integer expression expected\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			code := NewBracketCommand().Execute(tt.args, nil, nil, nil, &stderr)
+			if code != tt.expectedCode {
+				t.Errorf("[ %q = %d, expected %d", tt.args, code, tt.expectedCode)
+			}
+			if stderr.String() != tt.expectedStderr {
+				t.Errorf("[ %q stderr = %q, expected %q", tt.args, stderr.String(), tt.expectedStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gocli/internal/environment"
+)
+
+const UnsetCommandName = "unset"
+
+// UnsetCommand реализует встроенную команду unset.
+// Удаляет переменные и функции shell'а.
+type UnsetCommand struct {
+	environment *environment.Environment // Окружение с переменными и функциями shell'а
+}
+
+// NewUnsetCommand создает новый экземпляр команды unset для окружения env.
+func NewUnsetCommand(env *environment.Environment) *UnsetCommand {
+	return &UnsetCommand{environment: env}
+}
+
+// Name возвращает имя команды unset.
+func (u *UnsetCommand) Name() string {
+	return UnsetCommandName
+}
+
+// Execute выполняет команду unset [-fv] name ...
+// Флаг -f удаляет функции, -v - переменные. Без флагов удаляется переменная,
+// а если переменной с таким именем нет - функция. Несуществующие имена не считаются
+// ошибкой; код возврата равен 1, если имя переменной некорректно.
+func (u *UnsetCommand) Execute(args []string, _ map[string]string, _ io.Reader, _ io.Writer, stderr io.Writer) int {
+	functions, variables := false, false
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		for _, flag := range args[0][1:] {
+			switch flag {
+			case 'f':
+				functions = true
+			case 'v':
+				variables = true
+			default:
+				fmt.Fprintf(stderr, "unset: -%c: invalid option\n", flag)
+				fmt.Fprintln(stderr, "unset: usage: unset [-f] [-v] [name ...]")
+				return 2
+			}
+		}
+		args = args[1:]
+	}
+
+	if functions && variables {
+		fmt.Fprintln(stderr, "unset: cannot simultaneously unset a function and a variable")
+		return 1
+	}
+
+	exitCode := 0
+	for _, name := range args {
+		if functions {
+			u.environment.UnsetFunction(name)
+			continue
+		}
+		if !isValidName(name) {
+			fmt.Fprintf(stderr, "unset: `%s': not a valid identifier\n", name)
+			exitCode = 1
+			continue
+		}
+		if _, exists := u.environment.Get(name); exists || variables {
+			u.environment.Delete(name)
+			continue
+		}
+		u.environment.UnsetFunction(name)
+	}
+	return exitCode
+}
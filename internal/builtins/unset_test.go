@@ -0,0 +1,106 @@
+package builtins
+
+import (
+	"bytes"
+	"testing"
+
+	"gocli/internal/environment"
+)
+
+// TestUnsetCommand_Execute тестирует удаление переменных и функций.
+func TestUnsetCommand_Execute(t *testing.T) {
+	env := environment.NewEnvironment()
+	command := NewUnsetCommand(env)
+
+	tests := []struct {
+		name         string
+		args         []string
+		setup        func()
+		expectedCode int
+		check        func(t *testing.T)
+	}{
+		{
+			name:  "variable",
+			args:  []string{"x"},
+			setup: func() { env.Set("x", "1") },
+			check: func(t *testing.T) {
+				if _, exists := env.Get("x"); exists {
+					t.Error("variable x should be removed")
+				}
+			},
+		},
+		{
+			name:  "function",
+			args:  []string{"-f", "greet"},
+			setup: func() { env.Set("greet", "1"); env.SetFunction(newTestFunction("greet")) },
+			check: func(t *testing.T) {
+				if _, exists := env.Function("greet"); exists {
+					t.Error("function greet should be removed")
+				}
+				if _, exists := env.Get("greet"); !exists {
+					t.Error("variable greet should not be removed by -f")
+				}
+			},
+		},
+		{
+			name:  "function without variable",
+			args:  []string{"build"},
+			setup: func() { env.SetFunction(newTestFunction("build")) },
+			check: func(t *testing.T) {
+				if _, exists := env.Function("build"); exists {
+					t.Error("function build should be removed")
+				}
+			},
+		},
+		{
+			name:  "variable only",
+			args:  []string{"-v", "build"},
+			setup: func() { env.SetFunction(newTestFunction("build")) },
+			check: func(t *testing.T) {
+				if _, exists := env.Function("build"); !exists {
+					t.Error("function build should not be removed by -v")
+				}
+			},
+		},
+		{
+			name:  "missing name",
+			args:  []string{"missing"},
+			setup: func() {},
+			check: func(t *testing.T) {},
+		},
+		{
+			name:         "invalid identifier",
+			args:         []string{"a-b"},
+			setup:        func() {},
+			expectedCode: 1,
+			check:        func(t *testing.T) {},
+		},
+		{
+			name:         "function and variable",
+			args:         []string{"-fv", "x"},
+			setup:        func() {},
+			expectedCode: 1,
+			check:        func(t *testing.T) {},
+		},
+		{
+			name:         "invalid flag",
+			args:         []string{"-x"},
+			setup:        func() {},
+			expectedCode: 2,
+			check:        func(t *testing.T) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			var stdout, stderr bytes.Buffer
+			exitCode := command.Execute(tt.args, nil, nil, &stdout, &stderr)
+
+			if exitCode != tt.expectedCode {
+				t.Errorf("exitCode = %d, expected %d (stderr %q)", exitCode, tt.expectedCode, stderr.String())
+			}
+			tt.check(t)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+
+	"gocli/internal/jobs"
+)
+
+const WaitCommandName = "wait"
+
+// statusNotChild - код возврата wait для PID или задания, неизвестного shell'у (как в bash).
+const statusNotChild = 127
+
+// WaitCommand реализует встроенную команду wait.
+// Ждет завершения фоновых заданий.
+type WaitCommand struct {
+	table *jobs.Table // Таблица заданий shell'а
+}
+
+// NewWaitCommand создает новый экземпляр команды wait для таблицы заданий table.
+func NewWaitCommand(table *jobs.Table) *WaitCommand {
+	return &WaitCommand{table: table}
+}
+
+// Name возвращает имя команды wait.
+func (w *WaitCommand) Name() string {
+	return WaitCommandName
+}
+
+// Execute выполняет команду wait.
+//
+// Поведение:
+//   - Без аргументов: ждет все задания и возвращает 0
+//   - С аргументами (PID или %N): ждет указанные задания, возвращает код последнего;
+//     для задания, о завершении которого уже сообщено, возвращается его сохраненный код
+//   - Неизвестное задание: сообщение в stderr и код 127
+//
+// Дождавшиеся задания удаляются из таблицы и не попадают в уведомления о завершении.
+func (w *WaitCommand) Execute(args []string, _ map[string]string, _ io.Reader, _ io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		for _, job := range w.table.List() {
+			job.Wait()
+			w.table.Remove(job)
+		}
+		return 0
+	}
+
+	exitCode := 0
+	for _, spec := range args {
+		job, err := w.table.FindWaitable(spec)
+		if err != nil {
+			fmt.Fprintf(stderr, "wait: %v\n", err)
+			exitCode = statusNotChild
+			continue
+		}
+		exitCode = job.Wait()
+		w.table.Remove(job)
+	}
+
+	return exitCode
+}
@@ -0,0 +1,64 @@
+package builtins
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gocli/internal/jobs"
+)
+
+// TestWaitCommand_Execute тестирует ожидание заданий командой wait.
+// wait %N возвращает код возврата задания, wait без аргументов ждет все задания и возвращает 0.
+func TestWaitCommand_Execute(t *testing.T) {
+	table := jobs.NewTable()
+	release := make(chan struct{})
+	startTestJob(table, "first", 5, release)
+	startTestJob(table, "second", 6, release)
+	close(release)
+
+	command := NewWaitCommand(table)
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{"%1"}, nil, nil, &stdout, &stderr); exitCode != 5 {
+		t.Errorf("wait %%1 exitCode = %d, expected 5", exitCode)
+	}
+	if exitCode := command.Execute([]string{}, nil, nil, &stdout, &stderr); exitCode != 0 {
+		t.Errorf("wait exitCode = %d, expected 0", exitCode)
+	}
+	if len(table.List()) != 0 {
+		t.Errorf("waited jobs should be removed, table = %v", table.List())
+	}
+}
+
+// TestWaitCommand_ExecuteUnknown тестирует wait для неизвестного PID.
+func TestWaitCommand_ExecuteUnknown(t *testing.T) {
+	command := NewWaitCommand(jobs.NewTable())
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := command.Execute([]string{"99999"}, nil, nil, &stdout, &stderr); exitCode != 127 {
+		t.Errorf("WaitCommand.Execute() exitCode = %d, expected 127", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "not a child of this shell") {
+		t.Errorf("stderr = %q, expected 'not a child of this shell'", stderr.String())
+	}
+}
+
+// TestWaitCommand_ExecuteReported тестирует wait для задания, о завершении которого
+// уже сообщено перед приглашением: wait возвращает его код один раз.
+func TestWaitCommand_ExecuteReported(t *testing.T) {
+	table := jobs.NewTable()
+	release := make(chan struct{})
+	close(release)
+	startTestJob(table, "exit 3", 3, release).Wait()
+	table.ReportDone(&bytes.Buffer{})
+
+	command := NewWaitCommand(table)
+	var stdout, stderr bytes.Buffer
+	if exitCode := command.Execute([]string{"%1"}, nil, nil, &stdout, &stderr); exitCode != 3 {
+		t.Errorf("wait %%1 exitCode = %d, expected 3 (stderr %q)", exitCode, stderr.String())
+	}
+	if exitCode := command.Execute([]string{"%1"}, nil, nil, &stdout, &stderr); exitCode != 127 {
+		t.Errorf("second wait %%1 exitCode = %d, expected 127", exitCode)
+	}
+}
@@ -27,7 +27,7 @@ func (w *WcCommand) Name() string {
 // Execute выполняет команду wc.
 // Если аргументы не переданы, читает из стандартного ввода.
 // Иначе читает указанный файл и подсчитывает статистику.
-func (w *WcCommand) Execute(args []string, _ map[string]string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+func (w *WcCommand) Execute(args []string, env map[string]string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
 	var input io.Reader
 	var filename string
 
@@ -35,7 +35,7 @@ func (w *WcCommand) Execute(args []string, _ map[string]string, stdin io.Reader,
 		input = stdin
 	} else {
 		filename = args[0]
-		file, err := os.Open(filename)
+		file, err := OpenFile(env[PwdVariable], filename, os.O_RDONLY, 0)
 		if err != nil {
 			fmt.Fprintf(stderr, "wc: %s: %v\n", filename, err)
 			return 1
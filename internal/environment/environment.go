@@ -1,27 +1,55 @@
 package environment
 
 import (
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+
+	"gocli/internal/parser"
 )
 
 const envVarParts = 2
 
+// Параметры shell'а, управляемые командой shopt.
+const (
+	OptionFailGlob = "failglob" // Шаблон без совпадений считается ошибкой
+	OptionNullGlob = "nullglob" // Шаблон без совпадений раскрывается в пустой список
+)
+
+// shellOptions содержит имена поддерживаемых параметров в алфавитном порядке.
+var shellOptions = []string{OptionFailGlob, OptionNullGlob}
+
 // Environment управляет переменными окружения shell'а.
-// Поддерживает как глобальные (системные), так и локальные переменные сессии.
+// Поддерживает как глобальные (системные), так и локальные переменные сессии,
+// а также области видимости переменных, объявленных в функциях командой local.
+// Кроме переменных, окружение хранит индексированные массивы (например, BASH_REMATCH),
+// функции, определенные в shell'е, и рабочий каталог shell'а.
 type Environment struct {
-	mu     sync.RWMutex      // Мьютекс для защиты доступа к maps
-	global map[string]string // Глобальные переменные (наследуются от системы)
-	local  map[string]string // Локальные переменные сессии
+	mu        sync.RWMutex                // Мьютекс для защиты доступа к maps
+	global    map[string]string           // Глобальные переменные (наследуются от системы)
+	local     map[string]string           // Локальные переменные сессии
+	scopes    []map[string]string         // Области видимости вызванных функций; последняя - внутренняя
+	arrays    map[string][]string         // Индексированные массивы; элемент 0 - значение переменной
+	special   map[string]string           // Специальные параметры shell'а ($!, ...)
+	options   map[string]bool             // Включенные параметры shell'а (shopt)
+	params    []string                    // Позиционные параметры ($1, $2, ...)
+	functions map[string]*parser.Function // Функции shell'а по именам
+	dir       string                      // Рабочий каталог shell'а; пустой - каталог процесса
 }
 
 // NewEnvironment создает новое окружение.
-// Инициализирует глобальные переменные из системного окружения.
+// Инициализирует глобальные переменные из системного окружения, а рабочий каталог
+// и переменную PWD - из рабочего каталога процесса.
 func NewEnvironment() *Environment {
 	env := &Environment{
-		global: make(map[string]string),
-		local:  make(map[string]string),
+		global:    make(map[string]string),
+		local:     make(map[string]string),
+		special:   make(map[string]string),
+		options:   make(map[string]bool),
+		arrays:    make(map[string][]string),
+		functions: make(map[string]*parser.Function),
 	}
 
 	for _, envVar := range os.Environ() {
@@ -31,23 +59,111 @@ func NewEnvironment() *Environment {
 		}
 	}
 
+	if dir, err := os.Getwd(); err == nil {
+		env.dir = dir
+		env.global["PWD"] = dir
+	}
+
 	return env
 }
 
+// Clone возвращает независимую копию окружения (например, для подоболочки):
+// переменные, области видимости функций, специальные и позиционные параметры,
+// параметры shell'а, функции и рабочий каталог копируются, и их изменения в копии
+// не влияют на оригинал.
+func (env *Environment) Clone() *Environment {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+
+	clone := &Environment{
+		global:    maps.Clone(env.global),
+		local:     maps.Clone(env.local),
+		special:   maps.Clone(env.special),
+		options:   maps.Clone(env.options),
+		params:    slices.Clone(env.params),
+		arrays:    make(map[string][]string, len(env.arrays)),
+		functions: maps.Clone(env.functions),
+		dir:       env.dir,
+	}
+	for name, values := range env.arrays {
+		clone.arrays[name] = slices.Clone(values)
+	}
+	for _, scope := range env.scopes {
+		clone.scopes = append(clone.scopes, maps.Clone(scope))
+	}
+	return clone
+}
+
+// Dir возвращает рабочий каталог shell'а. Каталог хранится в окружении, а не
+// в процессе, поэтому cd в подоболочке или фоновом задании не влияет на остальные команды.
+// Пустая строка означает рабочий каталог процесса.
+func (env *Environment) Dir() string {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	return env.dir
+}
+
+// SetDir устанавливает рабочий каталог shell'а (абсолютный путь).
+// Переменные PWD и OLDPWD не меняются: их обновляет команда cd.
+func (env *Environment) SetDir(dir string) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	env.dir = dir
+}
+
 // Set устанавливает переменную в локальном окружении.
 // Локальные переменные имеют приоритет над глобальными.
+// Если переменная объявлена в области видимости вызванной функции (local),
+// изменяется значение в ближайшей такой области, как при динамической видимости в bash.
+// Для массива устанавливается его элемент 0.
 func (env *Environment) Set(name, value string) {
 	env.mu.Lock()
 	defer env.mu.Unlock()
-	env.local[name] = value
+	env.variables(name)[name] = value
+	if values, exists := env.arrays[name]; exists {
+		values[0] = value
+	}
+}
+
+// SetArray устанавливает индексированный массив: элементы доступны как ${name[i]},
+// а значение переменной name ($name) - элемент 0. Пустой массив удаляет переменную.
+// Массивы не бывают локальными для функций.
+func (env *Environment) SetArray(name string, values []string) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if len(values) == 0 {
+		delete(env.arrays, name)
+		delete(env.variables(name), name)
+		return
+	}
+	env.arrays[name] = slices.Clone(values)
+	env.variables(name)[name] = values[0]
+}
+
+// Array возвращает копию элементов массива. Заданная переменная, не являющаяся
+// массивом, считается массивом из одного элемента, как в bash.
+// Возвращает элементы и флаг существования.
+func (env *Environment) Array(name string) ([]string, bool) {
+	value, ok := env.Get(name)
+	if !ok {
+		return nil, false
+	}
+
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	if values, exists := env.arrays[name]; exists {
+		return slices.Clone(values), true
+	}
+	return []string{value}, true
 }
 
 // Get возвращает значение переменной.
-// Сначала проверяет локальные, затем глобальные переменные.
+// Сначала проверяет области видимости функций (от внутренней к внешней),
+// затем локальные и глобальные переменные.
 func (env *Environment) Get(name string) (string, bool) {
 	env.mu.RLock()
 	defer env.mu.RUnlock()
-	if value, exists := env.local[name]; exists {
+	if value, exists := env.variables(name)[name]; exists {
 		return value, true
 	}
 	if value, exists := env.global[name]; exists {
@@ -72,6 +188,12 @@ func (env *Environment) GetAll() []string {
 		all[k] = v
 	}
 
+	for _, scope := range env.scopes {
+		for k, v := range scope {
+			all[k] = v
+		}
+	}
+
 	var result []string
 	for k, v := range all {
 		result = append(result, k+"="+v)
@@ -79,11 +201,30 @@ func (env *Environment) GetAll() []string {
 	return result
 }
 
-// Unset удаляет переменную из локального окружения.
+// Unset удаляет переменную из локального окружения
+// (или из ближайшей области видимости функции, в которой она объявлена).
+// Глобальная переменная с тем же именем снова становится видимой.
 func (env *Environment) Unset(name string) {
 	env.mu.Lock()
 	defer env.mu.Unlock()
+	delete(env.variables(name), name)
+	delete(env.arrays, name)
+}
+
+// Delete удаляет переменную, как команда unset: из ближайшей области видимости
+// функции, в которой она объявлена, а если таких нет - из локального и глобального окружений.
+func (env *Environment) Delete(name string) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	delete(env.arrays, name)
+	for i := len(env.scopes) - 1; i >= 0; i-- {
+		if _, exists := env.scopes[i][name]; exists {
+			delete(env.scopes[i], name)
+			return
+		}
+	}
 	delete(env.local, name)
+	delete(env.global, name)
 }
 
 // ClearLocal очищает все локальные переменные.
@@ -134,14 +275,22 @@ func (env *Environment) GetAllMap() map[string]string {
 		result[k] = v
 	}
 
+	// Переменные функций перекрывают переменные сессии
+	for _, scope := range env.scopes {
+		for k, v := range scope {
+			result[k] = v
+		}
+	}
+
 	return result
 }
 
-// HasLocal проверяет, существует ли переменная в локальном окружении.
+// HasLocal проверяет, существует ли переменная в локальном окружении
+// или в области видимости вызванной функции.
 func (env *Environment) HasLocal(name string) bool {
 	env.mu.RLock()
 	defer env.mu.RUnlock()
-	_, exists := env.local[name]
+	_, exists := env.variables(name)[name]
 	return exists
 }
 
@@ -153,11 +302,180 @@ func (env *Environment) HasGlobal(name string) bool {
 	return exists
 }
 
-// GetLocal возвращает значение переменной из локального окружения.
+// GetLocal возвращает значение переменной из локального окружения
+// или из области видимости вызванной функции.
 // Возвращает значение и флаг существования.
 func (env *Environment) GetLocal(name string) (string, bool) {
 	env.mu.RLock()
 	defer env.mu.RUnlock()
-	value, exists := env.local[name]
+	value, exists := env.variables(name)[name]
+	return value, exists
+}
+
+// variables возвращает набор переменных, в котором хранится переменная name:
+// ближайшую область видимости функции, где она объявлена, иначе локальное окружение.
+// Вызывается под мьютексом.
+func (env *Environment) variables(name string) map[string]string {
+	for i := len(env.scopes) - 1; i >= 0; i-- {
+		if _, exists := env.scopes[i][name]; exists {
+			return env.scopes[i]
+		}
+	}
+	return env.local
+}
+
+// PushScope создает область видимости для переменных вызываемой функции.
+// Переменные, объявленные в ней через Declare, перекрывают одноименные внешние
+// и удаляются вызовом PopScope.
+func (env *Environment) PushScope() {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	env.scopes = append(env.scopes, make(map[string]string))
+}
+
+// PopScope удаляет внутреннюю область видимости вместе с её переменными.
+func (env *Environment) PopScope() {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if len(env.scopes) > 0 {
+		env.scopes = env.scopes[:len(env.scopes)-1]
+	}
+}
+
+// InScope проверяет, выполняется ли сейчас функция (есть ли область видимости).
+func (env *Environment) InScope() bool {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	return len(env.scopes) > 0
+}
+
+// ScopeVariables возвращает копию переменных внутренней области видимости функции;
+// вне функций возвращает пустой map.
+func (env *Environment) ScopeVariables() map[string]string {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	result := make(map[string]string)
+	if len(env.scopes) == 0 {
+		return result
+	}
+	for k, v := range env.scopes[len(env.scopes)-1] {
+		result[k] = v
+	}
+	return result
+}
+
+// Declare объявляет переменную во внутренней области видимости функции.
+// Вне функций переменная устанавливается в локальном окружении, как Set.
+func (env *Environment) Declare(name, value string) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	if len(env.scopes) == 0 {
+		env.local[name] = value
+		return
+	}
+	env.scopes[len(env.scopes)-1][name] = value
+}
+
+// SetSpecial устанавливает значение специального параметра shell'а (например, "!" для $!).
+// Специальные параметры не передаются внешним командам и не видны через Get.
+func (env *Environment) SetSpecial(name, value string) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	env.special[name] = value
+}
+
+// GetSpecial возвращает значение специального параметра shell'а.
+// Возвращает значение и флаг существования.
+func (env *Environment) GetSpecial(name string) (string, bool) {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	value, exists := env.special[name]
 	return value, exists
 }
+
+// Options возвращает имена всех поддерживаемых параметров shell'а в алфавитном порядке.
+func Options() []string {
+	return append([]string(nil), shellOptions...)
+}
+
+// SetOption включает или выключает параметр shell'а.
+// Возвращает false, если параметр с таким именем не поддерживается.
+func (env *Environment) SetOption(name string, enabled bool) bool {
+	if !isOption(name) {
+		return false
+	}
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	env.options[name] = enabled
+	return true
+}
+
+// Option проверяет, включен ли параметр shell'а.
+func (env *Environment) Option(name string) bool {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	return env.options[name]
+}
+
+// isOption проверяет, поддерживается ли параметр shell'а с именем name.
+func isOption(name string) bool {
+	for _, option := range shellOptions {
+		if option == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPositional устанавливает позиционные параметры $1, $2, ... (например, аргументы скрипта).
+func (env *Environment) SetPositional(params []string) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	env.params = append([]string(nil), params...)
+}
+
+// Positional возвращает копию позиционных параметров.
+func (env *Environment) Positional() []string {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	return append([]string(nil), env.params...)
+}
+
+// SetFunction определяет функцию shell'а (или заменяет функцию с тем же именем).
+func (env *Environment) SetFunction(fn *parser.Function) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	env.functions[fn.Name] = fn
+}
+
+// Function возвращает функцию shell'а по имени.
+// Возвращает функцию и флаг существования.
+func (env *Environment) Function(name string) (*parser.Function, bool) {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	fn, exists := env.functions[name]
+	return fn, exists
+}
+
+// UnsetFunction удаляет функцию shell'а. Возвращает false, если функции не было.
+func (env *Environment) UnsetFunction(name string) bool {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	_, exists := env.functions[name]
+	delete(env.functions, name)
+	return exists
+}
+
+// Functions возвращает все функции shell'а в алфавитном порядке имен.
+func (env *Environment) Functions() []*parser.Function {
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	result := make([]*parser.Function, 0, len(env.functions))
+	for _, fn := range env.functions {
+		result = append(result, fn)
+	}
+	slices.SortFunc(result, func(a, b *parser.Function) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
@@ -1,7 +1,10 @@
 package environment
 
 import (
+	"slices"
 	"testing"
+
+	"gocli/internal/parser"
 )
 
 const (
@@ -293,3 +296,264 @@ func TestEnvironment_UnsetGlobalVariable(t *testing.T) {
 		t.Errorf("Expected '%s' after unset, got '%s'", testGlobalValue, value)
 	}
 }
+
+// TestEnvironment_Options тестирует включение и выключение параметров shell'а.
+func TestEnvironment_Options(t *testing.T) {
+	env := NewEnvironment()
+
+	if env.Option(OptionNullGlob) {
+		t.Error("nullglob should be disabled by default")
+	}
+
+	if !env.SetOption(OptionNullGlob, true) {
+		t.Fatal("SetOption should accept nullglob")
+	}
+	if !env.Option(OptionNullGlob) {
+		t.Error("nullglob should be enabled")
+	}
+
+	env.SetOption(OptionNullGlob, false)
+	if env.Option(OptionNullGlob) {
+		t.Error("nullglob should be disabled")
+	}
+
+	// Неизвестный параметр не сохраняется
+	if env.SetOption("nonexistent", true) {
+		t.Error("SetOption should reject unknown option")
+	}
+	if env.Option("nonexistent") {
+		t.Error("unknown option should be disabled")
+	}
+}
+
+// TestEnvironment_Positional тестирует установку позиционных параметров.
+func TestEnvironment_Positional(t *testing.T) {
+	env := NewEnvironment()
+	if len(env.Positional()) != 0 {
+		t.Errorf("expected no positional parameters, got %v", env.Positional())
+	}
+
+	params := []string{"a", "b c"}
+	env.SetPositional(params)
+	params[0] = "changed"
+
+	got := env.Positional()
+	if len(got) != 2 || got[0] != "a" || got[1] != "b c" {
+		t.Errorf("Positional() = %v, expected [a b c]", got)
+	}
+
+	// Изменение результата не влияет на окружение
+	got[1] = "changed"
+	if env.Positional()[1] != "b c" {
+		t.Error("Positional() should return a copy")
+	}
+}
+
+// TestEnvironment_Scopes тестирует области видимости переменных функций:
+// объявленные переменные перекрывают внешние, присваивание изменяет ближайшее
+// объявление, а после удаления области внешние значения снова видны.
+func TestEnvironment_Scopes(t *testing.T) {
+	env := NewEnvironment()
+	env.Set("x", "outer")
+	env.Set("y", "outer")
+
+	if env.InScope() {
+		t.Error("InScope() = true outside of function")
+	}
+
+	env.PushScope()
+	if !env.InScope() {
+		t.Error("InScope() = false inside function")
+	}
+	env.Declare("x", "inner")
+	env.Set("y", "changed")
+	env.Set("z", "new")
+
+	if value, _ := env.Get("x"); value != "inner" {
+		t.Errorf("Get(x) = %q, expected %q", value, "inner")
+	}
+	if value, _ := env.GetLocal("x"); value != "inner" {
+		t.Errorf("GetLocal(x) = %q, expected %q", value, "inner")
+	}
+	if env.GetAllMap()["x"] != "inner" {
+		t.Errorf("GetAllMap()[x] = %q, expected %q", env.GetAllMap()["x"], "inner")
+	}
+	if scope := env.ScopeVariables(); len(scope) != 1 || scope["x"] != "inner" {
+		t.Errorf("ScopeVariables() = %v, expected map[x:inner]", scope)
+	}
+
+	// Вложенная функция видит и изменяет переменную вызывающей функции
+	env.PushScope()
+	env.Set("x", "nested")
+	env.PopScope()
+	if value, _ := env.Get("x"); value != "nested" {
+		t.Errorf("Get(x) = %q, expected %q", value, "nested")
+	}
+
+	env.PopScope()
+	if value, _ := env.Get("x"); value != "outer" {
+		t.Errorf("Get(x) after PopScope = %q, expected %q", value, "outer")
+	}
+	if value, _ := env.Get("y"); value != "changed" {
+		t.Errorf("Get(y) = %q, expected %q", value, "changed")
+	}
+	if value, _ := env.Get("z"); value != "new" {
+		t.Errorf("Get(z) = %q, expected %q", value, "new")
+	}
+
+	// Вне функций Declare работает как Set, а лишний PopScope ничего не делает
+	env.PopScope()
+	env.Declare("w", testValue)
+	if value, _ := env.Get("w"); value != testValue {
+		t.Errorf("Get(w) = %q, expected %q", value, testValue)
+	}
+}
+
+// TestEnvironment_Delete тестирует удаление переменных командой unset:
+// локальная переменная функции удаляется только из её области видимости,
+// а переменная сессии - вместе с глобальной.
+func TestEnvironment_Delete(t *testing.T) {
+	env := NewEnvironment()
+	env.global["DELETE_VAR"] = testGlobalValue
+	env.Set("DELETE_VAR", testLocalValue)
+
+	env.PushScope()
+	env.Declare("DELETE_VAR", testValue)
+	env.Delete("DELETE_VAR")
+	if value, _ := env.Get("DELETE_VAR"); value != testLocalValue {
+		t.Errorf("Get() after deleting local = %q, expected %q", value, testLocalValue)
+	}
+	env.PopScope()
+
+	env.Delete("DELETE_VAR")
+	if _, exists := env.Get("DELETE_VAR"); exists {
+		t.Error("variable should be deleted from local and global environments")
+	}
+}
+
+// TestEnvironment_Functions тестирует хранение функций shell'а.
+func TestEnvironment_Functions(t *testing.T) {
+	env := NewEnvironment()
+	body := &parser.Group{Body: &parser.List{}}
+	env.SetFunction(&parser.Function{Name: "b", Body: body})
+	env.SetFunction(&parser.Function{Name: "a", Body: body})
+
+	fn, exists := env.Function("a")
+	if !exists || fn.Name != "a" {
+		t.Errorf("Function(a) = %v, %v", fn, exists)
+	}
+
+	functions := env.Functions()
+	if len(functions) != 2 || functions[0].Name != "a" || functions[1].Name != "b" {
+		t.Errorf("Functions() = %v, expected a and b in order", functions)
+	}
+
+	if !env.UnsetFunction("a") {
+		t.Error("UnsetFunction(a) = false, expected true")
+	}
+	if env.UnsetFunction("a") {
+		t.Error("UnsetFunction(a) = true for removed function")
+	}
+	if _, exists := env.Function("a"); exists {
+		t.Error("function a should be removed")
+	}
+}
+
+// TestEnvironment_Clone тестирует копирование окружения: изменения переменных,
+// параметров и функций в копии не влияют на оригинал.
+func TestEnvironment_Clone(t *testing.T) {
+	env := NewEnvironment()
+	env.Set("x", "original")
+	env.SetPositional([]string{"a"})
+	env.SetSpecial("?", "0")
+	env.SetOption(OptionNullGlob, true)
+	env.PushScope()
+	env.Declare("y", "scoped")
+	env.SetDir("/original")
+
+	clone := env.Clone()
+	if value, _ := clone.Get("y"); value != "scoped" {
+		t.Errorf("clone Get(y) = %q, expected %q", value, "scoped")
+	}
+	if dir := clone.Dir(); dir != "/original" {
+		t.Errorf("clone Dir() = %q, expected %q", dir, "/original")
+	}
+
+	clone.Set("x", "changed")
+	clone.Set("y", "changed")
+	clone.SetPositional([]string{"b"})
+	clone.SetSpecial("?", "1")
+	clone.SetOption(OptionNullGlob, false)
+	clone.SetFunction(&parser.Function{Name: "f", Body: &parser.Group{Body: &parser.List{}}})
+	clone.PopScope()
+	clone.SetDir("/changed")
+
+	if value, _ := env.Get("x"); value != "original" {
+		t.Errorf("Get(x) = %q, expected %q", value, "original")
+	}
+	if value, _ := env.Get("y"); value != "scoped" {
+		t.Errorf("Get(y) = %q, expected %q", value, "scoped")
+	}
+	if params := env.Positional(); len(params) != 1 || params[0] != "a" {
+		t.Errorf("Positional() = %v, expected [a]", params)
+	}
+	if value, _ := env.GetSpecial("?"); value != "0" {
+		t.Errorf("GetSpecial(?) = %q, expected %q", value, "0")
+	}
+	if !env.Option(OptionNullGlob) {
+		t.Error("option nullglob should stay enabled")
+	}
+	if _, exists := env.Function("f"); exists {
+		t.Error("function defined in clone should not appear in original")
+	}
+	if dir := env.Dir(); dir != "/original" {
+		t.Errorf("Dir() = %q, expected %q", dir, "/original")
+	}
+	if !env.InScope() {
+		t.Error("PopScope in clone should not remove original scope")
+	}
+}
+
+// TestEnvironment_Arrays тестирует индексированные массивы: элемент 0 - значение переменной,
+// обычная переменная - массив из одного элемента, unset удаляет массив.
+func TestEnvironment_Arrays(t *testing.T) {
+	env := NewEnvironment()
+	env.SetArray("arr", []string{"a", "b", "c"})
+
+	if values, ok := env.Array("arr"); !ok || !slices.Equal(values, []string{"a", "b", "c"}) {
+		t.Errorf("Array(arr) = %v, %v, expected [a b c], true", values, ok)
+	}
+	if value, _ := env.Get("arr"); value != "a" {
+		t.Errorf("Get(arr) = %q, expected %q", value, "a")
+	}
+
+	env.Set("arr", "x")
+	if values, _ := env.Array("arr"); !slices.Equal(values, []string{"x", "b", "c"}) {
+		t.Errorf("Array(arr) after Set = %v, expected [x b c]", values)
+	}
+
+	clone := env.Clone()
+	clone.SetArray("arr", []string{"changed"})
+	if values, _ := env.Array("arr"); len(values) != 3 {
+		t.Errorf("SetArray in clone changed original array: %v", values)
+	}
+
+	env.Set("scalar", "v")
+	if values, ok := env.Array("scalar"); !ok || !slices.Equal(values, []string{"v"}) {
+		t.Errorf("Array(scalar) = %v, %v, expected [v], true", values, ok)
+	}
+
+	env.Delete("arr")
+	if _, ok := env.Array("arr"); ok {
+		t.Error("array should be removed by Delete")
+	}
+	env.Set("arr", "plain")
+	if values, _ := env.Array("arr"); !slices.Equal(values, []string{"plain"}) {
+		t.Errorf("Array(arr) after Delete and Set = %v, expected [plain]", values)
+	}
+
+	env.SetArray("arr", nil)
+	if _, ok := env.Get("arr"); ok {
+		t.Error("empty array should unset variable")
+	}
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gocli/internal/builtins"
+	"gocli/internal/jobs"
+	"gocli/internal/parser"
+)
+
+// startBackground запускает элемент списка, завершенный оператором &, как фоновое задание.
+// Shell не ждет ни завершения задания, ни запуска его команд: задание регистрируется
+// в таблице заданий, его идентификатор сохраняется в специальный параметр $!
+// (wait $! ждет именно это задание), а в интерактивном режиме в stderr выводится
+// строка "[N] ID", как в bash. Код возврата запуска всегда 0.
+//
+// Задание выполняется в подоболочке, созданной в момент запуска: присваивания, cd
+// и переменные циклов в нем не видны shell'у, а exit завершает только задание.
+//
+// Управления терминалом (групп процессов) нет, поэтому фоновое задание читает
+// стандартный ввод из /dev/null и не конкурирует с shell'ом за ввод пользователя.
+func (exec *Executor) startBackground(item *parser.AndOr, ctx *execContext) (int, error) {
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		return statusFailure, fmt.Errorf("cannot start background job: %w", err)
+	}
+
+	child := exec.subshell()
+	job := exec.jobs.Start(item.String(), func(job *jobs.Job) int {
+		defer stdin.Close()
+
+		jobCtx := &execContext{
+			stdio: &builtins.IO{Stdin: stdin, Stdout: ctx.stdio.Stdout, Stderr: ctx.stdio.Stderr},
+			job:   job,
+		}
+		status, err := subshellResult(child.executeAndOr(item, jobCtx))
+		child.reportError(err, jobCtx.stdio)
+		return status
+	})
+
+	pid := strconv.Itoa(job.Pid())
+	exec.environment.SetSpecial("!", pid)
+	if exec.interactive {
+		fmt.Fprintf(ctx.stdio.Stderr, "[%d] %s\n", job.ID, pid)
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	osexec "os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gocli/internal/builtins"
+	"gocli/internal/expander"
+	"gocli/internal/parser"
+)
+
+// TestExecutor_BackgroundBuiltin проверяет запуск задания из встроенных команд в фоне
+// и ожидание его командой wait.
+func TestExecutor_BackgroundBuiltin(t *testing.T) {
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	list := &parser.List{Items: []*parser.AndOr{{
+		Pipelines: []parser.Node{&parser.Command{
+			Name:      "echo",
+			Args:      []*parser.Argument{word("hello")},
+			Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(out)}},
+		}},
+		Background: true,
+	}}}
+
+	if err := executor.Execute(list); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if len(executor.Jobs().List()) != 1 {
+		t.Fatalf("expected one job in table, got %v", executor.Jobs().List())
+	}
+
+	if err := executor.Execute(&parser.Command{Name: "wait"}); err != nil {
+		t.Fatalf("wait error = %v", err)
+	}
+	if got := readFile(t, out); got != "hello\n" {
+		t.Errorf("file content = %q, expected %q", got, "hello\n")
+	}
+	if len(executor.Jobs().List()) != 0 {
+		t.Errorf("expected empty job table after wait, got %v", executor.Jobs().List())
+	}
+}
+
+// TestExecutor_BackgroundExternal проверяет фоновый запуск внешней программы:
+// PID процесса доступен через $!, а wait $! возвращает код возврата задания.
+func TestExecutor_BackgroundExternal(t *testing.T) {
+	if _, err := osexec.LookPath("sh"); err != nil {
+		t.Skip("sh not found, skipping test")
+	}
+
+	executor := NewExecutor()
+	list := &parser.List{Items: []*parser.AndOr{{
+		Pipelines:  []parser.Node{&parser.Command{Name: "sh", Args: []*parser.Argument{word("-c"), word("exit 3")}}},
+		Background: true,
+	}}}
+
+	if err := executor.Execute(list); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if executor.LastStatus() != 0 {
+		t.Errorf("background start status = %d, expected 0", executor.LastStatus())
+	}
+
+	pid, ok := executor.environment.GetSpecial("!")
+	if !ok || pid == "" {
+		t.Fatal("expected $! to be set after background start")
+	}
+	if pid != strconv.Itoa(executor.Jobs().List()[0].Pid()) {
+		t.Errorf("$! = %s, expected job pid %d", pid, executor.Jobs().List()[0].Pid())
+	}
+
+	// wait возвращает код задания; ненулевой код сообщается как ExitStatusError
+	_ = executor.Execute(&parser.Command{Name: "wait", Args: []*parser.Argument{word(pid)}})
+	if executor.LastStatus() != 3 {
+		t.Errorf("wait $! status = %d, expected 3", executor.LastStatus())
+	}
+}
+
+// TestExecutor_BackgroundWaitPid проверяет, что $! задан и для задания без внешних
+// программ, а wait $! ждет именно это задание и возвращает его код возврата.
+func TestExecutor_BackgroundWaitPid(t *testing.T) {
+	executor := NewExecutor()
+	list := &parser.List{Items: []*parser.AndOr{
+		{Pipelines: []parser.Node{&parser.Subshell{Body: &parser.List{Items: []*parser.AndOr{
+			{Pipelines: []parser.Node{&parser.Command{Name: "exit", Args: []*parser.Argument{word("3")}}}},
+		}}}}, Background: true},
+	}}
+	if err := executor.Execute(list); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+
+	pid, _ := executor.environment.GetSpecial("!")
+	if pid == "" {
+		t.Fatal("expected $! to be set for subshell job")
+	}
+	_ = executor.Execute(&parser.Command{Name: "wait", Args: []*parser.Argument{word(pid)}})
+	if executor.LastStatus() != 3 {
+		t.Errorf("wait $! status = %d, expected 3", executor.LastStatus())
+	}
+}
+
+// TestExecutor_BackgroundStartDoesNotBlock проверяет, что запуск задания не ждет
+// его первой команды: подстановка команды в задании выполняется уже в фоне.
+func TestExecutor_BackgroundStartDoesNotBlock(t *testing.T) {
+	if _, err := osexec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not found, skipping test")
+	}
+
+	executor := NewExecutor()
+	executor.SetExpander(expander.NewExpander(executor.environment))
+	list := &parser.List{Items: []*parser.AndOr{{
+		Pipelines: []parser.Node{&parser.Command{Assignments: []*parser.Assignment{
+			{Name: "x", Value: word("$(sleep 1)")},
+		}}},
+		Background: true,
+	}}}
+
+	start := time.Now()
+	if err := executor.Execute(list); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("background start took %v, expected no wait for the job", elapsed)
+	}
+	for _, job := range executor.Jobs().List() {
+		job.Wait()
+	}
+}
+
+// TestExecutor_BackgroundAnnouncement проверяет, что строка "[N] ID" о запуске
+// фонового задания выводится только в интерактивном режиме, в том числе для задания
+// только из встроенных команд.
+func TestExecutor_BackgroundAnnouncement(t *testing.T) {
+	tests := []struct {
+		name        string
+		interactive bool
+	}{
+		{name: "interactive", interactive: true},
+		{name: "script", interactive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			executor.SetInteractive(tt.interactive)
+			list := &parser.List{Items: []*parser.AndOr{{
+				Pipelines:  []parser.Node{&parser.Command{Name: "echo"}},
+				Background: true,
+			}}}
+
+			var stderr bytes.Buffer
+			executeWithIO(executor, list, &builtins.IO{Stdin: strings.NewReader(""), Stdout: io.Discard, Stderr: &stderr})
+			want := ""
+			if tt.interactive {
+				pid, _ := executor.environment.GetSpecial("!")
+				want = "[1] " + pid + "\n"
+			}
+			if got := stderr.String(); got != want {
+				t.Errorf("stderr = %q, expected %q", got, want)
+			}
+			for _, job := range executor.Jobs().List() {
+				job.Wait()
+			}
+		})
+	}
+}
+
+// TestExecutor_BackgroundSubshell проверяет, что фоновое задание выполняется в подоболочке:
+// присваивания и cd в нем не видны shell'у, а exit завершает только задание.
+func TestExecutor_BackgroundSubshell(t *testing.T) {
+	executor := NewExecutor()
+	dir := executor.environment.Dir()
+
+	background := func(cmd *parser.Command) *parser.AndOr {
+		return &parser.AndOr{Pipelines: []parser.Node{cmd}, Background: true}
+	}
+	list := &parser.List{Items: []*parser.AndOr{
+		background(&parser.Command{Assignments: []*parser.Assignment{{Name: "X", Value: word("1")}}}),
+		background(&parser.Command{Name: "cd", Args: []*parser.Argument{word(t.TempDir())}}),
+		background(&parser.Command{Name: "exit", Args: []*parser.Argument{word("3")}}),
+	}}
+
+	if err := executor.Execute(list); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	statuses := make([]int, 0, 3)
+	for _, job := range executor.Jobs().List() {
+		statuses = append(statuses, job.Wait())
+	}
+	if len(statuses) != 3 || statuses[2] != 3 {
+		t.Errorf("job statuses = %v, expected exit status 3 for the last job", statuses)
+	}
+
+	if value, exists := executor.environment.Get("X"); exists {
+		t.Errorf("X = %q, assignment in background job should not be visible", value)
+	}
+	if current := executor.environment.Dir(); current != dir {
+		t.Errorf("working directory = %q, expected %q", current, dir)
+	}
+}
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+
+	"gocli/internal/builtins"
+	"gocli/internal/glob"
+	"gocli/internal/parser"
+)
+
+// conditionalName - имя конструкции [[ ]] в сообщениях об ошибках.
+const conditionalName = "[["
+
+// rematchVariable - массив с результатом последнего сопоставления =~:
+// элемент 0 - совпавшая часть строки, элементы 1, 2, ... - подвыражения в скобках.
+const rematchVariable = "BASH_REMATCH"
+
+// statusConditionalError - код возврата [[ ]] при ошибке в выражении, как у test.
+const statusConditionalError = 2
+
+// executeConditional вычисляет условное выражение [[ expr ]]: код возврата 0,
+// если оно истинно, 1, если ложно, и 2 при ошибке (например, в регулярном выражении).
+func (exec *Executor) executeConditional(node *parser.Conditional) (int, error) {
+	result, err := exec.evaluateConditional(node.Expr)
+	if err != nil {
+		return statusConditionalError, fmt.Errorf("%s: %w", conditionalName, err)
+	}
+	if !result {
+		return statusFailure, &ExitStatusError{Name: conditionalName, Code: statusFailure}
+	}
+	return 0, nil
+}
+
+// evaluateConditional вычисляет выражение [[ ]]. Операнды раскрываются без разбиения
+// на поля и шаблонов имен файлов; правое выражение && и || вычисляется, только если
+// от него зависит результат.
+func (exec *Executor) evaluateConditional(expr parser.CondExpr) (bool, error) {
+	switch e := expr.(type) {
+	case *parser.CondWord:
+		value, err := exec.expandWord(e.Word)
+		return value != "", err
+	case *parser.CondUnary:
+		operand, err := exec.expandWord(e.Operand)
+		if err != nil {
+			return false, err
+		}
+		return builtins.TestUnary(exec.environment.Dir(), e.Op, operand)
+	case *parser.CondBinary:
+		return exec.evaluateConditionalBinary(e)
+	case *parser.CondNot:
+		result, err := exec.evaluateConditional(e.Expr)
+		return !result, err
+	case *parser.CondGroup:
+		return exec.evaluateConditional(e.Expr)
+	case *parser.CondAndOr:
+		left, err := exec.evaluateConditional(e.Left)
+		if err != nil || left == (e.Op == parser.OrOperator) {
+			return left, err
+		}
+		return exec.evaluateConditional(e.Right)
+	default:
+		return false, fmt.Errorf("unknown conditional expression: %T", expr)
+	}
+}
+
+// evaluateConditionalBinary вычисляет бинарную проверку [[ ]]. Правый операнд == и !=
+// - шаблон (как в case), =~ - регулярное выражение POSIX ERE; остальные операторы
+// вычисляются, как в команде test.
+func (exec *Executor) evaluateConditionalBinary(e *parser.CondBinary) (bool, error) {
+	left, err := exec.expandWord(e.Left)
+	if err != nil {
+		return false, err
+	}
+
+	switch e.Op {
+	case "==", "=", "!=":
+		pattern, err := exec.expandPattern(e.Right)
+		if err != nil {
+			return false, err
+		}
+		return glob.Match(pattern, left) == (e.Op != "!="), nil
+	case "=~":
+		return exec.matchRegex(left, e.Right)
+	default:
+		right, err := exec.expandWord(e.Right)
+		if err != nil {
+			return false, err
+		}
+		return builtins.TestBinary(exec.environment.Dir(), e.Op, left, right)
+	}
+}
+
+// matchRegex сопоставляет строку с регулярным выражением и сохраняет совпадение
+// и подвыражения в массив BASH_REMATCH (при несовпадении массив удаляется).
+// Части выражения в кавычках сопоставляются буквально.
+func (exec *Executor) matchRegex(value string, word *parser.Argument) (bool, error) {
+	pattern := word.Value
+	if exec.expander != nil {
+		expanded, err := exec.expander.ExpandRegex(word)
+		if err != nil {
+			return false, exec.expansionError(err)
+		}
+		pattern = expanded
+	}
+
+	re, err := regexp.CompilePOSIX(pattern)
+	if err != nil {
+		return false, fmt.Errorf("%s: invalid regular expression: %w", pattern, err)
+	}
+
+	match := re.FindStringSubmatch(value)
+	exec.environment.SetArray(rematchVariable, match)
+	return match != nil, nil
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"gocli/internal/builtins"
+	"gocli/internal/jobs"
+)
+
+// execContext описывает контекст выполнения узла AST:
+// потоки ввода/вывода, фоновое задание, к которому относится выполнение,
+// число охватывающих циклов (для break и continue), глубина вызовов функций
+// и выполняемых командой source файлов (для return).
+type execContext struct {
+	stdio     *builtins.IO // Потоки ввода/вывода узла
+	job       *jobs.Job    // Фоновое задание (nil при выполнении на переднем плане)
+	loops     int          // Число циклов, внутри которых выполняется узел
+	functions int          // Число вызванных функций, внутри которых выполняется узел
+	sources   int          // Число файлов source, внутри которых выполняется узел
+}
+
+// newContext создает контекст выполнения на переднем плане с потоками stdio.
+func newContext(stdio *builtins.IO) *execContext {
+	return &execContext{stdio: stdio}
+}
+
+// withIO возвращает копию контекста с другими потоками ввода/вывода.
+func (ctx *execContext) withIO(stdio *builtins.IO) *execContext {
+	copied := *ctx
+	copied.stdio = stdio
+	return &copied
+}
+
+// inLoop возвращает копию контекста для тела цикла.
+func (ctx *execContext) inLoop() *execContext {
+	copied := *ctx
+	copied.loops++
+	return &copied
+}
+
+// inFunction возвращает копию контекста для тела вызываемой функции.
+// Циклы вызывающего кода не охватывают тело: break и continue в функции на них не действуют.
+func (ctx *execContext) inFunction() *execContext {
+	copied := *ctx
+	copied.loops = 0
+	copied.functions++
+	return &copied
+}
+
+// inSource возвращает копию контекста для команд файла, выполняемого командой source.
+// Как и для функций, циклы вызывающего кода не охватывают команды файла.
+func (ctx *execContext) inSource() *execContext {
+	copied := *ctx
+	copied.loops = 0
+	copied.sources++
+	return &copied
+}
@@ -5,40 +5,288 @@ import (
 	"io"
 	"os"
 	osexec "os/exec"
+	"strconv"
+	"sync/atomic"
 
 	"gocli/internal/builtins"
 	"gocli/internal/environment"
+	"gocli/internal/expander"
+	"gocli/internal/glob"
+	"gocli/internal/jobs"
 	"gocli/internal/parser"
 )
 
 // Executor выполняет команды, представленные в виде AST.
 // Различает встроенные команды и внешние программы, управляет их выполнением.
 type Executor struct {
-	registry    *builtins.Registry       // Реестр встроенных команд
-	environment *environment.Environment // Управление переменными окружения
+	registry    *builtins.Registry        // Реестр встроенных команд
+	environment *environment.Environment  // Управление переменными окружения
+	expander    *expander.Expander        // Подстановки, выполняемые перед запуском каждой команды
+	jobs        *jobs.Table               // Таблица фоновых заданий
+	specials    map[string]specialBuiltin // Встроенные команды, управляющие самим исполнителем
+	lastStatus  int                       // Код возврата последнего выполненного узла
+	interactive bool                      // Интерактивный режим: сообщать о запуске фоновых заданий
+	formatError ErrorFormatter            // Формат выводимых исполнителем ошибок (nil - "Error: ...")
+
+	functionDepth atomic.Int32 // Число выполняющихся вызовов функций (для ограничения рекурсии)
 }
 
+// specialBuiltin выполняет встроенную команду, которой нужен контекст выполнения
+// (например, break и continue прерывают охватывающие циклы, а return - функцию).
+type specialBuiltin func(name string, args []string, ctx *execContext) (int, error)
+
 // NewExecutor создает новый экземпляр исполнителя.
-// Инициализирует реестр встроенных команд и возвращает готовую структуру.
+// Инициализирует реестр встроенных команд и таблицу фоновых заданий,
+// регистрирует команды управления заданиями и возвращает готовую структуру.
 func NewExecutor() *Executor {
-	return &Executor{
+	exec := &Executor{
 		registry:    builtins.NewRegistry(),
 		environment: environment.NewEnvironment(),
+		jobs:        jobs.NewTable(),
 	}
+
+	exec.registry.Register(builtins.NewJobsCommand(exec.jobs))
+	exec.registry.Register(builtins.NewFgCommand(exec.jobs))
+	exec.registry.Register(builtins.NewBgCommand(exec.jobs))
+	exec.registry.Register(builtins.NewWaitCommand(exec.jobs))
+	exec.registry.Register(builtins.NewDisownCommand(exec.jobs))
+	exec.registerEnvironmentBuiltins()
+	exec.registerSpecialBuiltins()
+
+	return exec
 }
 
-// Execute выполняет узел AST (команду или пайплайн).
+// registerSpecialBuiltins регистрирует встроенные команды, управляющие самим исполнителем.
+func (exec *Executor) registerSpecialBuiltins() {
+	exec.specials = map[string]specialBuiltin{
+		breakCommandName:         exec.executeLoopControl,
+		continueCommandName:      exec.executeLoopControl,
+		returnCommandName:        exec.executeReturn,
+		builtins.ExitCommandName: exec.executeExit,
+		sourceCommandName:        exec.executeSource,
+		dotCommandName:           exec.executeSource,
+	}
+}
+
+// registerEnvironmentBuiltins регистрирует встроенные команды, работающие с окружением исполнителя.
+// Вызывается повторно при замене окружения, чтобы команды использовали новое окружение.
+func (exec *Executor) registerEnvironmentBuiltins() {
+	exec.registry.Register(builtins.NewCdCommand(exec.environment))
+	exec.registry.Register(builtins.NewShoptCommand(exec.environment))
+	exec.registry.Register(builtins.NewDeclareCommand(exec.environment))
+	exec.registry.Register(builtins.NewLocalCommand(exec.environment))
+	exec.registry.Register(builtins.NewUnsetCommand(exec.environment))
+}
+
+// Execute выполняет узел AST (команду, пайплайн или список команд).
 // Определяет тип узла и вызывает соответствующий метод выполнения.
+// Код возврата сохраняется и доступен через LastStatus.
 // Возвращает ошибку при неудачном выполнении команды.
 func (exec *Executor) Execute(node parser.Node) error {
+	status, err := exec.executeNode(node, newContext(builtins.NewIO()))
+	exec.lastStatus = status
+	return err
+}
+
+// LastStatus возвращает код возврата последнего узла, выполненного через Execute.
+func (exec *Executor) LastStatus() int {
+	return exec.lastStatus
+}
+
+// executeNode выполняет узел AST с заданными потоками ввода/вывода.
+// После выполнения код возврата сохраняется в специальный параметр $?.
+// Возвращает код возврата узла и ошибку выполнения.
+func (exec *Executor) executeNode(node parser.Node, ctx *execContext) (int, error) {
+	var status int
+	var err error
+
 	switch n := node.(type) {
 	case *parser.Command:
-		return exec.executeCommand(n)
+		status, err = exec.executeCommand(n, ctx)
 	case *parser.Pipeline:
-		return exec.executePipeline(n)
+		status, err = exec.executePipeline(n, ctx)
+	case *parser.AndOr:
+		status, err = exec.executeAndOr(n, ctx)
+	case *parser.List:
+		status, err = exec.executeList(n, ctx)
+	case *parser.Program:
+		status, err = exec.executeList(n.Body, ctx)
+	case *parser.If:
+		status, err = exec.executeIf(n, ctx)
+	case *parser.For:
+		status, err = exec.executeFor(n, ctx)
+	case *parser.ArithFor:
+		status, err = exec.executeArithFor(n, ctx)
+	case *parser.While:
+		status, err = exec.executeWhile(n, ctx)
+	case *parser.Case:
+		status, err = exec.executeCase(n, ctx)
+	case *parser.Group:
+		status, err = exec.executeNode(n.Body, ctx)
+	case *parser.Subshell:
+		status, err = exec.executeSubshell(n, ctx)
+	case *parser.Function:
+		status, err = exec.executeFunctionDefinition(n)
+	case *parser.Conditional:
+		status, err = exec.executeConditional(n)
 	default:
-		return fmt.Errorf("unknown node type: %T", node)
+		return statusFailure, fmt.Errorf("unknown node type: %T", node)
+	}
+
+	exec.setStatus(status, ctx)
+	return status, err
+}
+
+// setStatus сохраняет код возврата в специальный параметр $?.
+// Команды фоновых заданий не изменяют $? основного shell'а.
+func (exec *Executor) setStatus(status int, ctx *execContext) {
+	if ctx.job != nil {
+		return
+	}
+	exec.environment.SetSpecial("?", strconv.Itoa(status))
+}
+
+// executeList последовательно выполняет элементы списка, разделенные ; и &.
+// Элементы с оператором & запускаются в фоне (см. startBackground).
+// Код возврата списка равен коду возврата последнего элемента.
+// Ошибки промежуточных элементов выводятся в stderr и не прерывают выполнение списка;
+// сигнал break, continue или return прерывает список и передается охватывающему циклу или функции.
+func (exec *Executor) executeList(list *parser.List, ctx *execContext) (int, error) {
+	status := 0
+	var err error
+
+	for i, item := range list.Items {
+		if isControlFlow(err) {
+			break
+		}
+		if i > 0 {
+			exec.reportError(err, ctx.stdio)
+		}
+		if item.Background {
+			status, err = exec.startBackground(item, ctx)
+			exec.setStatus(status, ctx)
+			continue
+		}
+		status, err = exec.executeAndOr(item, ctx)
+	}
+
+	return status, err
+}
+
+// executeAndOr выполняет цепочку пайплайнов, соединенных && и ||, с сокращенным вычислением.
+// Пайплайн после && выполняется, только если код возврата предыдущего равен 0,
+// пайплайн после || - только если код возврата ненулевой. Пропущенный пайплайн
+// сохраняет код возврата предыдущего, как в POSIX shell.
+func (exec *Executor) executeAndOr(andOr *parser.AndOr, ctx *execContext) (int, error) {
+	status, err := exec.executeNode(andOr.Pipelines[0], ctx)
+
+	for i, op := range andOr.Operators {
+		if isControlFlow(err) {
+			break
+		}
+		if (op == parser.AndOperator) != (status == 0) {
+			continue
+		}
+		exec.reportError(err, ctx.stdio)
+		status, err = exec.executeNode(andOr.Pipelines[i+1], ctx)
+	}
+
+	return status, err
+}
+
+// executeIf выполняет условную конструкцию: списки условий ветвей выполняются по порядку,
+// и выполняется тело первой ветви, условие которой завершилось с кодом 0.
+// Ненулевой код условия не считается ошибкой; прочие ошибки условия выводятся в stderr.
+// Если ни одна ветвь не выбрана и ветви else нет, код возврата равен 0.
+func (exec *Executor) executeIf(node *parser.If, ctx *execContext) (int, error) {
+	for _, clause := range node.Clauses {
+		status, err := exec.executeNode(clause.Condition, ctx)
+		if isControlFlow(err) {
+			return status, err
+		}
+		exec.reportError(err, ctx.stdio)
+		if status == 0 {
+			return exec.executeNode(clause.Body, ctx)
+		}
+	}
+
+	if node.Else != nil {
+		return exec.executeNode(node.Else, ctx)
+	}
+	return 0, nil
+}
+
+// executeCase выполняет конструкцию case: слово после подстановок сравнивается
+// с шаблонами ветвей по порядку, и выполняется тело первой подходящей ветви.
+// После ;; выполнение завершается, после ;& выполняется тело следующей ветви
+// без проверки шаблонов, после ;;& проверка шаблонов продолжается со следующей ветви.
+// Код возврата - код последнего выполненного тела, 0, если ни одна ветвь не подошла.
+func (exec *Executor) executeCase(node *parser.Case, ctx *execContext) (int, error) {
+	word, err := exec.expandWord(node.Word)
+	if err != nil {
+		return statusFailure, err
+	}
+
+	status := 0
+	fallThrough := false
+	for _, item := range node.Items {
+		if !fallThrough {
+			matched, err := exec.matchCaseItem(word, item.Patterns)
+			if err != nil {
+				return statusFailure, err
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		exec.reportError(err, ctx.stdio)
+		status, err = exec.executeNode(item.Body, ctx)
+		if item.Terminator == parser.CaseBreak || isControlFlow(err) {
+			return status, err
+		}
+		fallThrough = item.Terminator == parser.CaseFallThrough
+	}
+	return status, err
+}
+
+// expandWord раскрывает слово без разбиения на поля: слово конструкции case или операнд [[ ]].
+func (exec *Executor) expandWord(word *parser.Argument) (string, error) {
+	if exec.expander == nil {
+		return word.Value, nil
+	}
+	value, err := exec.expander.ExpandWord(word)
+	if err != nil {
+		return "", exec.expansionError(err)
+	}
+	return value, nil
+}
+
+// matchCaseItem проверяет, подходит ли слово хотя бы под один шаблон ветви.
+// Шаблоны раскрываются по очереди, до первого совпадения.
+func (exec *Executor) matchCaseItem(word string, patterns []*parser.Argument) (bool, error) {
+	for _, pattern := range patterns {
+		value, err := exec.expandPattern(pattern)
+		if err != nil {
+			return false, err
+		}
+		if glob.Match(value, word) {
+			return true, nil
+		}
 	}
+	return false, nil
+}
+
+// expandPattern раскрывает шаблон ветви case или правый операнд == в [[ ]] для glob.Match.
+func (exec *Executor) expandPattern(pattern *parser.Argument) (string, error) {
+	if exec.expander == nil {
+		return pattern.Value, nil
+	}
+	value, err := exec.expander.ExpandPattern(pattern)
+	if err != nil {
+		return "", exec.expansionError(err)
+	}
+	return value, nil
 }
 
 // varState хранит состояние переменной перед её изменением.
@@ -102,36 +350,59 @@ func (exec *Executor) restoreVariables(savedVars map[string]varState) {
 }
 
 // executeCommand выполняет отдельную команду.
-// Устанавливает переменные окружения, определяет тип команды (встроенная/внешняя)
-// и вызывает соответствующий метод выполнения.
+// Выполняет подстановки, устанавливает переменные окружения, определяет тип команды
+// (функция, встроенная или внешняя) и вызывает соответствующий метод выполнения.
 // Временные переменные из assignments автоматически восстанавливаются после выполнения,
 // если команда выполняется. Если команда состоит только из assignments, переменные сохраняются.
-func (exec *Executor) executeCommand(cmd *parser.Command) error {
+func (exec *Executor) executeCommand(cmd *parser.Command, ctx *execContext) (int, error) {
+	cmd, err := exec.expandCommand(cmd)
+	if err != nil {
+		return statusFailure, err
+	}
+
 	// Если команда состоит только из assignments (например, x=5),
 	// сохраняем переменные навсегда, не восстанавливаем их
-	if cmd.Name == "" {
+	if cmd.Name == "" && cmd.Compound == nil {
 		for _, assignment := range cmd.Assignments {
 			exec.environment.Set(assignment.Name, assignment.Value.Value)
 		}
-		return nil
+		status, err := exec.executeRedirectsOnly(cmd.Redirects, ctx)
+		if err != nil {
+			return status, err
+		}
+		// Код возврата такой команды - код последней подстановки команды в ней (x=$(false))
+		return exec.substitutionStatus()
 	}
 
-	// Сохраняем и устанавливаем переменные окружения
-	savedVars := exec.saveAndSetVariables(cmd.Assignments)
-
-	// Восстанавливаем переменные после выполнения команды
-	defer exec.restoreVariables(savedVars)
+	return exec.executeCommandWithIO(cmd, ctx)
+}
 
-	args := make([]string, len(cmd.Args))
-	for i, arg := range cmd.Args {
-		args[i] = arg.Value
+// substitutionStatus возвращает код возврата последней подстановки команды
+// в последней раскрытой команде (0, если подстановок не было).
+func (exec *Executor) substitutionStatus() (int, error) {
+	if exec.expander == nil {
+		return 0, nil
+	}
+	status, substituted := exec.expander.SubstitutionStatus()
+	if !substituted || status == 0 {
+		return 0, nil
 	}
+	return status, &ExitStatusError{Name: substitutionName, Code: status}
+}
 
-	if builtin, exists := exec.registry.Get(cmd.Name); exists {
-		return exec.executeBuiltin(builtin, args)
+// expandCommand выполняет подстановки в команде непосредственно перед её запуском,
+// чтобы учитывались переменные, установленные предыдущими командами списка.
+// Если expander не задан, команда возвращается без изменений.
+func (exec *Executor) expandCommand(cmd *parser.Command) (*parser.Command, error) {
+	if exec.expander == nil {
+		return cmd, nil
 	}
 
-	return exec.executeExternal(cmd.Name, args)
+	expanded, err := exec.expander.ExpandCommand(cmd)
+	if err != nil {
+		return nil, exec.expansionError(err)
+	}
+	return expanded, nil
 }
 
 // executePipeline выполняет пайплайн команд.
@@ -142,29 +413,35 @@ func (exec *Executor) executeCommand(cmd *parser.Command) error {
 // Например, в пайплайне `echo hello | wc` команда `wc` должна начать читать данные
 // сразу после того, как `echo` начнет их писать, иначе pipe заблокируется.
 //
+// Каждая команда пайплайна из нескольких команд выполняется в своей подоболочке
+// (см. runSubshell), как в bash: подстановки, присваивания, cd и определения функций
+// в ней не видны снаружи, а break, continue, return и exit завершают только ее.
+//
 // Возвращается код последней команды (как в POSIX shell).
 // Ошибки промежуточных команд не прерывают пайплайн, но сохраняются для диагностики.
-func (exec *Executor) executePipeline(pipeline *parser.Pipeline) error {
+func (exec *Executor) executePipeline(pipeline *parser.Pipeline, ctx *execContext) (int, error) {
 	if len(pipeline.Commands) == 0 {
-		return fmt.Errorf("empty pipeline")
+		return statusFailure, fmt.Errorf("empty pipeline")
 	}
 
 	// Если команда одна, выполняем её без пайплайна
 	if len(pipeline.Commands) == 1 {
-		return exec.executeCommand(pipeline.Commands[0])
+		return exec.executeCommand(pipeline.Commands[0], ctx)
 	}
 
+	commands := pipeline.Commands
+
 	// Создаем pipes между командами
 	// Для N команд нужно N-1 pipe: между каждой парой соседних команд
-	pipes := make([]*io.PipeWriter, len(pipeline.Commands)-1)
-	readers := make([]io.Reader, len(pipeline.Commands))
+	pipes := make([]*io.PipeWriter, len(commands)-1)
+	readers := make([]io.Reader, len(commands))
 
-	// Первая команда читает из os.Stdin
-	readers[0] = os.Stdin
+	// Первая команда читает из стандартного ввода пайплайна
+	readers[0] = ctx.stdio.Stdin
 
 	// Создаем pipes для промежуточных команд
 	// Каждый pipe соединяет stdout команды i с stdin команды i+1
-	for i := 0; i < len(pipeline.Commands)-1; i++ {
+	for i := 0; i < len(commands)-1; i++ {
 		r, w := io.Pipe()
 		pipes[i] = w
 		readers[i+1] = r
@@ -172,216 +449,246 @@ func (exec *Executor) executePipeline(pipeline *parser.Pipeline) error {
 
 	// Запускаем все команды параллельно в goroutines
 	// Это критично для работы pipe - команды должны работать одновременно
-	errors := make(chan error, len(pipeline.Commands))
+	type commandResult struct {
+		index  int
+		status int
+		err    error
+	}
+	results := make(chan commandResult, len(commands))
 
-	for i, cmd := range pipeline.Commands {
+	for i, cmd := range commands {
 		i := i // Захватываем переменную для замыкания в goroutine
 		cmd := cmd
 
 		go func() {
 			// Определяем stdout для команды
-			// Промежуточные команды пишут в pipe, последняя - в os.Stdout
-			var stdout io.Writer = os.Stdout
-			if i < len(pipeline.Commands)-1 {
+			// Промежуточные команды пишут в pipe, последняя - в stdout пайплайна
+			stdout := ctx.stdio.Stdout
+			if i < len(commands)-1 {
 				stdout = pipes[i]
 			}
 
-			// Выполняем команду с правильными потоками ввода/вывода
-			err := exec.executeCommandInPipeline(cmd, readers[i], stdout, os.Stderr)
+			// Выполняем команду в подоболочке с правильными потоками ввода/вывода
+			stageCtx := ctx.withIO(&builtins.IO{
+				Stdin:  readers[i],
+				Stdout: stdout,
+				Stderr: ctx.stdio.Stderr,
+			})
+			status, err := exec.runSubshell(func(child *Executor) (int, error) {
+				return child.executeCommand(cmd, stageCtx)
+			})
 
 			// Закрываем pipe после записи (если это не последняя команда)
 			// Это сигнализирует следующей команде, что данных больше не будет
-			if i < len(pipeline.Commands)-1 {
+			if i < len(commands)-1 {
 				pipes[i].Close()
 			}
 
-			// Отправляем результат в канал ошибок
+			// Закрываем читающий конец pipe: если команда завершилась, не дочитав ввод,
+			// предыдущая команда получит ошибку записи вместо бесконечной блокировки
+			if i > 0 {
+				readers[i].(*io.PipeReader).Close()
+			}
+
 			if err != nil {
-				errors <- fmt.Errorf("command %d (%s) failed: %w", i, cmd.Name, err)
-			} else {
-				errors <- nil
+				err = fmt.Errorf("command %d (%s) failed: %w", i, cmd.Name, err)
 			}
+			results <- commandResult{index: i, status: status, err: err}
 		}()
 	}
 
-	// Ждем завершения всех команд и собираем ошибки
+	// Ждем завершения всех команд
 	// В POSIX shell код возврата пайплайна равен коду последней команды
+	status := 0
 	var lastErr error
-	for i := 0; i < len(pipeline.Commands); i++ {
-		if err := <-errors; err != nil {
-			// Сохраняем ошибку последней команды - она определяет код возврата пайплайна
-			if i == len(pipeline.Commands)-1 {
-				lastErr = err
-			}
-			// Ошибки промежуточных команд не прерывают пайплайн
-			// (в bash/zsh промежуточные ошибки игнорируются, если не установлен set -e)
+	for range commands {
+		result := <-results
+		if result.index == len(commands)-1 {
+			status = result.status
+			lastErr = result.err
 		}
+		// Ошибки промежуточных команд не прерывают пайплайн
+		// (в bash/zsh промежуточные ошибки игнорируются, если не установлен set -e)
 	}
 
-	return lastErr
+	return status, lastErr
 }
 
-// executeCommandInPipeline выполняет команду в контексте пайплайна.
-// stdin, stdout, stderr определяют потоки ввода/вывода для команды.
+// executeCommandWithIO выполняет команду с заданными потоками ввода/вывода.
+// Используется как для одиночных команд, так и для команд в пайплайне.
+// Перед запуском применяет перенаправления команды к потокам stdio.
 // Временные переменные из assignments автоматически восстанавливаются после выполнения.
-func (exec *Executor) executeCommandInPipeline(
-	cmd *parser.Command,
-	stdin io.Reader,
-	stdout, stderr io.Writer,
-) error {
+func (exec *Executor) executeCommandWithIO(cmd *parser.Command, ctx *execContext) (int, error) {
 	// Сохраняем и устанавливаем переменные окружения
 	savedVars := exec.saveAndSetVariables(cmd.Assignments)
 
 	// Восстанавливаем переменные после выполнения команды
 	defer exec.restoreVariables(savedVars)
 
+	if cmd.Name == "" && cmd.Compound == nil {
+		return exec.executeRedirectsOnly(cmd.Redirects, ctx)
+	}
+
+	stdio, closeFiles, err := exec.applyRedirects(cmd.Redirects, ctx.stdio)
+	if err != nil {
+		return statusFailure, err
+	}
+	defer closeFiles()
+	ctx = ctx.withIO(stdio)
+
+	// Составная команда выполняется целиком с перенаправленными потоками
+	if cmd.Compound != nil {
+		return exec.executeNode(cmd.Compound, ctx)
+	}
+
 	args := make([]string, len(cmd.Args))
 	for j, arg := range cmd.Args {
 		args[j] = arg.Value
 	}
 
 	// Выполняем команду
+	if special, exists := exec.specials[cmd.Name]; exists {
+		return special(cmd.Name, args, ctx)
+	}
+	if fn, exists := exec.environment.Function(cmd.Name); exists {
+		return exec.executeFunction(fn, args, ctx)
+	}
 	if builtin, exists := exec.registry.Get(cmd.Name); exists {
-		return exec.executeBuiltinInPipeline(builtin, args, stdin, stdout, stderr)
+		return exec.executeBuiltin(builtin, args, ctx)
 	}
 
-	return exec.executeExternalInPipeline(cmd.Name, args, stdin, stdout, stderr)
+	return exec.executeExternal(cmd.Name, args, ctx)
 }
 
-// executeBuiltinInPipeline выполняет встроенную команду в контексте пайплайна.
-func (exec *Executor) executeBuiltinInPipeline(
-	builtin builtins.Builtin,
-	args []string,
-	stdin io.Reader,
-	stdout, stderr io.Writer,
-) error {
-	env := exec.environment.GetAllMap()
+// executeRedirectsOnly обрабатывает команду без имени, состоящую только из перенаправлений
+// (например, "> file"). Файлы открываются (и при необходимости создаются или усекаются)
+// и сразу закрываются, как в POSIX shell.
+func (exec *Executor) executeRedirectsOnly(redirects []*parser.Redirect, ctx *execContext) (int, error) {
+	if len(redirects) == 0 {
+		return 0, nil
+	}
 
-	// Создаем IO структуру с переданными потоками
-	io := &builtins.IO{
-		Stdin:  stdin,
-		Stdout: stdout,
-		Stderr: stderr,
+	_, closeFiles, err := exec.applyRedirects(redirects, ctx.stdio)
+	if err != nil {
+		return statusFailure, err
 	}
+	closeFiles()
+	return 0, nil
+}
 
-	exitCode := builtin.Execute(args, env, io.Stdin, io.Stdout, io.Stderr)
+// executeBuiltin выполняет встроенную команду с заданными потоками ввода/вывода.
+// Передает переменные окружения во встроенную команду.
+// Возвращает код возврата и ошибку, если команда завершилась с ненулевым кодом.
+//
+// Специальная обработка для grep: код возврата 1 означает "не найдено совпадений",
+// что не является ошибкой, поэтому для grep код 1 не возвращается как ошибка.
+func (exec *Executor) executeBuiltin(builtin builtins.Builtin, args []string, ctx *execContext) (int, error) {
+	// Относительные пути встроенные команды отсчитывают от рабочего каталога исполнителя
+	env := exec.environment.GetAllMap()
+	if dir := exec.environment.Dir(); dir != "" {
+		env[builtins.PwdVariable] = dir
+	}
 
-	// Команда exit в пайплайне завершает весь процесс
-	// Это стандартное поведение для большинства shell
+	exitCode := builtin.Execute(args, env, ctx.stdio.Stdin, ctx.stdio.Stdout, ctx.stdio.Stderr)
 
 	// Специальная обработка для grep: код 1 = не найдено (не ошибка)
 	if builtin.Name() == builtins.GrepCommandName && exitCode == 1 {
-		// Код 1 для grep означает "не найдено совпадений" - это нормальное состояние, не ошибка
-		return nil
+		// Код 1 для grep означает "не найдено совпадений" - это нормальное состояние, не ошибка,
+		// но код возврата сохраняется для && и ||
+		return exitCode, nil
 	}
 
 	if exitCode != 0 {
-		return fmt.Errorf("command %s exited with code %d", builtin.Name(), exitCode)
+		return exitCode, &ExitStatusError{Name: builtin.Name(), Code: exitCode}
 	}
 
-	return nil
+	return 0, nil
 }
 
-// executeExternalInPipeline выполняет внешнюю программу в контексте пайплайна.
-func (exec *Executor) executeExternalInPipeline(
-	name string,
-	args []string,
-	stdin io.Reader,
-	stdout, stderr io.Writer,
-) error {
+// executeExternal выполняет внешнюю программу с заданными потоками ввода/вывода.
+// Использует os/exec для запуска внешней команды с переданными аргументами.
+// Передает переменные окружения и рабочий каталог исполнителя; незаданные потоки
+// заменяются стандартными.
+// Процесс внешней программы в фоновом задании регистрируется в задании.
+// Возвращает код возврата программы (127, если программа не найдена).
+func (exec *Executor) executeExternal(name string, args []string, ctx *execContext) (int, error) {
 	cmd := osexec.Command(name, args...)
 
 	// Устанавливаем потоки
-	if stdin != nil {
-		cmd.Stdin = stdin
+	if ctx.stdio.Stdin != nil {
+		cmd.Stdin = ctx.stdio.Stdin
 	} else {
 		cmd.Stdin = os.Stdin
 	}
 
-	if stdout != nil {
-		cmd.Stdout = stdout
+	if ctx.stdio.Stdout != nil {
+		cmd.Stdout = ctx.stdio.Stdout
 	} else {
 		cmd.Stdout = os.Stdout
 	}
 
-	if stderr != nil {
-		cmd.Stderr = stderr
+	if ctx.stdio.Stderr != nil {
+		cmd.Stderr = ctx.stdio.Stderr
 	} else {
 		cmd.Stderr = os.Stderr
 	}
 
 	cmd.Env = exec.environment.GetAll()
+	cmd.Dir = exec.environment.Dir()
 
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("external command failed: %w", err)
+	if err := cmd.Start(); err != nil {
+		return exitStatus(err), fmt.Errorf("external command failed: %w", err)
 	}
-
-	return nil
-}
-
-// executeBuiltin выполняет встроенную команду.
-// Создает IO структуру и вызывает метод Execute встроенной команды.
-// Передает переменные окружения во встроенную команду.
-// Возвращает ошибку, если команда завершилась с ненулевым кодом возврата.
-//
-// Примечание: команда exit вызывает os.Exit(), который завершает процесс,
-// поэтому код после вызова Execute для exit никогда не выполняется.
-//
-// Специальная обработка для grep: код возврата 1 означает "не найдено совпадений",
-// что не является ошибкой, поэтому для grep код 1 не возвращается как ошибка.
-func (exec *Executor) executeBuiltin(builtin builtins.Builtin, args []string) error {
-	io := builtins.NewIO()
-	env := exec.environment.GetAllMap()
-
-	exitCode := builtin.Execute(args, env, io.Stdin, io.Stdout, io.Stderr)
-
-	// Если команда exit была вызвана, os.Exit() уже завершил процесс,
-	// поэтому этот код не выполнится для exit
-
-	// Специальная обработка для grep: код 1 = не найдено (не ошибка)
-	if builtin.Name() == builtins.GrepCommandName && exitCode == 1 {
-		// Код 1 для grep означает "не найдено совпадений" - это нормальное состояние, не ошибка
-		return nil
+	if ctx.job != nil {
+		ctx.job.AddProcess(cmd.Process)
 	}
 
-	if exitCode != 0 {
-		return fmt.Errorf("command %s exited with code %d", builtin.Name(), exitCode)
+	if err := cmd.Wait(); err != nil {
+		return exitStatus(err), fmt.Errorf("external command failed: %w", err)
 	}
 
-	return nil
+	return 0, nil
 }
 
-// executeExternal выполняет внешнюю программу.
-// Использует os/exec для запуска внешней команды с переданными аргументами.
-// Передает переменные окружения и подключает стандартные потоки ввода/вывода.
-func (exec *Executor) executeExternal(name string, args []string) error {
-	cmd := osexec.Command(name, args...)
-
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	cmd.Env = exec.environment.GetAll()
+func (exec *Executor) IsBuiltin(name string) bool {
+	_, special := exec.specials[name]
+	return special || exec.registry.IsBuiltin(name)
+}
 
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("external command failed: %w", err)
+func (exec *Executor) ListBuiltins() []string {
+	names := exec.registry.List()
+	for name := range exec.specials {
+		// exit есть и в реестре (код вычисляет builtins.ExitCommand), но выполняется исполнителем
+		if !exec.registry.IsBuiltin(name) {
+			names = append(names, name)
+		}
 	}
+	return names
+}
 
-	return nil
+// SetInteractive включает или выключает интерактивный режим. В интерактивном режиме,
+// как в bash, о запуске фонового задания сообщается строкой "[N] PID" в stderr;
+// при выполнении скрипта такие сообщения не выводятся.
+func (exec *Executor) SetInteractive(interactive bool) {
+	exec.interactive = interactive
 }
 
-func (exec *Executor) IsBuiltin(name string) bool {
-	return exec.registry.IsBuiltin(name)
+// Jobs возвращает таблицу фоновых заданий исполнителя.
+func (exec *Executor) Jobs() *jobs.Table {
+	return exec.jobs
 }
 
-func (exec *Executor) ListBuiltins() []string {
-	return exec.registry.List()
+// SetExpander устанавливает expander, выполняющий подстановки перед запуском каждой команды.
+// Подстановки команд $(...) и `...` expander выполняет этим исполнителем в подоболочке.
+// Без expander команды выполняются в том виде, в котором переданы в Execute.
+func (exec *Executor) SetExpander(exp *expander.Expander) {
+	exec.expander = exp
+	exp.SetCommandRunner(exec.runSubstitution)
 }
 
 // SetEnvironment устанавливает окружение для исполнителя.
 // Позволяет использовать общее окружение между Shell и Executor.
 func (exec *Executor) SetEnvironment(env *environment.Environment) {
 	exec.environment = env
+	exec.registerEnvironmentBuiltins()
 }
@@ -1,8 +1,16 @@
 package executor
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"gocli/internal/builtins"
 	"gocli/internal/parser"
 )
 
@@ -19,6 +27,11 @@ func TestExecutor_IsBuiltin(t *testing.T) {
 		{"builtin command", "echo", true},
 		{"builtin command", "cat", true},
 		{"builtin command", "grep", true},
+		{"loop control command", "break", true},
+		{"function control command", "return", true},
+		{"variable command", "local", true},
+		{"test command", "test", true},
+		{"bracket command", "[", true},
 		{"non-builtin command", "ping", false},
 		{"empty command", "", false},
 	}
@@ -39,7 +52,7 @@ func TestExecutor_ListBuiltins(t *testing.T) {
 	executor := NewExecutor()
 	commands := executor.ListBuiltins()
 
-	expectedCount := 8 // cat, echo, wc, pwd, exit, grep
+	expectedCount := 24 // cat, echo, wc, pwd, exit, grep, cd, ls, test, [, jobs, fg, bg, wait, disown, shopt, declare, local, unset, break, continue, return, source, .
 	if len(commands) != expectedCount {
 		t.Errorf("Executor.ListBuiltins() returned %d commands, expected %d", len(commands), expectedCount)
 	}
@@ -411,3 +424,832 @@ func TestExecutor_ExecutePipelineWithGrep(t *testing.T) {
 		})
 	}
 }
+
+// TestExecutor_ExecuteAndOr тестирует сокращенное вычисление операторов && и ||.
+// Проверяет, какие пайплайны выполняются, по побочному эффекту - созданным файлам.
+func TestExecutor_ExecuteAndOr(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.txt")
+
+	// touch создает команду, которая создает файл с указанным именем
+	touch := func(name string) *parser.Command {
+		return &parser.Command{
+			Name:      "echo",
+			Args:      []*parser.Argument{word(name)},
+			Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(filepath.Join(dir, name))}},
+		}
+	}
+	fail := &parser.Command{
+		Name:      "cat",
+		Args:      []*parser.Argument{word(missing)},
+		Redirects: []*parser.Redirect{{Fd: 2, Op: parser.RedirectDupOutput, Target: word("-")}},
+	}
+
+	tests := []struct {
+		name       string
+		andOr      *parser.AndOr
+		wantStatus int
+		created    []string
+		skipped    []string
+	}{
+		{
+			name: "and after success",
+			andOr: &parser.AndOr{
+				Pipelines: []parser.Node{touch("a1"), touch("a2")},
+				Operators: []parser.AndOrOperator{parser.AndOperator},
+			},
+			wantStatus: 0,
+			created:    []string{"a1", "a2"},
+		},
+		{
+			name: "and after failure",
+			andOr: &parser.AndOr{
+				Pipelines: []parser.Node{fail, touch("b1")},
+				Operators: []parser.AndOrOperator{parser.AndOperator},
+			},
+			wantStatus: 1,
+			skipped:    []string{"b1"},
+		},
+		{
+			name: "or after failure",
+			andOr: &parser.AndOr{
+				Pipelines: []parser.Node{fail, touch("c1")},
+				Operators: []parser.AndOrOperator{parser.OrOperator},
+			},
+			wantStatus: 0,
+			created:    []string{"c1"},
+		},
+		{
+			name: "failure skips and, runs or",
+			andOr: &parser.AndOr{
+				Pipelines: []parser.Node{fail, touch("d1"), touch("d2")},
+				Operators: []parser.AndOrOperator{parser.AndOperator, parser.OrOperator},
+			},
+			wantStatus: 0,
+			created:    []string{"d2"},
+			skipped:    []string{"d1"},
+		},
+		{
+			name: "success skips or, runs and",
+			andOr: &parser.AndOr{
+				Pipelines: []parser.Node{touch("e1"), touch("e2"), touch("e3")},
+				Operators: []parser.AndOrOperator{parser.OrOperator, parser.AndOperator},
+			},
+			wantStatus: 0,
+			created:    []string{"e1", "e3"},
+			skipped:    []string{"e2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			err := executor.Execute(&parser.List{Items: []*parser.AndOr{tt.andOr}})
+
+			if (err != nil) != (tt.wantStatus != 0) {
+				t.Errorf("Executor.Execute() error = %v, expected status %d", err, tt.wantStatus)
+			}
+			if executor.LastStatus() != tt.wantStatus {
+				t.Errorf("Executor.LastStatus() = %d, expected %d", executor.LastStatus(), tt.wantStatus)
+			}
+			for _, name := range tt.created {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("expected %s to be executed", name)
+				}
+			}
+			for _, name := range tt.skipped {
+				if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+					t.Errorf("expected %s to be skipped", name)
+				}
+			}
+		})
+	}
+}
+
+// TestExecutor_ExecuteList тестирует последовательное выполнение списка команд.
+// Ошибка промежуточной команды не прерывает список, код возврата берется у последней.
+func TestExecutor_ExecuteList(t *testing.T) {
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	list := &parser.List{Items: []*parser.AndOr{
+		{Pipelines: []parser.Node{&parser.Command{Name: "nonexistent"}}},
+		{Pipelines: []parser.Node{&parser.Command{
+			Name:      "echo",
+			Args:      []*parser.Argument{word("after")},
+			Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectAppend, Target: word(out)}},
+		}}},
+	}}
+
+	if err := executor.Execute(list); err != nil {
+		t.Errorf("Executor.Execute() error = %v, expected no error", err)
+	}
+	if executor.LastStatus() != 0 {
+		t.Errorf("Executor.LastStatus() = %d, expected 0", executor.LastStatus())
+	}
+	if got := readFile(t, out); got != "after\n" {
+		t.Errorf("file content = %q, expected %q", got, "after\n")
+	}
+
+	// Статус ненайденной команды - 127
+	if err := executor.Execute(&parser.Command{Name: "nonexistent"}); err == nil {
+		t.Error("expected error for nonexistent command")
+	}
+	if executor.LastStatus() != 127 {
+		t.Errorf("Executor.LastStatus() = %d, expected 127", executor.LastStatus())
+	}
+}
+
+// executeWithIO выполняет узел AST с заданными потоками ввода/вывода, чтобы тест мог
+// перехватить вывод. Ошибка выполнения выводится в stderr потоков, как для промежуточных
+// команд списка. Возвращает код возврата узла; LastStatus при этом не изменяется.
+func executeWithIO(exec *Executor, node parser.Node, stdio *builtins.IO) int {
+	status, err := exec.executeNode(node, newContext(stdio))
+	exec.reportError(err, stdio)
+	return status
+}
+
+// TestExecutor_ExecuteWithIO проверяет выполнение узла с заданными потоками:
+// вывод попадает в переданный writer, ошибка - в stderr, а LastStatus не изменяется.
+func TestExecutor_ExecuteWithIO(t *testing.T) {
+	executor := NewExecutor()
+
+	var stdout, stderr bytes.Buffer
+	pipeline := &parser.Pipeline{Commands: []*parser.Command{
+		{Name: "echo", Args: []*parser.Argument{{Value: "hello"}}},
+		{Name: "wc"},
+	}}
+
+	status := executeWithIO(executor, pipeline, &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr})
+	if status != 0 {
+		t.Errorf("executeWithIO() status = %d, expected 0", status)
+	}
+	if stdout.String() != "1 1 6\n" {
+		t.Errorf("executeWithIO() output = %q, expected %q", stdout.String(), "1 1 6\n")
+	}
+
+	status = executeWithIO(executor, &parser.Command{Name: "nonexistent_command_12345"}, &builtins.IO{Stdout: &stdout, Stderr: &stderr})
+	if status != 127 {
+		t.Errorf("executeWithIO() status = %d, expected 127", status)
+	}
+	if !strings.Contains(stderr.String(), "nonexistent_command_12345") {
+		t.Errorf("expected error in stderr, got %q", stderr.String())
+	}
+	if executor.LastStatus() != 0 {
+		t.Errorf("LastStatus() = %d, expected unchanged 0", executor.LastStatus())
+	}
+}
+
+// TestExecutor_ExecuteIf проверяет выбор ветви условной конструкции по коду возврата
+// списка условия: выполняется тело первой успешной ветви, иначе ветвь else.
+func TestExecutor_ExecuteIf(t *testing.T) {
+	// echo создает список из одной команды echo с аргументом text
+	echo := func(text string) *parser.List {
+		return list(command("echo", text))
+	}
+	fail := list(command("cat", filepath.Join(t.TempDir(), "missing")))
+
+	tests := []struct {
+		name       string
+		node       *parser.If
+		wantOutput string
+		wantStatus int
+	}{
+		{
+			name:       "condition succeeds",
+			node:       &parser.If{Clauses: []*parser.IfClause{{Condition: echo("cond"), Body: echo("then")}}, Else: echo("else")},
+			wantOutput: "cond\nthen\n",
+		},
+		{
+			name:       "condition fails",
+			node:       &parser.If{Clauses: []*parser.IfClause{{Condition: fail, Body: echo("then")}}, Else: echo("else")},
+			wantOutput: "else\n",
+		},
+		{
+			name: "elif",
+			node: &parser.If{Clauses: []*parser.IfClause{
+				{Condition: fail, Body: echo("if")},
+				{Condition: echo("cond"), Body: echo("elif")},
+			}, Else: echo("else")},
+			wantOutput: "cond\nelif\n",
+		},
+		{
+			name:       "no branch taken",
+			node:       &parser.If{Clauses: []*parser.IfClause{{Condition: fail, Body: echo("then")}}},
+			wantOutput: "",
+		},
+		{
+			name:       "status of body",
+			node:       &parser.If{Clauses: []*parser.IfClause{{Condition: echo("cond"), Body: fail}}},
+			wantOutput: "cond\n",
+			wantStatus: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			var stdout, stderr bytes.Buffer
+
+			status := executeWithIO(executor, tt.node, &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr})
+			if status != tt.wantStatus {
+				t.Errorf("executeWithIO() status = %d, expected %d", status, tt.wantStatus)
+			}
+			if stdout.String() != tt.wantOutput {
+				t.Errorf("executeWithIO() output = %q, expected %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+// TestExecutor_ExecuteCompoundWithRedirect проверяет, что перенаправления составной команды
+// применяются ко всем командам внутри нее, в том числе в пайплайне.
+func TestExecutor_ExecuteCompoundWithRedirect(t *testing.T) {
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	body := &parser.List{Items: []*parser.AndOr{
+		{Pipelines: []parser.Node{&parser.Command{Name: "echo", Args: []*parser.Argument{word("a")}}}},
+		{Pipelines: []parser.Node{&parser.Command{Name: "echo", Args: []*parser.Argument{word("b")}}}},
+	}}
+	ifNode := &parser.If{Clauses: []*parser.IfClause{{Condition: &parser.List{Items: []*parser.AndOr{
+		{Pipelines: []parser.Node{&parser.Command{Name: "pwd", Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(os.DevNull)}}}}},
+	}}, Body: body}}}
+
+	pipeline := &parser.Pipeline{Commands: []*parser.Command{
+		{Compound: ifNode},
+		{Name: "wc", Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(out)}}},
+	}}
+	if err := executor.Execute(pipeline); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if got := readFile(t, out); got != "2 2 4\n" {
+		t.Errorf("wc output = %q, expected %q", got, "2 2 4\n")
+	}
+
+	command := &parser.Command{Compound: ifNode, Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(out)}}}
+	if err := executor.Execute(command); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if got := readFile(t, out); got != "a\nb\n" {
+		t.Errorf("file content = %q, expected %q", got, "a\nb\n")
+	}
+}
+
+// TestExecutor_ExecuteLoops проверяет циклы for, арифметический for и while,
+// а также команды break и continue, в том числе с выходом из вложенных циклов.
+func TestExecutor_ExecuteLoops(t *testing.T) {
+	// loop оборачивает составную команду в элемент списка
+	loop := func(node parser.Node) *parser.AndOr {
+		return &parser.AndOr{Pipelines: []parser.Node{node}}
+	}
+	words := func(values ...string) []*parser.Argument {
+		args := []*parser.Argument{}
+		for _, value := range values {
+			args = append(args, word(value))
+		}
+		return args
+	}
+	fail := command("cat", filepath.Join(t.TempDir(), "missing"))
+
+	tests := []struct {
+		name       string
+		node       parser.Node
+		wantOutput string
+		wantStderr string
+	}{
+		{
+			name:       "for",
+			node:       &parser.For{Variable: "x", Words: words("a", "b", "c"), Body: list(command("echo", "iteration"))},
+			wantOutput: "iteration\niteration\niteration\n",
+		},
+		{
+			name:       "for without words",
+			node:       &parser.For{Variable: "x", Words: words(), Body: list(command("echo", "iteration"))},
+			wantOutput: "",
+		},
+		{
+			name: "continue",
+			node: &parser.For{Variable: "x", Words: words("a", "b"), Body: list(
+				command("echo", "before"), command("continue"), command("echo", "after"),
+			)},
+			wantOutput: "before\nbefore\n",
+		},
+		{
+			name: "break in and-or chain",
+			node: &parser.For{Variable: "x", Words: words("a", "b"), Body: list(
+				&parser.AndOr{
+					Pipelines: []parser.Node{&parser.Command{Name: "echo", Args: words("once")}, &parser.Command{Name: "break"}, &parser.Command{Name: "echo", Args: words("skipped")}},
+					Operators: []parser.AndOrOperator{parser.AndOperator, parser.AndOperator},
+				},
+			)},
+			wantOutput: "once\n",
+		},
+		{
+			name: "break 2 from nested loop",
+			node: &parser.For{Variable: "x", Words: words("a", "b"), Body: list(
+				loop(&parser.For{Variable: "y", Words: words("c", "d"), Body: list(command("echo", "inner"), command("break", "2"))}),
+				command("echo", "outer"),
+			)},
+			wantOutput: "inner\n",
+		},
+		{
+			name: "continue 2 from nested loop",
+			node: &parser.For{Variable: "x", Words: words("a", "b"), Body: list(
+				loop(&parser.While{Condition: list(command("echo", "cond")), Body: list(command("continue", "2"))}),
+				command("echo", "outer"),
+			)},
+			wantOutput: "cond\ncond\n",
+		},
+		{
+			name: "break count larger than nesting",
+			node: &parser.For{Variable: "x", Words: words("a", "b"), Body: list(
+				command("echo", "once"), command("break", "10"),
+			)},
+			wantOutput: "once\n",
+		},
+		{
+			name:       "arithmetic for",
+			node:       &parser.ArithFor{Init: "i = 0", Cond: "i < 3", Update: "i++", Body: list(command("echo", "iteration"))},
+			wantOutput: "iteration\niteration\niteration\n",
+		},
+		{
+			name:       "arithmetic for with empty condition",
+			node:       &parser.ArithFor{Body: list(command("echo", "iteration"), command("break"))},
+			wantOutput: "iteration\n",
+		},
+		{
+			name:       "while with failing condition",
+			node:       &parser.While{Condition: list(fail), Body: list(command("echo", "iteration"))},
+			wantOutput: "",
+		},
+		{
+			name:       "until",
+			node:       &parser.While{Condition: list(fail), Body: list(command("echo", "iteration"), command("break")), Until: true},
+			wantOutput: "iteration\n",
+		},
+		{
+			name:       "break outside loop",
+			node:       list(command("break"), command("echo", "after")),
+			wantOutput: "after\n",
+			wantStderr: "break: only meaningful in a `for', `while', or `until' loop\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			var stdout, stderr bytes.Buffer
+
+			status := executeWithIO(executor, tt.node, &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr})
+			if status != 0 {
+				t.Errorf("executeWithIO() status = %d, expected 0", status)
+			}
+			if stdout.String() != tt.wantOutput {
+				t.Errorf("executeWithIO() output = %q, expected %q", stdout.String(), tt.wantOutput)
+			}
+			if !strings.HasSuffix(stderr.String(), tt.wantStderr) {
+				t.Errorf("executeWithIO() stderr = %q, expected %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+// TestExecutor_ExecuteLoopVariables проверяет значения переменных после циклов:
+// переменная for сохраняет последнее слово, а переменная арифметического цикла -
+// значение после последнего вычисления update.
+func TestExecutor_ExecuteLoopVariables(t *testing.T) {
+	executor := NewExecutor()
+	body := &parser.List{Items: []*parser.AndOr{{Pipelines: []parser.Node{&parser.Command{Name: "pwd", Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(os.DevNull)}}}}}}}
+
+	if err := executor.Execute(&parser.For{Variable: "x", Words: []*parser.Argument{word("a"), word("b")}, Body: body}); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if value, _ := executor.environment.Get("x"); value != "b" {
+		t.Errorf("x = %q, expected %q", value, "b")
+	}
+
+	executor.environment.SetPositional([]string{"p1", "p2", "p3"})
+	if err := executor.Execute(&parser.For{Variable: "x", Body: body}); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if value, _ := executor.environment.Get("x"); value != "p3" {
+		t.Errorf("x = %q, expected %q", value, "p3")
+	}
+
+	if err := executor.Execute(&parser.ArithFor{Init: "i = 0", Cond: "i < 5", Update: "i += 2", Body: body}); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if value, _ := executor.environment.Get("i"); value != "6" {
+		t.Errorf("i = %q, expected %q", value, "6")
+	}
+
+	if err := executor.Execute(&parser.ArithFor{Init: "1 / 0", Body: body}); err == nil {
+		t.Error("expected error for invalid arithmetic expression")
+	}
+}
+
+// TestExecutor_ExecuteCase проверяет выбор ветви case по шаблонам и операторы
+// завершения ветвей ;;, ;& и ;;&.
+func TestExecutor_ExecuteCase(t *testing.T) {
+	// item создает ветвь, тело которой выводит text
+	item := func(text string, terminator parser.CaseTerminator, patterns ...string) *parser.CaseItem {
+		args := []*parser.Argument{}
+		for _, pattern := range patterns {
+			args = append(args, word(pattern))
+		}
+		body := &parser.List{Items: []*parser.AndOr{
+			{Pipelines: []parser.Node{&parser.Command{Name: "echo", Args: []*parser.Argument{word(text)}}}},
+		}}
+		return &parser.CaseItem{Patterns: args, Body: body, Terminator: terminator}
+	}
+
+	tests := []struct {
+		name       string
+		word       string
+		items      []*parser.CaseItem
+		wantOutput string
+	}{
+		{
+			name:       "first match",
+			word:       "stop",
+			items:      []*parser.CaseItem{item("start", parser.CaseBreak, "start", "stop"), item("any", parser.CaseBreak, "*")},
+			wantOutput: "start\n",
+		},
+		{
+			name:       "glob pattern",
+			word:       "file.go",
+			items:      []*parser.CaseItem{item("text", parser.CaseBreak, "*.txt"), item("go", parser.CaseBreak, "*.[gc]o"), item("any", parser.CaseBreak, "*")},
+			wantOutput: "go\n",
+		},
+		{
+			name:       "no match",
+			word:       "x",
+			items:      []*parser.CaseItem{item("a", parser.CaseBreak, "a"), item("b", parser.CaseBreak, "b?")},
+			wantOutput: "",
+		},
+		{
+			name:       "fall through",
+			word:       "a",
+			items:      []*parser.CaseItem{item("a", parser.CaseFallThrough, "a"), item("b", parser.CaseBreak, "b"), item("c", parser.CaseBreak, "c")},
+			wantOutput: "a\nb\n",
+		},
+		{
+			name:       "continue matching",
+			word:       "ab",
+			items:      []*parser.CaseItem{item("a", parser.CaseContinue, "a*"), item("c", parser.CaseBreak, "c*"), item("b", parser.CaseBreak, "*b"), item("any", parser.CaseBreak, "*")},
+			wantOutput: "a\nb\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			var stdout, stderr bytes.Buffer
+
+			node := &parser.Case{Word: word(tt.word), Items: tt.items}
+			status := executeWithIO(executor, node, &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr})
+			if status != 0 {
+				t.Errorf("executeWithIO() status = %d, expected 0", status)
+			}
+			if stdout.String() != tt.wantOutput {
+				t.Errorf("executeWithIO() output = %q, expected %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+// TestExecutor_ExecuteFunction проверяет определение и вызов функций: выполнение тела,
+// выход по return с кодом возврата, независимость break от циклов вызывающего кода
+// и восстановление позиционных параметров и локальных переменных после вызова.
+func TestExecutor_ExecuteFunction(t *testing.T) {
+	// define создает элемент списка, определяющий функцию name с телом body
+	define := func(name string, body *parser.List) *parser.AndOr {
+		return &parser.AndOr{Pipelines: []parser.Node{&parser.Function{Name: name, Body: &parser.Group{Body: body}}}}
+	}
+
+	tests := []struct {
+		name       string
+		node       parser.Node
+		wantStatus int
+		wantOutput string
+		wantStderr string
+	}{
+		{
+			name:       "call",
+			node:       list(define("greet", list(command("echo", "hello"))), command("greet", "a"), command("greet")),
+			wantOutput: "hello\nhello\n",
+		},
+		{
+			name:       "definition does not run body",
+			node:       list(define("greet", list(command("echo", "hello")))),
+			wantOutput: "",
+		},
+		{
+			name:       "return",
+			node:       list(define("f", list(command("echo", "before"), command("return", "3"), command("echo", "after"))), command("f")),
+			wantStatus: 3,
+			wantOutput: "before\n",
+		},
+		{
+			name: "return from loop",
+			node: list(define("f", list(
+				&parser.AndOr{Pipelines: []parser.Node{&parser.For{Variable: "x", Words: []*parser.Argument{word("a"), word("b")}, Body: list(command("echo", "loop"), command("return"))}}},
+				command("echo", "after"),
+			)), command("f"), command("echo", "done")),
+			wantOutput: "loop\ndone\n",
+		},
+		{
+			name: "break does not leave caller loop",
+			node: &parser.For{Variable: "x", Words: []*parser.Argument{word("a"), word("b")}, Body: list(
+				define("f", list(command("break"))), command("f"), command("echo", "iteration"),
+			)},
+			wantOutput: "iteration\niteration\n",
+			wantStderr: "break: only meaningful in a `for', `while', or `until' loop\n",
+		},
+		{
+			name:       "return outside function",
+			node:       list(command("return", "1"), command("echo", "after")),
+			wantOutput: "after\n",
+			wantStderr: "Error: return: can only `return' from a function or sourced script\n",
+		},
+		{
+			name:       "redefinition",
+			node:       list(define("f", list(command("echo", "one"))), define("f", list(command("echo", "two"))), command("f")),
+			wantOutput: "two\n",
+		},
+		{
+			name:       "infinite recursion",
+			node:       list(define("f", list(command("f"))), command("f")),
+			wantStatus: 1,
+			wantStderr: "f: maximum function nesting level exceeded (1000)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			var stdout, stderr bytes.Buffer
+
+			status := executeWithIO(executor, tt.node, &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr})
+			if status != tt.wantStatus {
+				t.Errorf("executeWithIO() status = %d, expected %d", status, tt.wantStatus)
+			}
+			if stdout.String() != tt.wantOutput {
+				t.Errorf("executeWithIO() output = %q, expected %q", stdout.String(), tt.wantOutput)
+			}
+			if !strings.HasSuffix(stderr.String(), tt.wantStderr) {
+				t.Errorf("executeWithIO() stderr = %q, expected %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+
+	executor := NewExecutor()
+	executor.environment.SetPositional([]string{"outer"})
+	executor.environment.Set("x", "global")
+	body := &parser.List{Items: []*parser.AndOr{command("local", "x=local", "y=1")}}
+	if err := executor.Execute(&parser.Function{Name: "f", Body: &parser.Group{Body: body}}); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if err := executor.Execute(&parser.Command{Name: "f", Args: []*parser.Argument{word("a"), word("b")}}); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if params := executor.environment.Positional(); len(params) != 1 || params[0] != "outer" {
+		t.Errorf("Positional() = %v, expected [outer]", params)
+	}
+	if value, _ := executor.environment.Get("x"); value != "global" {
+		t.Errorf("x = %q, expected %q", value, "global")
+	}
+	if _, exists := executor.environment.Get("y"); exists {
+		t.Error("local variable y should not exist after function call")
+	}
+}
+
+// TestExecutor_ExecuteSubshell проверяет подоболочки: переменные, функции и рабочий каталог
+// внутри них не изменяются снаружи, exit завершает только подоболочку, а break
+// и return не выходят за её пределы. Группа { ...; } выполняется в текущем shell'е.
+func TestExecutor_ExecuteSubshell(t *testing.T) {
+	// assign создает элемент списка из присваивания
+	assign := func(name, value string) *parser.AndOr {
+		return &parser.AndOr{Pipelines: []parser.Node{&parser.Command{Assignments: []*parser.Assignment{{Name: name, Value: word(value)}}}}}
+	}
+	// item оборачивает составную команду в элемент списка
+	item := func(node parser.Node) *parser.AndOr {
+		return &parser.AndOr{Pipelines: []parser.Node{node}}
+	}
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd() error = %v", err)
+	}
+
+	executor := NewExecutor()
+	var stdout, stderr bytes.Buffer
+	stdio := &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+
+	executor.environment.Set("x", "outer")
+	node := list(
+		item(&parser.Subshell{Body: list(
+			assign("x", "inner"),
+			item(&parser.Function{Name: "f", Body: &parser.Group{Body: list(command("echo", "f"))}}),
+			command("cd", dir),
+			command("pwd"),
+		)}),
+		command("pwd"),
+	)
+	if status := executeWithIO(executor, node, stdio); status != 0 {
+		t.Errorf("executeWithIO() status = %d, expected 0 (stderr %q)", status, stderr.String())
+	}
+	if expected := dir + "\n" + wd + "\n"; stdout.String() != expected {
+		t.Errorf("executeWithIO() output = %q, expected %q", stdout.String(), expected)
+	}
+	if value, _ := executor.environment.Get("x"); value != "outer" {
+		t.Errorf("x = %q, expected %q", value, "outer")
+	}
+	if _, exists := executor.environment.Function("f"); exists {
+		t.Error("function defined in subshell should not exist outside")
+	}
+	if current, _ := os.Getwd(); current != wd {
+		t.Errorf("working directory = %q, expected %q", current, wd)
+	}
+
+	// Группа выполняется в текущем shell'е
+	if status := executeWithIO(executor, &parser.Group{Body: list(assign("x", "group"))}, stdio); status != 0 {
+		t.Errorf("executeWithIO() status = %d, expected 0", status)
+	}
+	if value, _ := executor.environment.Get("x"); value != "group" {
+		t.Errorf("x = %q, expected %q", value, "group")
+	}
+
+	tests := []struct {
+		name       string
+		node       parser.Node
+		wantStatus int
+		wantOutput string
+	}{
+		{
+			name:       "exit",
+			node:       list(item(&parser.Subshell{Body: list(command("exit", "3"), command("echo", "skipped"))}), command("echo", "after")),
+			wantOutput: "after\n",
+		},
+		{
+			name:       "exit status",
+			node:       &parser.Subshell{Body: list(command("exit", "260"))},
+			wantStatus: 4,
+		},
+		{
+			name:       "exit with too many arguments",
+			node:       &parser.Subshell{Body: list(command("exit", "1", "2"), command("echo", "after"))},
+			wantOutput: "after\n",
+		},
+		{
+			name:       "exit without argument",
+			node:       &parser.Subshell{Body: list(command("test", "a", "=", "b"), command("exit"))},
+			wantStatus: 1,
+		},
+		{
+			name: "break inside subshell",
+			node: &parser.For{Variable: "i", Words: []*parser.Argument{word("a"), word("b")}, Body: list(
+				item(&parser.Subshell{Body: list(command("break"), command("echo", "skipped"))}), command("echo", "iteration"),
+			)},
+			wantOutput: "iteration\niteration\n",
+		},
+		{
+			name: "exit inside loop inside subshell",
+			node: list(item(&parser.Subshell{Body: list(
+				item(&parser.For{Variable: "i", Words: []*parser.Argument{word("a"), word("b")}, Body: list(command("echo", "iteration"), command("exit"))}),
+				command("echo", "skipped"),
+			)})),
+			wantOutput: "iteration\n",
+		},
+		{
+			name: "pipeline stage",
+			node: &parser.Pipeline{Commands: []*parser.Command{
+				{Compound: &parser.Subshell{Body: list(command("echo", "a"), command("echo", "b"))}},
+				{Name: "wc"},
+			}},
+			wantOutput: "2 2 4\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := executeWithIO(executor, tt.node, &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr})
+			if status != tt.wantStatus {
+				t.Errorf("executeWithIO() status = %d, expected %d", status, tt.wantStatus)
+			}
+			if stdout.String() != tt.wantOutput {
+				t.Errorf("executeWithIO() output = %q, expected %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+// TestExecutor_ExecuteExit проверяет команду exit вне подоболочки: выполнение списка
+// прекращается, а Execute возвращает *ShellExit с кодом завершения.
+func TestExecutor_ExecuteExit(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		lastStatus string
+		wantStatus int
+	}{
+		{name: "status", args: []string{"3"}, wantStatus: 3},
+		{name: "modulo 256", args: []string{"257"}, wantStatus: 1},
+		{name: "last status", lastStatus: "5", wantStatus: 5},
+		{name: "invalid argument", args: []string{"abc"}, wantStatus: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			if tt.lastStatus != "" {
+				executor.environment.SetSpecial("?", tt.lastStatus)
+			}
+
+			exit := &parser.Command{Name: "exit"}
+			for _, arg := range tt.args {
+				exit.Args = append(exit.Args, word(arg))
+			}
+			assign := &parser.Command{Assignments: []*parser.Assignment{{Name: "x", Value: word("after")}}}
+			list := &parser.List{Items: []*parser.AndOr{{Pipelines: []parser.Node{exit}}, {Pipelines: []parser.Node{assign}}}}
+
+			var shellExit *ShellExit
+			if err := executor.Execute(list); !errors.As(err, &shellExit) {
+				t.Fatalf("Executor.Execute() error = %v, expected *ShellExit", err)
+			}
+			if shellExit.Status != tt.wantStatus {
+				t.Errorf("ShellExit.Status = %d, expected %d", shellExit.Status, tt.wantStatus)
+			}
+			if executor.LastStatus() != tt.wantStatus {
+				t.Errorf("LastStatus() = %d, expected %d", executor.LastStatus(), tt.wantStatus)
+			}
+			if _, exists := executor.environment.Get("x"); exists {
+				t.Error("commands after exit should not be executed")
+			}
+		})
+	}
+}
+
+// TestExecutor_ExecuteConditional тестирует вычисление [[ ]]: сравнение с шаблоном,
+// регулярные выражения с заполнением BASH_REMATCH, логические операторы и ошибки.
+func TestExecutor_ExecuteConditional(t *testing.T) {
+	// binary создает бинарную проверку
+	binary := func(left, op, right string) parser.CondExpr {
+		return &parser.CondBinary{Op: op, Left: word(left), Right: word(right)}
+	}
+
+	dir := t.TempDir()
+	tests := []struct {
+		name       string
+		expr       parser.CondExpr
+		wantStatus int
+	}{
+		{"non-empty word", &parser.CondWord{Word: word("a")}, 0},
+		{"empty word", &parser.CondWord{Word: word("")}, 1},
+		{"pattern", binary("hello", "==", "h*o"), 0},
+		{"pattern mismatch", binary("hello", "==", "h?"), 1},
+		{"negated pattern", binary("hello", "!=", "x*"), 0},
+		{"regex", binary("abc", "=~", "^a.c$"), 0},
+		{"regex mismatch", binary("abc", "=~", "^b"), 1},
+		{"integers", binary("10", "-gt", "9"), 0},
+		{"strings", binary("b", "<", "a"), 1},
+		{"directory", &parser.CondUnary{Op: "-d", Operand: word(dir)}, 0},
+		{"not", &parser.CondNot{Expr: &parser.CondUnary{Op: "-f", Operand: word(dir)}}, 0},
+		{"and", &parser.CondAndOr{Op: parser.AndOperator, Left: &parser.CondWord{Word: word("a")}, Right: &parser.CondWord{Word: word("")}}, 1},
+		{"or", &parser.CondAndOr{Op: parser.OrOperator, Left: &parser.CondWord{Word: word("")}, Right: &parser.CondGroup{Expr: &parser.CondWord{Word: word("a")}}}, 0},
+		{"short circuit", &parser.CondAndOr{Op: parser.OrOperator, Left: &parser.CondWord{Word: word("a")}, Right: binary("x", "-eq", "1")}, 0},
+		{"integer error", binary("x", "-eq", "1"), 2},
+		{"regex error", binary("a", "=~", "("), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor()
+			var stdout, stderr bytes.Buffer
+			stdio := &builtins.IO{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+
+			status := executeWithIO(executor, &parser.Conditional{Expr: tt.expr}, stdio)
+			if status != tt.wantStatus {
+				t.Errorf("executeWithIO() status = %d, expected %d (stderr %q)", status, tt.wantStatus, stderr.String())
+			}
+			if (status == 2) != (stderr.Len() > 0) {
+				t.Errorf("stderr = %q for status %d", stderr.String(), status)
+			}
+		})
+	}
+
+	executor := NewExecutor()
+	stdio := &builtins.IO{Stdin: strings.NewReader(""), Stdout: io.Discard, Stderr: io.Discard}
+	executeWithIO(executor, &parser.Conditional{Expr: binary("key=value", "=~", "^([a-z]+)=(.*)$")}, stdio)
+	if values, _ := executor.environment.Array("BASH_REMATCH"); !slices.Equal(values, []string{"key=value", "key", "value"}) {
+		t.Errorf("BASH_REMATCH = %q, expected [key=value key value]", values)
+	}
+
+	executeWithIO(executor, &parser.Conditional{Expr: binary("key", "=~", "^[0-9]+$")}, stdio)
+	if values, exists := executor.environment.Array("BASH_REMATCH"); exists {
+		t.Errorf("BASH_REMATCH = %q after failed match, expected unset", values)
+	}
+}
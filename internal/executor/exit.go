@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	"gocli/internal/builtins"
+	"gocli/internal/expander"
+)
+
+// ShellExit - сигнал команды exit. Как и сигналы циклов и return, он передается
+// вверх по AST в виде ошибки: в подоболочке он завершает только ее (см. runSubshell),
+// а из Execute возвращается вызывающему коду, который завершает shell с кодом Status.
+// Сигнал подает и ошибка, завершающая неинтерактивный shell (см. expansionError):
+// тогда Err - сама ошибка, о которой нужно сообщить перед завершением.
+type ShellExit struct {
+	Status int   // Код завершения shell'а
+	Err    error // Ошибка, завершившая shell (nil для команды exit)
+}
+
+// Error возвращает сообщение завершившей shell ошибки или имя команды exit.
+func (e *ShellExit) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return builtins.ExitCommandName
+}
+
+// Unwrap возвращает ошибку, завершившую shell.
+func (e *ShellExit) Unwrap() error {
+	return e.Err
+}
+
+// expansionError возвращает ошибку подстановки для вызывающего кода. Ошибка ${VAR:?word}
+// завершает неинтерактивный shell (скрипт, gocli -c, подоболочку) с кодом 1, как
+// в POSIX shell; интерактивный shell выводит ее и продолжает работу.
+func (exec *Executor) expansionError(err error) error {
+	err = fmt.Errorf("variable expansion failed: %w", err)
+	var unset *expander.UnsetParameterError
+	if exec.interactive || !errors.As(err, &unset) {
+		return err
+	}
+	return &ShellExit{Status: statusFailure, Err: err}
+}
+
+// executeExit выполняет команду exit [n]: завершает shell (или подоболочку) с кодом n
+// по модулю 256, а без аргумента - с кодом последней команды ($?).
+// Нечисловой аргумент - ошибка, shell завершается с кодом 2. Несколько аргументов -
+// ошибка с кодом 1, после которой shell, как bash, продолжает работу.
+func (exec *Executor) executeExit(name string, args []string, ctx *execContext) (int, error) {
+	if len(args) > 1 {
+		return statusFailure, fmt.Errorf("%s: too many arguments", name)
+	}
+
+	// Код завершения вычисляет встроенная команда exit, чтобы разбор аргумента был один
+	env := make(map[string]string, 1)
+	if value, ok := exec.environment.GetSpecial("?"); ok {
+		env[builtins.StatusVariable] = value
+	}
+	status := builtins.NewExitCommand().Execute(args, env, nil, nil, ctx.stdio.Stderr)
+	return status, &ShellExit{Status: status}
+}
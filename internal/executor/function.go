@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gocli/internal/parser"
+)
+
+// returnCommandName - имя команды выхода из функции.
+const returnCommandName = "return"
+
+// maxFunctionDepth ограничивает вложенность вызовов функций, чтобы бесконечная
+// рекурсия завершалась ошибкой, а не переполнением стека shell'а.
+const maxFunctionDepth = 1000
+
+// functionReturn - сигнал команды return. Как и сигналы циклов, он передается
+// вверх по AST в виде ошибки и снимается вызовом функции (см. executeFunction)
+// или выполнением файла командой source (см. sourceFile).
+type functionReturn struct {
+	status int // Код возврата функции
+}
+
+// Error возвращает имя команды, подавшей сигнал.
+func (r *functionReturn) Error() string {
+	return returnCommandName
+}
+
+// isControlFlow проверяет, что ошибка - сигнал break, continue, return или exit,
+// который прерывает списки команд и передается охватывающей конструкции.
+func isControlFlow(err error) bool {
+	var ret *functionReturn
+	var exit *ShellExit
+	return isLoopControl(err) || errors.As(err, &ret) || errors.As(err, &exit)
+}
+
+// executeFunctionDefinition сохраняет функцию в окружении; тело не выполняется.
+func (exec *Executor) executeFunctionDefinition(fn *parser.Function) (int, error) {
+	exec.environment.SetFunction(fn)
+	return 0, nil
+}
+
+// executeFunction вызывает функцию shell'а: аргументы вызова становятся позиционными
+// параметрами, а для переменных, объявленных командой local, создается область видимости.
+// После выполнения тела позиционные параметры и переменные восстанавливаются.
+// Код возврата - значение return или код последней команды тела.
+func (exec *Executor) executeFunction(fn *parser.Function, args []string, ctx *execContext) (int, error) {
+	// Глубина считается по исполнителю, а не по контексту: рекурсия через подстановку
+	// команды $(...) начинает выполнение с нового контекста
+	if exec.functionDepth.Add(1) > maxFunctionDepth {
+		exec.functionDepth.Add(-1)
+		return statusFailure, fmt.Errorf("%s: maximum function nesting level exceeded (%d)", fn.Name, maxFunctionDepth)
+	}
+	defer exec.functionDepth.Add(-1)
+
+	positional := exec.environment.Positional()
+	exec.environment.SetPositional(args)
+	exec.environment.PushScope()
+	defer func() {
+		exec.environment.PopScope()
+		exec.environment.SetPositional(positional)
+	}()
+
+	status, err := exec.executeNode(fn.Body, ctx.inFunction())
+	var ret *functionReturn
+	if errors.As(err, &ret) {
+		if ret.status != 0 {
+			return ret.status, &ExitStatusError{Name: fn.Name, Code: ret.status}
+		}
+		return 0, nil
+	}
+	return status, err
+}
+
+// executeReturn выполняет команду return [n]: выход из функции с кодом n
+// (по умолчанию - код последней выполненной команды, $?). Код берется по модулю 256.
+// В файле, выполняемом командой source, return завершает выполнение файла.
+// Вне функции и файла source команда завершается с ошибкой.
+func (exec *Executor) executeReturn(name string, args []string, ctx *execContext) (int, error) {
+	if ctx.functions == 0 && ctx.sources == 0 {
+		return statusFailure, fmt.Errorf("%s: can only `return' from a function or sourced script", name)
+	}
+
+	status := 0
+	if value, ok := exec.environment.GetSpecial("?"); ok {
+		status, _ = strconv.Atoi(value)
+	}
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return statusFailure, fmt.Errorf("%s: %s: numeric argument required", name, args[0])
+		}
+		status = int(uint8(n))
+	}
+	return status, &functionReturn{status: status}
+}
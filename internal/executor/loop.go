@@ -0,0 +1,195 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gocli/internal/arith"
+	"gocli/internal/parser"
+)
+
+// Имена команд управления циклами.
+const (
+	breakCommandName    = "break"
+	continueCommandName = "continue"
+)
+
+// loopControl - сигнал команды break или continue. Он передается вверх по AST
+// как ошибка: списки и цепочки && и || прекращают выполнение, а охватывающий цикл
+// снимает сигнал (см. handleLoopControl).
+type loopControl struct {
+	next   bool // continue: перейти к следующей итерации вместо выхода из цикла
+	levels int  // Число охватывающих циклов, к которым относится сигнал
+}
+
+// Error возвращает имя команды, подавшей сигнал.
+func (c *loopControl) Error() string {
+	if c.next {
+		return continueCommandName
+	}
+	return breakCommandName
+}
+
+// isLoopControl проверяет, что ошибка - сигнал break или continue.
+func isLoopControl(err error) bool {
+	var control *loopControl
+	return errors.As(err, &control)
+}
+
+// handleLoopControl обрабатывает сигнал break или continue после выполнения части цикла.
+// Сигнал для этого цикла снимается (err становится nil), а сигнал для внешних циклов
+// передается дальше с уменьшенным числом уровней; прочие сигналы (return, exit в подоболочке)
+// прерывают цикл без изменений. Возвращает true, если цикл нужно прервать.
+func handleLoopControl(err *error) bool {
+	var control *loopControl
+	if !errors.As(*err, &control) {
+		return isControlFlow(*err)
+	}
+	if control.levels > 1 {
+		control.levels--
+		return true
+	}
+	*err = nil
+	return !control.next
+}
+
+// executeLoopControl выполняет команды break [n] и continue [n].
+// n (по умолчанию 1) - число охватывающих циклов; если циклов меньше, сигнал
+// относится к самому внешнему. Вне цикла команда выводит предупреждение и ничего не делает.
+func (exec *Executor) executeLoopControl(name string, args []string, ctx *execContext) (int, error) {
+	levels := 1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return statusFailure, fmt.Errorf("%s: %s: numeric argument required", name, args[0])
+		}
+		if n < 1 {
+			return statusFailure, fmt.Errorf("%s: %s: loop count out of range", name, args[0])
+		}
+		levels = n
+	}
+
+	if ctx.loops == 0 {
+		fmt.Fprintf(ctx.stdio.Stderr, "%s: only meaningful in a `for', `while', or `until' loop\n", name)
+		return 0, nil
+	}
+	return 0, &loopControl{next: name == continueCommandName, levels: min(levels, ctx.loops)}
+}
+
+// executeFor выполняет цикл for: переменная цикла по очереди получает значения слов
+// после подстановок (или позиционных параметров, если in не указан), и для каждого
+// выполняется тело. Код возврата цикла - код последней команды тела, 0 без итераций.
+// Ошибки промежуточных итераций выводятся в stderr и не прерывают цикл.
+func (exec *Executor) executeFor(node *parser.For, ctx *execContext) (int, error) {
+	words, err := exec.expandWords(node.Words)
+	if err != nil {
+		return statusFailure, err
+	}
+
+	loopCtx := ctx.inLoop()
+	status := 0
+	for _, word := range words {
+		exec.reportError(err, ctx.stdio)
+		exec.environment.Set(node.Variable, word)
+		status, err = exec.executeNode(node.Body, loopCtx)
+		if handleLoopControl(&err) {
+			break
+		}
+	}
+	return status, err
+}
+
+// expandWords раскрывает слова цикла for; nil означает позиционные параметры ("$@").
+func (exec *Executor) expandWords(words []*parser.Argument) ([]string, error) {
+	if words == nil {
+		return exec.environment.Positional(), nil
+	}
+	if exec.expander == nil {
+		values := make([]string, len(words))
+		for i, word := range words {
+			values[i] = word.Value
+		}
+		return values, nil
+	}
+
+	values, err := exec.expander.ExpandWords(words)
+	if err != nil {
+		return nil, exec.expansionError(err)
+	}
+	return values, nil
+}
+
+// executeArithFor выполняет арифметический цикл for ((init; cond; update)).
+// Ошибка вычисления выражения завершает цикл с кодом 1.
+func (exec *Executor) executeArithFor(node *parser.ArithFor, ctx *execContext) (int, error) {
+	if _, err := exec.evaluateArithmetic(node.Init); err != nil {
+		return statusFailure, err
+	}
+
+	loopCtx := ctx.inLoop()
+	status := 0
+	var err error
+	for {
+		exec.reportError(err, ctx.stdio)
+		if node.Cond != "" {
+			value, condErr := exec.evaluateArithmetic(node.Cond)
+			if condErr != nil {
+				return statusFailure, condErr
+			}
+			if value == 0 {
+				return status, nil
+			}
+		}
+
+		status, err = exec.executeNode(node.Body, loopCtx)
+		if handleLoopControl(&err) {
+			return status, err
+		}
+
+		if _, updateErr := exec.evaluateArithmetic(node.Update); updateErr != nil {
+			return statusFailure, updateErr
+		}
+	}
+}
+
+// evaluateArithmetic вычисляет арифметическое выражение заголовка цикла.
+// Без expander'а выражение вычисляется без подстановок.
+func (exec *Executor) evaluateArithmetic(expr string) (int64, error) {
+	var value int64
+	var err error
+	if exec.expander != nil {
+		value, err = exec.expander.EvaluateArithmetic(expr)
+	} else {
+		value, err = arith.Evaluate(expr, exec.environment)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("arithmetic for: %w", err)
+	}
+	return value, nil
+}
+
+// executeWhile выполняет цикл while (или until): тело выполняется, пока список условия
+// завершается с кодом 0 (для until - с ненулевым кодом). Код возврата цикла - код
+// последней команды тела, 0 без итераций. Ненулевой код условия не считается ошибкой.
+func (exec *Executor) executeWhile(node *parser.While, ctx *execContext) (int, error) {
+	loopCtx := ctx.inLoop()
+	status := 0
+	var err error
+	for {
+		exec.reportError(err, ctx.stdio)
+		condStatus, condErr := exec.executeNode(node.Condition, loopCtx)
+		if handleLoopControl(&condErr) {
+			return status, condErr
+		}
+		exec.reportError(condErr, ctx.stdio)
+		if (condStatus == 0) == node.Until {
+			return status, nil
+		}
+
+		status, err = exec.executeNode(node.Body, loopCtx)
+		if handleLoopControl(&err) {
+			return status, err
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gocli/internal/builtins"
+	"gocli/internal/parser"
+)
+
+// redirectFileMode задает права доступа для файлов, создаваемых перенаправлением.
+const redirectFileMode = 0o644
+
+// closeDescriptor - цель дублирования, закрывающая дескриптор (например, 2>&-).
+const closeDescriptor = "-"
+
+// applyRedirects применяет перенаправления к потокам stdio слева направо.
+// Исходная структура stdio не изменяется: возвращается новая структура IO
+// и функция, закрывающая все файлы, открытые при перенаправлении.
+// При ошибке уже открытые файлы закрываются автоматически.
+func (exec *Executor) applyRedirects(redirects []*parser.Redirect, stdio *builtins.IO) (*builtins.IO, func(), error) {
+	result := &builtins.IO{
+		Stdin:  stdio.Stdin,
+		Stdout: stdio.Stdout,
+		Stderr: stdio.Stderr,
+	}
+
+	var opened []*os.File
+	closeFiles := func() {
+		for _, file := range opened {
+			file.Close()
+		}
+	}
+
+	for _, redirect := range redirects {
+		file, err := exec.applyRedirect(redirect, result)
+		if file != nil {
+			opened = append(opened, file)
+		}
+		if err != nil {
+			closeFiles()
+			return nil, nil, err
+		}
+	}
+
+	return result, closeFiles, nil
+}
+
+// applyRedirect применяет одно перенаправление к потокам stdio.
+// Возвращает открытый файл (если перенаправление открывает файл), чтобы вызывающий код
+// мог закрыть его после выполнения команды.
+func (exec *Executor) applyRedirect(redirect *parser.Redirect, stdio *builtins.IO) (*os.File, error) {
+	target := redirect.Target.Value
+
+	switch redirect.Op {
+	case parser.RedirectOutput:
+		return exec.openOutput(stdio, redirect.Fd, target, os.O_TRUNC)
+	case parser.RedirectAppend:
+		return exec.openOutput(stdio, redirect.Fd, target, os.O_APPEND)
+	case parser.RedirectAll:
+		return exec.openOutputAll(stdio, target, os.O_TRUNC)
+	case parser.RedirectAppendAll:
+		return exec.openOutputAll(stdio, target, os.O_APPEND)
+	case parser.RedirectInput:
+		if redirect.Fd != 0 {
+			return nil, fmt.Errorf("%d: bad file descriptor", redirect.Fd)
+		}
+		file, err := builtins.OpenFile(exec.environment.Dir(), target, os.O_RDONLY, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		stdio.Stdin = file
+		return file, nil
+	case parser.RedirectDupOutput:
+		// Форма >&file (без номера дескриптора) эквивалентна &>file
+		if !isDescriptor(target) && target != closeDescriptor && redirect.Fd == 1 {
+			return exec.openOutputAll(stdio, target, os.O_TRUNC)
+		}
+		return nil, duplicateOutput(stdio, redirect.Fd, target)
+	case parser.RedirectDupInput:
+		return nil, duplicateInput(stdio, redirect.Fd, target)
+	case parser.RedirectHeredoc:
+		return nil, setInput(stdio, redirect.Fd, target)
+	case parser.RedirectHereString:
+		// Here-string передается в stdin с завершающим переводом строки, как в bash
+		return nil, setInput(stdio, redirect.Fd, target+"\n")
+	default:
+		return nil, fmt.Errorf("unsupported redirection %s", redirect.Op)
+	}
+}
+
+// openOutput открывает файл на запись и связывает его с дескриптором fd.
+// Параметр mode определяет режим: os.O_TRUNC для > и os.O_APPEND для >>.
+// Относительный путь отсчитывается от рабочего каталога исполнителя.
+func (exec *Executor) openOutput(stdio *builtins.IO, fd int, target string, mode int) (*os.File, error) {
+	if fd != 1 && fd != 2 {
+		return nil, fmt.Errorf("%d: bad file descriptor", fd)
+	}
+
+	file, err := builtins.OpenFile(exec.environment.Dir(), target, os.O_WRONLY|os.O_CREATE|mode, redirectFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", target, err)
+	}
+
+	setOutput(stdio, fd, file)
+	return file, nil
+}
+
+// openOutputAll открывает файл на запись и направляет в него и stdout, и stderr.
+func (exec *Executor) openOutputAll(stdio *builtins.IO, target string, mode int) (*os.File, error) {
+	file, err := exec.openOutput(stdio, 1, target, mode)
+	if err != nil {
+		return nil, err
+	}
+	stdio.Stderr = file
+	return file, nil
+}
+
+// duplicateOutput делает дескриптор fd копией дескриптора вывода target (например, 2>&1).
+// Цель "-" закрывает дескриптор: вывод в него отбрасывается.
+func duplicateOutput(stdio *builtins.IO, fd int, target string) error {
+	if fd != 1 && fd != 2 {
+		return fmt.Errorf("%d: bad file descriptor", fd)
+	}
+
+	if target == closeDescriptor {
+		setOutput(stdio, fd, io.Discard)
+		return nil
+	}
+
+	switch target {
+	case "1":
+		setOutput(stdio, fd, stdio.Stdout)
+	case "2":
+		setOutput(stdio, fd, stdio.Stderr)
+	default:
+		return fmt.Errorf("%s: bad file descriptor", target)
+	}
+	return nil
+}
+
+// duplicateInput делает стандартный ввод копией дескриптора ввода target (например, 0<&0).
+// Цель "-" закрывает стандартный ввод: команда сразу получает конец файла.
+func duplicateInput(stdio *builtins.IO, fd int, target string) error {
+	if fd != 0 {
+		return fmt.Errorf("%d: bad file descriptor", fd)
+	}
+
+	switch target {
+	case closeDescriptor:
+		stdio.Stdin = strings.NewReader("")
+	case "0":
+		// Дескриптор уже указывает на стандартный ввод
+	default:
+		return fmt.Errorf("%s: bad file descriptor", target)
+	}
+	return nil
+}
+
+// setInput делает стандартным вводом текст here-document или here-string.
+func setInput(stdio *builtins.IO, fd int, text string) error {
+	if fd != 0 {
+		return fmt.Errorf("%d: bad file descriptor", fd)
+	}
+	stdio.Stdin = strings.NewReader(text)
+	return nil
+}
+
+// setOutput связывает дескриптор вывода fd (1 или 2) с writer.
+func setOutput(stdio *builtins.IO, fd int, writer io.Writer) {
+	if fd == 2 {
+		stdio.Stderr = writer
+	} else {
+		stdio.Stdout = writer
+	}
+}
+
+// isDescriptor проверяет, является ли цель перенаправления номером дескриптора.
+func isDescriptor(target string) bool {
+	_, err := strconv.Atoi(target)
+	return err == nil
+}
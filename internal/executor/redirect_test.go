@@ -0,0 +1,310 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"gocli/internal/parser"
+)
+
+// readFile читает содержимое файла, завершая тест при ошибке.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// word создает некавыченный аргумент для тестов.
+func word(value string) *parser.Argument {
+	return &parser.Argument{Value: value, Quoted: false, QuoteType: parser.NoQuote}
+}
+
+// command создает для тестов элемент списка из одной команды с некавыченными аргументами.
+func command(name string, args ...string) *parser.AndOr {
+	cmd := &parser.Command{Name: name}
+	for _, arg := range args {
+		cmd.Args = append(cmd.Args, word(arg))
+	}
+	return &parser.AndOr{Pipelines: []parser.Node{cmd}}
+}
+
+// list создает для тестов список из элементов.
+func list(items ...*parser.AndOr) *parser.List {
+	return &parser.List{Items: items}
+}
+
+// TestExecutor_RedirectOutput проверяет перенаправление вывода встроенной команды в файл
+// с усечением (>) и дозаписью (>>).
+func TestExecutor_RedirectOutput(t *testing.T) {
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	commands := []*parser.Command{
+		{
+			Name:      "echo",
+			Args:      []*parser.Argument{word("first")},
+			Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(out)}},
+		},
+		{
+			Name:      "echo",
+			Args:      []*parser.Argument{word("second")},
+			Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectAppend, Target: word(out)}},
+		},
+	}
+
+	for _, cmd := range commands {
+		if err := executor.Execute(cmd); err != nil {
+			t.Fatalf("Executor.Execute(%s) error = %v", cmd, err)
+		}
+	}
+
+	if got := readFile(t, out); got != "first\nsecond\n" {
+		t.Errorf("file content = %q, expected %q", got, "first\nsecond\n")
+	}
+
+	// Повторное > усекает файл
+	truncate := &parser.Command{
+		Name:      "echo",
+		Args:      []*parser.Argument{word("third")},
+		Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(out)}},
+	}
+	if err := executor.Execute(truncate); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+	if got := readFile(t, out); got != "third\n" {
+		t.Errorf("file content = %q, expected %q", got, "third\n")
+	}
+}
+
+// TestExecutor_RedirectInput проверяет чтение стандартного ввода из файла (<).
+func TestExecutor_RedirectInput(t *testing.T) {
+	executor := NewExecutor()
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.txt")
+	out := filepath.Join(dir, "out.txt")
+
+	if err := os.WriteFile(in, []byte("line1\nline2\n"), 0o644); err != nil {
+		t.Fatalf("cannot write input file: %v", err)
+	}
+
+	cmd := &parser.Command{
+		Name: "wc",
+		Redirects: []*parser.Redirect{
+			{Fd: 0, Op: parser.RedirectInput, Target: word(in)},
+			{Fd: 1, Op: parser.RedirectOutput, Target: word(out)},
+		},
+	}
+
+	if err := executor.Execute(cmd); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+
+	if got := readFile(t, out); got != "2 2 12\n" {
+		t.Errorf("wc output = %q, expected %q", got, "2 2 12\n")
+	}
+}
+
+// TestExecutor_RedirectStderr проверяет перенаправление stderr (2>) и дублирование 2>&1.
+func TestExecutor_RedirectStderr(t *testing.T) {
+	executor := NewExecutor()
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.txt")
+
+	errFile := filepath.Join(dir, "err.txt")
+	cmd := &parser.Command{
+		Name:      "cat",
+		Args:      []*parser.Argument{word(missing)},
+		Redirects: []*parser.Redirect{{Fd: 2, Op: parser.RedirectOutput, Target: word(errFile)}},
+	}
+	if err := executor.Execute(cmd); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if got := readFile(t, errFile); got == "" {
+		t.Error("expected cat error message in stderr file")
+	}
+
+	// >all 2>&1: оба потока попадают в один файл
+	allFile := filepath.Join(dir, "all.txt")
+	cmd = &parser.Command{
+		Name: "cat",
+		Args: []*parser.Argument{word(missing)},
+		Redirects: []*parser.Redirect{
+			{Fd: 1, Op: parser.RedirectOutput, Target: word(allFile)},
+			{Fd: 2, Op: parser.RedirectDupOutput, Target: word("1")},
+		},
+	}
+	if err := executor.Execute(cmd); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if got := readFile(t, allFile); got == "" {
+		t.Error("expected cat error message in combined output file")
+	}
+}
+
+// TestExecutor_RedirectAll проверяет перенаправление &> и &>> для обоих потоков.
+func TestExecutor_RedirectAll(t *testing.T) {
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "all.txt")
+
+	for _, op := range []parser.RedirectOp{parser.RedirectAll, parser.RedirectAppendAll} {
+		cmd := &parser.Command{
+			Name:      "echo",
+			Args:      []*parser.Argument{word("hello")},
+			Redirects: []*parser.Redirect{{Fd: 1, Op: op, Target: word(out)}},
+		}
+		if err := executor.Execute(cmd); err != nil {
+			t.Fatalf("Executor.Execute() error = %v", err)
+		}
+	}
+
+	if got := readFile(t, out); got != "hello\nhello\n" {
+		t.Errorf("file content = %q, expected %q", got, "hello\nhello\n")
+	}
+}
+
+// TestExecutor_RedirectOnly проверяет, что команда из одних перенаправлений создает файл.
+func TestExecutor_RedirectOnly(t *testing.T) {
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "empty.txt")
+
+	cmd := &parser.Command{
+		Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(out)}},
+	}
+	if err := executor.Execute(cmd); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("expected file to be created: %v", err)
+	}
+}
+
+// TestExecutor_RedirectErrors проверяет ошибки перенаправления.
+func TestExecutor_RedirectErrors(t *testing.T) {
+	executor := NewExecutor()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		redirect *parser.Redirect
+	}{
+		{"missing input file", &parser.Redirect{Fd: 0, Op: parser.RedirectInput, Target: word(filepath.Join(dir, "none"))}},
+		{"output into directory", &parser.Redirect{Fd: 1, Op: parser.RedirectOutput, Target: word(dir)}},
+		{"bad descriptor", &parser.Redirect{Fd: 2, Op: parser.RedirectDupOutput, Target: word("7")}},
+		{"unsupported descriptor", &parser.Redirect{Fd: 5, Op: parser.RedirectOutput, Target: word(filepath.Join(dir, "f"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &parser.Command{
+				Name:      "echo",
+				Args:      []*parser.Argument{word("hello")},
+				Redirects: []*parser.Redirect{tt.redirect},
+			}
+			if err := executor.Execute(cmd); err == nil {
+				t.Error("expected redirection error")
+			}
+		})
+	}
+}
+
+// TestExecutor_RedirectInPipeline проверяет перенаправления у команд пайплайна.
+func TestExecutor_RedirectInPipeline(t *testing.T) {
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	pipeline := &parser.Pipeline{
+		Commands: []*parser.Command{
+			{Name: "echo", Args: []*parser.Argument{word("hello")}},
+			{
+				Name:      "wc",
+				Redirects: []*parser.Redirect{{Fd: 1, Op: parser.RedirectOutput, Target: word(out)}},
+			},
+		},
+	}
+
+	if err := executor.Execute(pipeline); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+
+	if got := readFile(t, out); got != "1 1 6\n" {
+		t.Errorf("wc output = %q, expected %q", got, "1 1 6\n")
+	}
+}
+
+// TestExecutor_RedirectExternal проверяет перенаправления для внешних программ.
+func TestExecutor_RedirectExternal(t *testing.T) {
+	if _, err := osexec.LookPath("sh"); err != nil {
+		t.Skip("sh not found, skipping test")
+	}
+
+	executor := NewExecutor()
+	out := filepath.Join(t.TempDir(), "out.txt")
+
+	cmd := &parser.Command{
+		Name: "sh",
+		Args: []*parser.Argument{word("-c"), word("echo out; echo err >&2")},
+		Redirects: []*parser.Redirect{
+			{Fd: 1, Op: parser.RedirectOutput, Target: word(out)},
+			{Fd: 2, Op: parser.RedirectDupOutput, Target: word("1")},
+		},
+	}
+	if err := executor.Execute(cmd); err != nil {
+		t.Fatalf("Executor.Execute() error = %v", err)
+	}
+
+	if got := readFile(t, out); got != "out\nerr\n" {
+		t.Errorf("file content = %q, expected %q", got, "out\nerr\n")
+	}
+}
+
+// TestExecutor_RedirectHeredoc проверяет передачу here-document и here-string
+// в стандартный ввод встроенных команд и внешних программ.
+func TestExecutor_RedirectHeredoc(t *testing.T) {
+	executor := NewExecutor()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		command  string
+		args     []*parser.Argument
+		redirect *parser.Redirect
+		expected string
+	}{
+		{"heredoc to builtin", "cat", nil, &parser.Redirect{Fd: 0, Op: parser.RedirectHeredoc, Target: word("line1\nline2\n")}, "line1\nline2\n"},
+		{"here-string to builtin", "wc", nil, &parser.Redirect{Fd: 0, Op: parser.RedirectHereString, Target: word("a b")}, "1 2 4\n"},
+		{"heredoc to external", "sh", []*parser.Argument{word("-c"), word("cat")}, &parser.Redirect{Fd: 0, Op: parser.RedirectHeredoc, Target: word("body\n")}, "body\n"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.command == "sh" {
+				if _, err := osexec.LookPath("sh"); err != nil {
+					t.Skip("sh not found, skipping test")
+				}
+			}
+
+			out := filepath.Join(dir, fmt.Sprintf("out%d.txt", i))
+			cmd := &parser.Command{
+				Name: tt.command,
+				Args: tt.args,
+				Redirects: []*parser.Redirect{
+					tt.redirect,
+					{Fd: 1, Op: parser.RedirectOutput, Target: word(out)},
+				},
+			}
+			if err := executor.Execute(cmd); err != nil {
+				t.Fatalf("Executor.Execute() error = %v", err)
+			}
+			if got := readFile(t, out); got != tt.expected {
+				t.Errorf("output = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
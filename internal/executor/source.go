@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gocli/internal/builtins"
+	"gocli/internal/lexer"
+	"gocli/internal/parser"
+)
+
+// Имена команды выполнения файла в текущем shell'е.
+const (
+	sourceCommandName = "source"
+	dotCommandName    = "."
+)
+
+// maxSourceDepth ограничивает вложенность выполнения файлов командой source, чтобы файл,
+// выполняющий сам себя, завершался ошибкой, а не переполнением стека shell'а.
+const maxSourceDepth = 100
+
+// Source выполняет файл в текущем shell'е, как команда source (например, файл
+// инициализации ~/.goclirc). Путь используется как есть, без поиска в $PATH.
+// Код возврата сохраняется и доступен через LastStatus.
+func (exec *Executor) Source(path string) error {
+	status, err := exec.sourceFile(sourceCommandName, path, nil, newContext(builtins.NewIO()))
+	exec.lastStatus = status
+	return err
+}
+
+// executeSource выполняет команду source file [args...] (синоним - .): находит файл
+// (см. findSourceFile) и выполняет его команды в текущем shell'е.
+func (exec *Executor) executeSource(name string, args []string, ctx *execContext) (int, error) {
+	if len(args) == 0 {
+		return statusUsage, fmt.Errorf("%s: filename argument required", name)
+	}
+	return exec.sourceFile(name, exec.findSourceFile(args[0]), args[1:], ctx)
+}
+
+// sourceFile читает и выполняет файл в текущем shell'е: присваивания, функции
+// и смена каталога сохраняются после выполнения. Непустые args на время выполнения
+// становятся позиционными параметрами. return в файле завершает выполнение файла.
+// Код возврата - значение return или код последней команды файла.
+func (exec *Executor) sourceFile(name, path string, args []string, ctx *execContext) (int, error) {
+	if ctx.sources >= maxSourceDepth {
+		return statusFailure, fmt.Errorf("%s: %s: maximum source nesting level exceeded (%d)", name, path, maxSourceDepth)
+	}
+	data, err := os.ReadFile(builtins.ResolvePath(exec.environment.Dir(), path))
+	if err != nil {
+		return statusFailure, fmt.Errorf("%s: %w", name, err)
+	}
+	program, err := parser.NewIncrementalParser().Parse(string(data))
+	if err != nil {
+		return statusFailure, sourceError(path, err)
+	}
+
+	// Позиции ошибок, выводимых при выполнении файла, относятся к файлу
+	format := exec.formatError
+	exec.formatError = func(err error) string { return errorMessage(format, sourceError(path, err)) }
+	defer func() { exec.formatError = format }()
+
+	if len(args) > 0 {
+		positional := exec.environment.Positional()
+		exec.environment.SetPositional(args)
+		defer exec.environment.SetPositional(positional)
+	}
+
+	status, err := exec.executeNode(program, ctx.inSource())
+	var ret *functionReturn
+	if errors.As(err, &ret) {
+		if ret.status != 0 {
+			return ret.status, &ExitStatusError{Name: name, Code: ret.status}
+		}
+		return 0, nil
+	}
+	return status, sourceError(path, err)
+}
+
+// findSourceFile находит файл для source: имя со слешем - путь к файлу, иначе файл
+// ищется в каталогах $PATH, а если не найден там - в текущем каталоге, как в bash.
+func (exec *Executor) findSourceFile(name string) string {
+	if strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator) {
+		return name
+	}
+
+	path, _ := exec.environment.Get("PATH")
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(builtins.ResolvePath(exec.environment.Dir(), candidate)); err == nil && info.Mode().IsRegular() {
+			return candidate
+		}
+	}
+	return name
+}
+
+// sourceError добавляет к ошибке с позицией имя выполняемого файла. Позиция относится
+// к файлу, а не к вводу shell'а, поэтому она включается в сообщение как файл:строка:столбец,
+// а исходная строка ввода shell'а под сообщением не выводится.
+func sourceError(path string, err error) error {
+	var exit *ShellExit
+	if errors.As(err, &exit) {
+		if exit.Err == nil {
+			return err
+		}
+		return &ShellExit{Status: exit.Status, Err: sourceError(path, exit.Err)}
+	}
+
+	var posErr *lexer.PosError
+	if !errors.As(err, &posErr) || !posErr.Pos.IsValid() {
+		return err
+	}
+	return fmt.Errorf("%s:%s: %s", path, posErr.Pos, err.Error())
+}
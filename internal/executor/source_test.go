@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gocli/internal/builtins"
+	"gocli/internal/expander"
+	"gocli/internal/parser"
+)
+
+// TestExecutor_ExecuteSource проверяет выполнение файла командами source и . в текущем
+// shell'е: присваивания и функции сохраняются, аргументы доступны как позиционные
+// параметры на время выполнения, а return завершает выполнение файла.
+func TestExecutor_ExecuteSource(t *testing.T) {
+	dir := t.TempDir()
+	writeScript := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("cannot write %s: %v", path, err)
+		}
+		return path
+	}
+
+	vars := writeScript("vars.sh", "#!/usr/bin/env gocli\nx=sourced\ngreet() {\n  y=$1\n}\ngreet $1\n")
+	ret := writeScript("ret.sh", "z=before\nreturn 3\nz=after\n")
+	writeScript("lib.sh", "w=found\n")
+	bad := writeScript("bad.sh", "echo ok\necho a | | wc\n")
+	unset := writeScript("unset.sh", "echo ${UNSET:?unset}\necho ok\n")
+	self := filepath.Join(dir, "self.sh")
+	writeScript("self.sh", "source "+self+"\n")
+
+	executor := NewExecutor()
+	executor.SetExpander(expander.NewExpander(executor.environment))
+	executor.environment.SetPositional([]string{"outer"})
+	executor.environment.Set("PATH", dir)
+
+	// run выполняет команду и возвращает код возврата и stderr
+	run := func(name string, args ...string) (int, string) {
+		cmd := &parser.Command{Name: name}
+		for _, arg := range args {
+			cmd.Args = append(cmd.Args, word(arg))
+		}
+		var stderr bytes.Buffer
+		status := executeWithIO(executor, cmd, &builtins.IO{Stdin: strings.NewReader(""), Stdout: &bytes.Buffer{}, Stderr: &stderr})
+		return status, stderr.String()
+	}
+
+	if status, stderr := run("source", vars, "arg"); status != 0 || stderr != "" {
+		t.Fatalf("source status = %d, stderr = %q", status, stderr)
+	}
+	for name, expected := range map[string]string{"x": "sourced", "y": "arg"} {
+		if value, _ := executor.environment.Get(name); value != expected {
+			t.Errorf("%s = %q, expected %q", name, value, expected)
+		}
+	}
+	if _, exists := executor.environment.Function("greet"); !exists {
+		t.Error("function defined in sourced file should exist")
+	}
+	if positional := executor.environment.Positional(); len(positional) != 1 || positional[0] != "outer" {
+		t.Errorf("positional parameters after source = %v, expected [outer]", positional)
+	}
+
+	if status, _ := run(".", ret); status != 3 {
+		t.Errorf(". status = %d, expected 3", status)
+	}
+	if value, _ := executor.environment.Get("z"); value != "before" {
+		t.Errorf("z = %q, expected %q", value, "before")
+	}
+
+	// Имя без слеша ищется в $PATH
+	if status, stderr := run("source", "lib.sh"); status != 0 || stderr != "" {
+		t.Fatalf("source lib.sh status = %d, stderr = %q", status, stderr)
+	}
+	if value, _ := executor.environment.Get("w"); value != "found" {
+		t.Errorf("w = %q, expected %q", value, "found")
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+		wantStderr string
+	}{
+		{"missing argument", nil, 2, "Error: source: filename argument required\n"},
+		{"missing file", []string{filepath.Join(dir, "none.sh")}, 1, "no such file or directory"},
+		{"syntax error", []string{bad}, 1, bad + ":2:10: parsing failed: empty command before pipe\n"},
+		{"recursive source", []string{self}, 1, "source: " + self + ": maximum source nesting level exceeded (100)\n"},
+		{"error inside file", []string{unset}, 1, "Error: " + unset + ":1:6: variable expansion failed: failed to expand argument: UNSET: unset\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, stderr := run("source", tt.args...)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, expected %d", status, tt.wantStatus)
+			}
+			if !strings.Contains(stderr, tt.wantStderr) {
+				t.Errorf("stderr = %q, expected to contain %q", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	osexec "os/exec"
+
+	"gocli/internal/builtins"
+)
+
+// Коды возврата, которые shell назначает сам, как в POSIX shell.
+const (
+	statusFailure  = 1   // Общая ошибка (ошибка подстановки, перенаправления и т.п.)
+	statusUsage    = 2   // Неверное использование встроенной команды
+	statusNotFound = 127 // Команда не найдена
+)
+
+// ExitStatusError сообщает о завершении команды с ненулевым кодом возврата.
+// В отличие от прочих ошибок, она не означает сбоя самого shell:
+// команда отработала и лишь сообщила о неуспехе своим кодом возврата.
+type ExitStatusError struct {
+	Name string // Имя команды
+	Code int    // Код возврата
+}
+
+// Error возвращает текстовое описание ошибки.
+func (e *ExitStatusError) Error() string {
+	return fmt.Sprintf("command %s exited with code %d", e.Name, e.Code)
+}
+
+// exitStatus преобразует ошибку выполнения команды в код возврата.
+// Возвращает 0 для nil, код завершения процесса для внешних программ,
+// 127 для ненайденной программы и 1 для прочих ошибок.
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var statusErr *ExitStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code
+	}
+
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+
+	if errors.Is(err, osexec.ErrNotFound) {
+		return statusNotFound
+	}
+
+	return statusFailure
+}
+
+// IsStatusOnly проверяет, что ошибка лишь сообщает о ненулевом коде возврата команды
+// (ExitStatusError или код завершения внешней программы). О такой ошибке команда уже
+// сообщила сама, поэтому повторно она не выводится.
+func IsStatusOnly(err error) bool {
+	var statusErr *ExitStatusError
+	var exitErr *osexec.ExitError
+	return errors.As(err, &statusErr) || errors.As(err, &exitErr)
+}
+
+// ErrorFormatter возвращает сообщение об ошибке для вывода в stderr (с переводом строки).
+type ErrorFormatter func(err error) string
+
+// SetErrorFormatter задает формат сообщений об ошибках, которые исполнитель выводит сам
+// (ошибки промежуточных команд списков, тел циклов, if, case и функций, фоновых заданий).
+// Shell передает тот же формат, что и для ошибок, возвращаемых Execute: с позицией
+// ошибки и исходной строкой. Без него ошибка выводится как "Error: сообщение".
+func (exec *Executor) SetErrorFormatter(format ErrorFormatter) {
+	exec.formatError = format
+}
+
+// reportError выводит в stderr ошибку команды, результат которой не возвращается
+// вызывающему коду (например, промежуточного элемента списка cmd1; cmd2).
+// Ненулевой код возврата сам по себе не выводится: команда уже сообщила о проблеме
+// сама, а код возврата используется операторами && и ||. Сигналы break, continue
+// и return ошибками не являются и также не выводятся, а для сигнала завершения
+// shell'а выводится завершившая его ошибка.
+func (exec *Executor) reportError(err error, stdio *builtins.IO) {
+	var exit *ShellExit
+	if errors.As(err, &exit) {
+		err = exit.Err
+	}
+	if err == nil || IsStatusOnly(err) || isControlFlow(err) {
+		return
+	}
+	fmt.Fprint(stdio.Stderr, errorMessage(exec.formatError, err))
+}
+
+// errorMessage возвращает сообщение об ошибке в формате format (или "Error: сообщение").
+func errorMessage(format ErrorFormatter, err error) string {
+	if format == nil {
+		return fmt.Sprintf("Error: %v\n", err)
+	}
+	return format(err)
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+
+	"gocli/internal/builtins"
+	"gocli/internal/expander"
+	"gocli/internal/parser"
+)
+
+// executeSubshell выполняет подоболочку ( list ) (см. runSubshell).
+// Циклы и функции вокруг подоболочки видны в ней, но break, continue и return
+// завершают только саму подоболочку.
+func (exec *Executor) executeSubshell(node *parser.Subshell, ctx *execContext) (int, error) {
+	return exec.runSubshell(func(child *Executor) (int, error) {
+		return child.executeNode(node.Body, ctx)
+	})
+}
+
+// runSubshell выполняет run исполнителем подоболочки: с копией окружения, поэтому
+// присваивания, функции и cd внутри не видны снаружи. Рабочий каталог хранится
+// в окружении, а не в процессе, поэтому cd в подоболочке не влияет и на команды,
+// выполняющиеся одновременно с ней (в пайплайне или в фоне).
+func (exec *Executor) runSubshell(run func(child *Executor) (int, error)) (int, error) {
+	return subshellResult(run(exec.subshell()))
+}
+
+// subshellResult преобразует результат выполнения в подоболочке в результат
+// для вызывающего shell'а: exit, break, continue и return завершают только подоболочку.
+func subshellResult(status int, err error) (int, error) {
+	// exit в подоболочке завершает только ее; ошибка, завершившая подоболочку,
+	// передается вызывающему коду для вывода
+	var exit *ShellExit
+	if errors.As(err, &exit) {
+		if exit.Err != nil {
+			return exit.Status, exit.Err
+		}
+		if exit.Status != 0 {
+			return exit.Status, &ExitStatusError{Name: builtins.ExitCommandName, Code: exit.Status}
+		}
+		return 0, nil
+	}
+	if isControlFlow(err) {
+		err = nil
+	}
+	return status, err
+}
+
+// subshell создает исполнитель подоболочки: с копией окружения, встроенными командами
+// и expander'ом, работающими с этой копией, и с общей таблицей фоновых заданий.
+// Подоболочка не интерактивна: о запуске фоновых заданий в ней не сообщается.
+func (exec *Executor) subshell() *Executor {
+	child := &Executor{
+		registry:    exec.registry.Clone(),
+		environment: exec.environment.Clone(),
+		jobs:        exec.jobs,
+		formatError: exec.formatError,
+	}
+	child.registerEnvironmentBuiltins()
+	child.registerSpecialBuiltins()
+	if exec.expander != nil {
+		child.SetExpander(expander.NewExpander(child.environment))
+	}
+	return child
+}
+
+// substitutionName - имя подстановки команды в сообщениях об ошибках.
+const substitutionName = "$(...)"
+
+// runSubstitution выполняет командную строку подстановки $(...) или `...` в подоболочке
+// и возвращает её стандартный вывод и код возврата. Ошибки разбора возвращаются
+// вызывающему коду, ошибки выполнения выводятся в stderr, а вывод команды подставляется
+// в любом случае, как в POSIX shell.
+func (exec *Executor) runSubstitution(command string) (string, int, error) {
+	program, err := parser.NewIncrementalParser().Parse(command)
+	if err != nil {
+		return "", statusUsage, err
+	}
+
+	var output bytes.Buffer
+	stdio := builtins.NewIO()
+	stdio.Stdout = &output
+	status, _ := exec.runSubshell(func(child *Executor) (int, error) {
+		// Позиции в тексте подстановки не совпадают с позициями во вводе shell'а,
+		// поэтому ошибки выводятся без позиции
+		child.formatError = nil
+		status, err := child.executeNode(program, newContext(stdio))
+		child.reportError(err, stdio)
+		return status, err
+	})
+
+	return output.String(), status, nil
+}
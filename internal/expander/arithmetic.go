@@ -0,0 +1,52 @@
+package expander
+
+import (
+	"fmt"
+	"strconv"
+
+	"gocli/internal/arith"
+)
+
+// expandArithmetic выполняет арифметическую подстановку $((выражение)).
+// Перед вычислением в выражении выполняются подстановки переменных и команд,
+// поэтому переменные можно указывать как с $, так и без него.
+// Возвращает значение выражения в десятичной записи.
+func (e *Expander) expandArithmetic(expr string) (string, error) {
+	value, err := e.EvaluateArithmetic(expr)
+	if err != nil {
+		return "", fmt.Errorf("arithmetic expansion failed: %w", err)
+	}
+	return strconv.FormatInt(value, 10), nil
+}
+
+// EvaluateArithmetic вычисляет арифметическое выражение так же, как $((выражение)),
+// и возвращает его значение. Используется для заголовка цикла for ((...)).
+func (e *Expander) EvaluateArithmetic(expr string) (int64, error) {
+	expanded, err := e.expandString(expr, true)
+	if err != nil {
+		return 0, err
+	}
+	return arith.Evaluate(expanded, e.environment)
+}
+
+// isArithmetic проверяет, что содержимое $(...) целиком заключено в скобки,
+// то есть подстановка имеет вид $((выражение)), а не $((cmd1) | (cmd2)).
+func isArithmetic(inner string) bool {
+	if len(inner) < 2 || inner[0] != '(' || inner[len(inner)-1] != ')' {
+		return false
+	}
+
+	depth := 0
+	for i := 0; i < len(inner); i++ {
+		switch inner[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(inner)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
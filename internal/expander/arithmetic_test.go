@@ -0,0 +1,73 @@
+package expander
+
+import (
+	"errors"
+	"testing"
+
+	"gocli/internal/arith"
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// TestExpander_ExpandArithmetic проверяет арифметическую подстановку $((...)):
+// переменные с $ и без него, присваивания в окружение и вложенные подстановки.
+func TestExpander_ExpandArithmetic(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("x", "5")
+	exp := NewExpander(env)
+	exp.SetCommandRunner(func(string) (string, int, error) { return "3\n", 0, nil })
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"simple", "$((1 + 2 * 3))", "7"},
+		{"variable without dollar", "$((x * 2))", "10"},
+		{"variable with dollar", "$(( $x ** 2 ))", "25"},
+		{"text around", "n=$((x - 1))!", "n=4!"},
+		{"command substitution inside", "$(( $(cmd) + 1 ))", "4"},
+		{"nested parens", "$(( (1 + 2) * (3 + 4) ))", "21"},
+		{"assignment", "$((y = x + 1))", "6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := exp.expandArgument(&parser.Argument{Value: tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expanded.Value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, expanded.Value)
+			}
+		})
+	}
+
+	if value, _ := env.Get("y"); value != "6" {
+		t.Errorf("y = %q after assignment in $((...)), expected %q", value, "6")
+	}
+
+	if _, err := exp.expandArgument(&parser.Argument{Value: "$((x / 0))"}); !errors.Is(err, arith.ErrDivisionByZero) {
+		t.Errorf("expected division by zero error, got %v", err)
+	}
+}
+
+// TestIsArithmetic проверяет отличие $((выражение)) от подстановки команды с подоболочками.
+func TestIsArithmetic(t *testing.T) {
+	tests := []struct {
+		inner    string
+		expected bool
+	}{
+		{"(1 + 2)", true},
+		{"((1) + (2))", true},
+		{"(a) | (b)", false},
+		{"echo hi", false},
+		{"()", true},
+	}
+
+	for _, tt := range tests {
+		if got := isArithmetic(tt.inner); got != tt.expected {
+			t.Errorf("isArithmetic(%q) = %v, expected %v", tt.inner, got, tt.expected)
+		}
+	}
+}
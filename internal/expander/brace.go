@@ -0,0 +1,290 @@
+package expander
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gocli/internal/lexer"
+	"gocli/internal/parser"
+)
+
+// expandBraces выполняет раскрытие фигурных скобок в слове, как в bash:
+//   - {a,b,c} - список вариантов, в том числе вложенных (a{b,c{d,e}});
+//   - {1..10}, {10..1..2} - числовые последовательности с необязательным шагом;
+//   - {01..10} - числа дополняются нулями до одинаковой ширины;
+//   - {a..e}, {a..z..3} - последовательности символов.
+//
+// Каждый вариант комбинируется с текстом до и после скобок, поэтому
+// build/{debug,release}/{bin,lib} дает четыре слова. Скобки без запятой
+// и без корректной последовательности (например, {x} или {}) остаются буквальными.
+// Подстановки ${...}, $(...) и `...` не раскрываются.
+func expandBraces(word string) []string {
+	open, close, alternatives := findBraceExpression(word)
+	if open == -1 {
+		return []string{word}
+	}
+
+	preamble := word[:open]
+	postambles := expandBraces(word[close+1:])
+
+	var result []string
+	for _, alternative := range alternatives {
+		for _, expanded := range expandBraces(alternative) {
+			for _, postamble := range postambles {
+				result = append(result, preamble+expanded+postamble)
+			}
+		}
+	}
+	return result
+}
+
+// expandWordBraces раскрывает фигурные скобки в слове, состоящем из частей.
+// Скобки, запятые и последовательности распознаются только в частях без кавычек,
+// поэтому {a,"b c"} дает слова a и "b c", а "{a,b}" остается буквальным.
+// Для раскрытия части в кавычках временно заменяются экранированными символами-метками,
+// которые expandBraces пропускает, а затем восстанавливаются в результатах.
+func expandWordBraces(parts []parser.WordPart) [][]parser.WordPart {
+	var word strings.Builder
+	hasBrace := false
+	for i, part := range parts {
+		if part.QuoteType != parser.NoQuote {
+			word.WriteByte('\\')
+			word.WriteRune(partMarker + rune(i))
+			continue
+		}
+		word.WriteString(part.Value)
+		hasBrace = hasBrace || strings.Contains(part.Value, "{")
+	}
+	if !hasBrace || len(parts) > maxMarkedParts {
+		return [][]parser.WordPart{parts}
+	}
+
+	expanded := expandBraces(word.String())
+	words := make([][]parser.WordPart, 0, len(expanded))
+	for _, w := range expanded {
+		words = append(words, decodeWordParts(w, parts))
+	}
+	return words
+}
+
+// partMarker - первый символ-метка частей слова в кавычках (область Unicode
+// для частного использования); maxMarkedParts - число доступных меток.
+const (
+	partMarker     = '\uE000'
+	maxMarkedParts = 0x1000
+)
+
+// decodeWordParts восстанавливает части слова из строки с метками частей в кавычках.
+func decodeWordParts(word string, parts []parser.WordPart) []parser.WordPart {
+	var result []parser.WordPart
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			result = append(result, parser.WordPart{Value: text.String(), QuoteType: parser.NoQuote})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(word); {
+		if word[i] == '\\' && i+1 < len(word) {
+			r, size := utf8.DecodeRuneInString(word[i+1:])
+			if r >= partMarker && r < partMarker+maxMarkedParts && int(r-partMarker) < len(parts) {
+				flush()
+				result = append(result, parts[r-partMarker])
+			} else {
+				text.WriteString(word[i : i+1+size])
+			}
+			i += 1 + size
+			continue
+		}
+		text.WriteByte(word[i])
+		i++
+	}
+	flush()
+	return result
+}
+
+// findBraceExpression ищет первое раскрываемое выражение в фигурных скобках.
+// Возвращает позиции открывающей и закрывающей скобок и список вариантов,
+// либо -1, если раскрываемых скобок в слове нет.
+func findBraceExpression(word string) (int, int, []string) {
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '$', '`':
+			i = skipSubstitution(word, i) - 1
+		case '{':
+			close, commas := matchBrace(word, i)
+			if close == -1 {
+				continue
+			}
+			content := word[i+1 : close]
+			if len(commas) > 0 {
+				return i, close, splitAlternatives(content, i+1, commas)
+			}
+			if sequence, ok := expandSequence(content); ok {
+				return i, close, sequence
+			}
+		}
+	}
+	return -1, -1, nil
+}
+
+// matchBrace ищет закрывающую скобку для открывающей скобки в позиции open.
+// Возвращает её позицию (или -1) и позиции запятых верхнего уровня внутри скобок.
+func matchBrace(word string, open int) (int, []int) {
+	depth := 0
+	var commas []int
+
+	for i := open + 1; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '$', '`':
+			i = skipSubstitution(word, i) - 1
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i, commas
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				commas = append(commas, i)
+			}
+		}
+	}
+	return -1, nil
+}
+
+// skipSubstitution возвращает позицию после подстановки ${...}, $(...) или `...`,
+// начинающейся с word[i]. Если в позиции i подстановки нет, возвращает i+1.
+func skipSubstitution(word string, i int) int {
+	if word[i] == '$' && (i+1 >= len(word) || (word[i+1] != '{' && word[i+1] != '(')) {
+		return i + 1
+	}
+	end, err := lexer.SubstitutionEnd(word, i)
+	if err != nil {
+		return i + 1
+	}
+	return end
+}
+
+// splitAlternatives разбивает содержимое скобок на варианты по запятым верхнего уровня.
+// offset - позиция начала содержимого в слове, commas - позиции запятых в слове.
+func splitAlternatives(content string, offset int, commas []int) []string {
+	alternatives := make([]string, 0, len(commas)+1)
+	start := 0
+	for _, comma := range commas {
+		alternatives = append(alternatives, content[start:comma-offset])
+		start = comma - offset + 1
+	}
+	return append(alternatives, content[start:])
+}
+
+// expandSequence раскрывает последовательность x..y или x..y..step.
+// Границы должны быть либо целыми числами, либо одиночными символами.
+// Возвращает false, если содержимое скобок не является последовательностью.
+func expandSequence(content string) ([]string, bool) {
+	parts := strings.Split(content, "..")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, false
+	}
+
+	step := int64(1)
+	if len(parts) == 3 {
+		parsed, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		step = parsed
+	}
+	if step < 0 {
+		step = -step
+	}
+	if step == 0 {
+		step = 1
+	}
+
+	start, errStart := strconv.ParseInt(parts[0], 10, 64)
+	end, errEnd := strconv.ParseInt(parts[1], 10, 64)
+	if errStart == nil && errEnd == nil {
+		width := 0
+		if hasLeadingZero(parts[0]) || hasLeadingZero(parts[1]) {
+			width = max(len(parts[0]), len(parts[1]))
+		}
+		return numericSequence(start, end, step, width), true
+	}
+
+	if len(parts[0]) == 1 && len(parts[1]) == 1 && !isDigit(parts[0][0]) && !isDigit(parts[1][0]) {
+		return characterSequence(parts[0][0], parts[1][0], step), true
+	}
+	return nil, false
+}
+
+// numericSequence формирует числовую последовательность от start до end с шагом step.
+// Если width больше нуля, числа дополняются нулями до этой ширины (знак входит в ширину).
+func numericSequence(start, end, step int64, width int) []string {
+	var result []string
+	for n := start; ; {
+		result = append(result, padNumber(n, width))
+		if start <= end {
+			if n > end-step {
+				break
+			}
+			n += step
+		} else {
+			if n < end+step {
+				break
+			}
+			n -= step
+		}
+	}
+	return result
+}
+
+// characterSequence формирует последовательность символов от start до end с шагом step.
+func characterSequence(start, end byte, step int64) []string {
+	var result []string
+	for c := int64(start); ; {
+		result = append(result, string(rune(c)))
+		if start <= end {
+			c += step
+			if c > int64(end) {
+				break
+			}
+		} else {
+			c -= step
+			if c < int64(end) {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// padNumber форматирует число, дополняя его нулями до ширины width.
+func padNumber(n int64, width int) string {
+	s := strconv.FormatInt(n, 10)
+	if len(s) >= width {
+		return s
+	}
+	if n < 0 {
+		return "-" + strings.Repeat("0", width-len(s)) + s[1:]
+	}
+	return strings.Repeat("0", width-len(s)) + s
+}
+
+// hasLeadingZero проверяет, записано ли число с ведущим нулем (01, -05).
+func hasLeadingZero(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return len(s) > 1 && s[0] == '0'
+}
+
+// isDigit проверяет, является ли байт десятичной цифрой.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
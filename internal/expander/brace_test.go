@@ -0,0 +1,97 @@
+package expander
+
+import (
+	"reflect"
+	"testing"
+
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// TestExpandBraces проверяет раскрытие фигурных скобок в слове.
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected []string
+	}{
+		{"a{b,c}d", []string{"abd", "acd"}},
+		{"{a,b,c}", []string{"a", "b", "c"}},
+		{"build/{debug,release}/{bin,lib}", []string{"build/debug/bin", "build/debug/lib", "build/release/bin", "build/release/lib"}},
+		{"a{b,c{d,e}}f", []string{"abf", "acdf", "acef"}},
+		{"x{a,}", []string{"xa", "x"}},
+		{"{1..5}", []string{"1", "2", "3", "4", "5"}},
+		{"{5..1}", []string{"5", "4", "3", "2", "1"}},
+		{"{1..10..3}", []string{"1", "4", "7", "10"}},
+		{"{10..1..-4}", []string{"10", "6", "2"}},
+		{"{-2..2}", []string{"-2", "-1", "0", "1", "2"}},
+		{"{01..03}", []string{"01", "02", "03"}},
+		{"{8..010}", []string{"008", "009", "010"}},
+		{"{-1..01}", []string{"-1", "00", "01"}},
+		{"{a..e}", []string{"a", "b", "c", "d", "e"}},
+		{"{e..a..2}", []string{"e", "c", "a"}},
+		{"file{1..2}.{txt,log}", []string{"file1.txt", "file1.log", "file2.txt", "file2.log"}},
+		{"{x}", []string{"{x}"}},
+		{"{}", []string{"{}"}},
+		{"{x}{a,b}", []string{"{x}a", "{x}b"}},
+		{"{x{a,b}}", []string{"{xa}", "{xb}"}},
+		{"{a,b", []string{"{a,b"}},
+		{"{1..a}", []string{"{1..a}"}},
+		{"{1..2..x}", []string{"{1..2..x}"}},
+		{`\{a,b}`, []string{`\{a,b}`}},
+		{`{a\,b}`, []string{`{a\,b}`}},
+		{"${VAR}", []string{"${VAR}"}},
+		{"${VAR,,}{1,2}", []string{"${VAR,,}1", "${VAR,,}2"}},
+		{"$(echo {a,b})", []string{"$(echo {a,b})"}},
+		{"plain", []string{"plain"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := expandBraces(tt.word); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expandBraces(%q) = %q, expected %q", tt.word, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestExpander_ExpandBracesInArguments проверяет, что скобки раскрываются до подстановки
+// переменных и только в аргументах без кавычек.
+func TestExpander_ExpandBracesInArguments(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("DIR", "out")
+	env.Set("LIST", "{a,b}")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name     string
+		arg      *parser.Argument
+		expected []string
+	}{
+		{"with variable", &parser.Argument{Value: "$DIR/{x,y}"}, []string{"out/x", "out/y"}},
+		{"braces from variable are literal", &parser.Argument{Value: "$LIST"}, []string{"{a,b}"}},
+		{"double quoted", &parser.Argument{Value: "{a,b}", Quoted: true, QuoteType: parser.DoubleQuote}, []string{"{a,b}"}},
+		{"single quoted", &parser.Argument{Value: "{1..3}", Quoted: true, QuoteType: parser.SingleQuote}, []string{"{1..3}"}},
+		{"empty alternatives dropped", &parser.Argument{Value: "{,a,}"}, []string{"a"}},
+		{"quoted alternative", compositeArgument(
+			parser.WordPart{Value: "{a,"},
+			parser.WordPart{Value: "b c", QuoteType: parser.DoubleQuote},
+			parser.WordPart{Value: "}d"},
+		), []string{"ad", "b cd"}},
+		{"quoted braces in composite word", compositeArgument(
+			parser.WordPart{Value: "x"},
+			parser.WordPart{Value: "{a,b}", QuoteType: parser.SingleQuote},
+		), []string{"x{a,b}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := exp.expandArgumentFields(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := argumentValues(args); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -2,17 +2,28 @@ package expander
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode"
 
 	"gocli/internal/environment"
+	"gocli/internal/glob"
+	"gocli/internal/lexer"
 	"gocli/internal/parser"
 )
 
+// CommandRunner выполняет командную строку и возвращает её стандартный вывод
+// и код возврата. Используется для подстановки команд $(...) и `...`.
+type CommandRunner func(command string) (string, int, error)
+
 // Expander выполняет подстановку переменных и обработку кавычек.
 // Преобразует AST с переменными в окончательные аргументы команд.
 type Expander struct {
 	environment *environment.Environment
+	runner      CommandRunner // Выполнение команд для подстановки $(...) и `...`
+
+	substitutionStatus int  // Код возврата последней подстановки команды
+	substituted        bool // В последней раскрытой команде была подстановка команды
 }
 
 // NewExpander создает новый экземпляр expander.
@@ -22,6 +33,12 @@ func NewExpander(env *environment.Environment) *Expander {
 	}
 }
 
+// SetCommandRunner устанавливает функцию выполнения команд для подстановки $(...) и `...`.
+// Без неё подстановка команд завершается ошибкой.
+func (e *Expander) SetCommandRunner(runner CommandRunner) {
+	e.runner = runner
+}
+
 // Expand выполняет подстановку переменных в AST.
 // Обрабатывает подстановки $VAR в аргументах команд и присваиваниях.
 // Возвращает расширенный AST и ошибку при некорректных подстановках.
@@ -36,66 +53,199 @@ func (e *Expander) Expand(node parser.Node) (parser.Node, error) {
 	}
 }
 
+// ExpandCommand выполняет подстановку переменных в отдельной команде.
+// Используется Executor для подстановки непосредственно перед запуском команды.
+// Код возврата последней подстановки команды в ней доступен через SubstitutionStatus.
+func (e *Expander) ExpandCommand(cmd *parser.Command) (*parser.Command, error) {
+	e.substituted = false
+	return e.expandCommand(cmd)
+}
+
+// SubstitutionStatus возвращает код возврата последней подстановки команды $(...)
+// или `...` в команде, раскрытой последним вызовом ExpandCommand, и флаг, что такая
+// подстановка была. Команда только из присваиваний получает этот код, как в POSIX shell:
+// после x=$(false) значение $? равно 1.
+func (e *Expander) SubstitutionStatus() (int, bool) {
+	return e.substitutionStatus, e.substituted
+}
+
+// ExpandWords раскрывает список слов так же, как аргументы команды: с раскрытием скобок,
+// разбиением на поля и шаблонами имен файлов. Используется для слов цикла for.
+func (e *Expander) ExpandWords(words []*parser.Argument) ([]string, error) {
+	var result []string
+	for _, word := range words {
+		fields, err := e.expandArgumentFields(word)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			result = append(result, field.Value)
+		}
+	}
+	return result, nil
+}
+
+// ExpandWord раскрывает слово без разбиения на поля и раскрытия шаблонов
+// (как слово конструкции case): выполняются подстановки, тильда и снятие кавычек.
+func (e *Expander) ExpandWord(word *parser.Argument) (string, error) {
+	expanded, err := e.expandArgument(word)
+	if err != nil {
+		return "", err
+	}
+	return expanded.Value, nil
+}
+
+// ExpandPattern раскрывает шаблон ветви case. Подстановки выполняются, как в слове,
+// но результат остается шаблоном для glob.Match: части в кавычках экранируются
+// и сравниваются буквально, а метасимволы *, ? и [...] вне кавычек, в том числе
+// из значений переменных без кавычек, сохраняют свой смысл.
+func (e *Expander) ExpandPattern(pattern *parser.Argument) (string, error) {
+	var result strings.Builder
+	for _, part := range e.expandWordTilde(pattern.WordParts()) {
+		switch part.QuoteType {
+		case parser.SingleQuote:
+			result.WriteString(glob.QuoteMeta(part.Value))
+		case parser.DoubleQuote:
+			expanded, err := e.expandString(part.Value, true)
+			if err != nil {
+				return "", wordError(pattern, err)
+			}
+			result.WriteString(glob.QuoteMeta(expanded))
+		default:
+			expanded, err := e.expandPattern(part.Value)
+			if err != nil {
+				return "", wordError(pattern, err)
+			}
+			result.WriteString(expanded)
+		}
+	}
+	return result.String(), nil
+}
+
+// ExpandRegex раскрывает правый операнд оператора =~ в [[ ]] в регулярное выражение.
+// Подстановки выполняются, как в слове; части в кавычках сопоставляются буквально,
+// а текст без кавычек, в том числе значения переменных без кавычек, остается
+// регулярным выражением.
+func (e *Expander) ExpandRegex(word *parser.Argument) (string, error) {
+	var result strings.Builder
+	for _, part := range e.expandWordTilde(word.WordParts()) {
+		switch part.QuoteType {
+		case parser.SingleQuote:
+			result.WriteString(regexp.QuoteMeta(part.Value))
+		case parser.DoubleQuote:
+			expanded, err := e.expandString(part.Value, true)
+			if err != nil {
+				return "", wordError(word, err)
+			}
+			result.WriteString(regexp.QuoteMeta(expanded))
+		default:
+			expanded, err := e.expandPattern(part.Value)
+			if err != nil {
+				return "", wordError(word, err)
+			}
+			result.WriteString(expanded)
+		}
+	}
+	return result.String(), nil
+}
+
 // expandCommand выполняет подстановку переменных в команде.
 // Сначала расширяет assignments и устанавливает их в окружение временно,
 // затем расширяет имя команды и аргументы (которые могут использовать эти переменные).
-// Переменные остаются в окружении для Executor, но в пайплайнах они изолированы.
+// Имя раскрывается так же, как аргументы: $CMD со значением "ls -l" дает команду ls
+// с аргументом -l, а имя, раскрывшееся в пустоту, уступает место следующему слову.
+// После расширения окружение возвращается в исходное состояние:
+// Executor сам устанавливает переменные из assignments на время выполнения команды.
 func (e *Expander) expandCommand(cmd *parser.Command) (*parser.Command, error) {
 	expandedCmd := &parser.Command{
-		Name:        cmd.Name,
+		Position:    cmd.Position,
+		Compound:    cmd.Compound,
 		Args:        make([]*parser.Argument, 0, len(cmd.Args)),
 		Assignments: make([]*parser.Assignment, 0, len(cmd.Assignments)),
+		Redirects:   make([]*parser.Redirect, 0, len(cmd.Redirects)),
 	}
 
 	// Сохраняем состояние переменных для восстановления после расширения
 	savedVars := make(map[string]string)
 	for _, assignment := range cmd.Assignments {
-		if value, exists := e.environment.Get(assignment.Name); exists {
+		if value, exists := e.environment.GetLocal(assignment.Name); exists {
 			savedVars[assignment.Name] = value
 		}
 	}
+	defer e.restoreVars(cmd.Assignments, savedVars)
 
 	// Сначала расширяем присваивания и устанавливаем их в окружение
 	// Это позволяет использовать переменные из assignments в аргументах команды
 	for _, assignment := range cmd.Assignments {
-		expandedValue, err := e.expandArgument(assignment.Value)
+		expandedValue, err := e.expandAssignmentValue(assignment.Value)
 		if err != nil {
-			e.restoreVars(savedVars)
 			return nil, fmt.Errorf("failed to expand assignment %s: %w", assignment.Name, err)
 		}
 		e.environment.Set(assignment.Name, expandedValue.Value)
 		expandedCmd.Assignments = append(expandedCmd.Assignments, &parser.Assignment{
-			Name:  assignment.Name,
-			Value: expandedValue,
+			Name:     assignment.Name,
+			Value:    expandedValue,
+			Position: assignment.Position,
 		})
 	}
 
-	// Расширяем имя команды и аргументы
-	expandedName, err := e.expandString(cmd.Name)
-	if err != nil {
-		e.restoreVars(savedVars)
-		return nil, fmt.Errorf("failed to expand command name: %w", err)
+	// Расширяем имя команды и аргументы; аргументы-шаблоны раскрываются в списки путей
+	words := cmd.Args
+	if nameArg := commandNameArg(cmd); nameArg != nil {
+		words = append([]*parser.Argument{nameArg}, cmd.Args...)
 	}
-	expandedCmd.Name = expandedName
-
-	for _, arg := range cmd.Args {
-		expandedArg, err := e.expandArgument(arg)
+	for i, word := range words {
+		fields, err := e.expandArgumentFields(word)
 		if err != nil {
-			e.restoreVars(savedVars)
+			if i == 0 && len(words) > len(cmd.Args) {
+				return nil, fmt.Errorf("failed to expand command name: %w", err)
+			}
 			return nil, fmt.Errorf("failed to expand argument: %w", err)
 		}
-		expandedCmd.Args = append(expandedCmd.Args, expandedArg)
+		expandedCmd.Args = append(expandedCmd.Args, fields...)
+	}
+	if len(expandedCmd.Args) > 0 {
+		expandedCmd.Name = expandedCmd.Args[0].Value
+		expandedCmd.Args = expandedCmd.Args[1:]
+	}
+
+	for _, redirect := range cmd.Redirects {
+		expandedTarget, err := e.expandArgument(redirect.Target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand redirection target: %w", err)
+		}
+		expandedCmd.Redirects = append(expandedCmd.Redirects, &parser.Redirect{
+			Fd:       redirect.Fd,
+			Op:       redirect.Op,
+			Target:   expandedTarget,
+			Position: redirect.Position,
+		})
 	}
 
-	// Переменные остаются в окружении для Executor
-	// В пайплайнах они будут откатены в expandPipeline
 	return expandedCmd, nil
 }
 
-// restoreVars восстанавливает сохраненные переменные в окружении.
-func (e *Expander) restoreVars(savedVars map[string]string) {
-	for name, value := range savedVars {
-		e.environment.Set(name, value)
+// commandNameArg возвращает слово имени команды. Для команд, построенных без парсера
+// (только с Name), имя считается словом без кавычек.
+func commandNameArg(cmd *parser.Command) *parser.Argument {
+	if cmd.NameArg != nil {
+		return cmd.NameArg
+	}
+	if cmd.Name != "" {
+		return &parser.Argument{Value: cmd.Name, QuoteType: parser.NoQuote}
+	}
+	return nil
+}
+
+// restoreVars восстанавливает состояние переменных из assignments после расширения.
+// Переменные, которых не было в локальном окружении, удаляются.
+func (e *Expander) restoreVars(assignments []*parser.Assignment, savedVars map[string]string) {
+	for _, assignment := range assignments {
+		if value, wasSaved := savedVars[assignment.Name]; wasSaved {
+			e.environment.Set(assignment.Name, value)
+		} else {
+			e.environment.Unset(assignment.Name)
+		}
 	}
 }
 
@@ -104,185 +254,244 @@ func (e *Expander) restoreVars(savedVars map[string]string) {
 func (e *Expander) expandPipeline(pipeline *parser.Pipeline) (*parser.Pipeline, error) {
 	expandedPipeline := &parser.Pipeline{
 		Commands: make([]*parser.Command, 0, len(pipeline.Commands)),
+		Position: pipeline.Position,
 	}
 
 	for _, cmd := range pipeline.Commands {
-		// Сохраняем состояние переменных перед расширением команды
-		savedVars := make(map[string]string)
-		for _, assignment := range cmd.Assignments {
-			if value, exists := e.environment.Get(assignment.Name); exists {
-				savedVars[assignment.Name] = value
-			}
-		}
-
 		expandedCmd, err := e.expandCommand(cmd)
 		if err != nil {
 			return nil, err
 		}
-
-		// Откатываем переменные этой команды, чтобы они не влияли на следующие команды
-		// Executor установит их снова при выполнении
-		for _, assignment := range expandedCmd.Assignments {
-			if _, wasSaved := savedVars[assignment.Name]; wasSaved {
-				e.environment.Set(assignment.Name, savedVars[assignment.Name])
-			} else {
-				e.environment.Unset(assignment.Name)
-			}
-		}
-
 		expandedPipeline.Commands = append(expandedPipeline.Commands, expandedCmd)
 	}
 
 	return expandedPipeline, nil
 }
 
-// expandArgument выполняет подстановку переменных в аргументе.
-// Учитывает тип кавычек: одинарные кавычки не расширяются, двойные - расширяются.
+// expandArgument выполняет подстановку переменных в аргументе и возвращает одно слово.
+// Учитывает тип кавычек каждой части: одинарные кавычки не расширяются, двойные - расширяются,
+// тильда раскрывается только в начале слова без кавычек. Разбиение на поля
+// и раскрытие шаблонов не выполняются (используется для целей перенаправлений).
 func (e *Expander) expandArgument(arg *parser.Argument) (*parser.Argument, error) {
-	// Если аргумент был в одинарных кавычках, подстановки не выполняются
-	if arg.QuoteType == parser.SingleQuote {
-		return &parser.Argument{
-			Value:     arg.Value,
-			Quoted:    false, // После обработки кавычки убираются
-			QuoteType: parser.NoQuote,
-		}, nil
-	}
-
-	// Для двойных кавычек и некавыченных аргументов выполняем подстановки
-	expanded, err := e.expandString(arg.Value)
+	expanded, err := e.expandWord(e.expandWordTilde(arg.WordParts()))
 	if err != nil {
-		return nil, err
+		return nil, wordError(arg, err)
 	}
 	return &parser.Argument{
 		Value:     expanded,
-		Quoted:    false,
+		Quoted:    false, // После обработки кавычки убираются
 		QuoteType: parser.NoQuote,
+		Position:  arg.Position,
 	}, nil
 }
 
-// expandString выполняет подстановку переменных в строке.
-// Одинарные кавычки обрабатываются на уровне expandArgument, здесь всегда выполняются подстановки.
-// Обрабатывает экранирование обратными слешами и поддерживает синтаксис $VAR и ${VAR}.
-func (e *Expander) expandString(s string) (string, error) {
-	var result strings.Builder
-	var i int
-
-	for i < len(s) {
-		dollarIdx := e.findNextDollar(s, i)
-		if dollarIdx == -1 {
-			// $ не найден, записываем остаток и выходим
-			result.WriteString(s[i:])
-			break
-		}
+// wordError добавляет к ошибке подстановки позицию слова arg в исходном тексте.
+// Для слов без позиции (построенных не парсером) ошибка возвращается как есть.
+func wordError(arg *parser.Argument, err error) error {
+	if !arg.Position.IsValid() {
+		return err
+	}
+	return &lexer.PosError{Pos: arg.Position, Err: err}
+}
 
-		backslashCount := e.countBackslashesBefore(s, dollarIdx)
-		if e.handleEscapedDollar(&result, s, &i, dollarIdx, backslashCount) {
+// expandWord выполняет подстановки в частях слова и объединяет их в одну строку.
+func (e *Expander) expandWord(parts []parser.WordPart) (string, error) {
+	var result strings.Builder
+	for _, part := range parts {
+		if part.QuoteType == parser.SingleQuote {
+			result.WriteString(part.Value)
 			continue
 		}
-
-		e.writeTextBeforeDollar(&result, s, i, dollarIdx, backslashCount)
-
-		newPos, err := e.expandVariable(&result, s, dollarIdx)
+		expanded, err := e.expandString(part.Value, part.QuoteType == parser.DoubleQuote)
 		if err != nil {
 			return "", err
 		}
-		i = newPos
+		result.WriteString(expanded)
 	}
-
 	return result.String(), nil
 }
 
-// findNextDollar ищет следующий символ $ в строке начиная с позиции start.
-// Возвращает индекс найденного $ или -1, если $ не найден.
-func (e *Expander) findNextDollar(s string, start int) int {
-	for j := start; j < len(s); j++ {
-		if s[j] == '$' {
-			return j
+// expandArgumentFields выполняет подстановки в аргументе и раскрывает шаблоны имен файлов.
+// Сначала раскрываются фигурные скобки вне кавычек ({a,b}, {1..3}), затем в каждом
+// получившемся слове раскрывается тильда и выполняются подстановки переменных и команд.
+// Результаты подстановок вне кавычек разбиваются на поля по IFS, а части слова в кавычках
+// остаются буквальными; "$@" дает по полю на каждый позиционный параметр.
+// Поле с неэкранированными метасимволами *, ? или [...] вне кавычек заменяется
+// отсортированным списком подходящих путей, поэтому из одного аргумента может получиться
+// несколько; в остальных полях снимается экранирование (\* дает буквальную *).
+func (e *Expander) expandArgumentFields(arg *parser.Argument) ([]*parser.Argument, error) {
+	words := expandWordBraces(arg.WordParts())
+	var fields []*parser.Argument
+	for _, word := range words {
+		// Пустые слова, полученные раскрытием скобок ({a,}), отбрасываются, как в bash
+		if len(word) == 0 && len(words) > 1 {
+			continue
+		}
+
+		values, err := e.expandFields(e.expandWordTilde(word))
+		if err != nil {
+			return nil, wordError(arg, err)
+		}
+		for _, value := range values {
+			if !glob.HasMeta(value) {
+				fields = append(fields, &parser.Argument{Value: glob.Unescape(value), QuoteType: parser.NoQuote, Position: arg.Position})
+				continue
+			}
+
+			matches, err := e.expandPathname(value)
+			if err != nil {
+				return nil, wordError(arg, err)
+			}
+			fields = append(fields, matches...)
 		}
 	}
-	return -1
+	return fields, nil
 }
 
-// countBackslashesBefore подсчитывает количество последовательных обратных слешей
-// перед позицией dollarIdx в строке s.
-func (e *Expander) countBackslashesBefore(s string, dollarIdx int) int {
-	count := 0
-	for k := dollarIdx - 1; k >= 0 && s[k] == '\\'; k-- {
-		count++
+// expandAssignmentValue выполняет подстановки в значении присваивания.
+// В частях значения без кавычек дополнительно раскрываются тильды в начале и после двоеточий
+// (PATH=~/bin:$PATH); скобки, разбиение на поля и шаблоны имен файлов
+// в присваиваниях не выполняются.
+func (e *Expander) expandAssignmentValue(value *parser.Argument) (*parser.Argument, error) {
+	expanded, err := e.expandWord(e.expandAssignmentWordTildes(value.WordParts()))
+	if err != nil {
+		return nil, wordError(value, err)
 	}
-	return count
+	return &parser.Argument{Value: expanded, QuoteType: parser.NoQuote, Position: value.Position}, nil
 }
 
-// handleEscapedDollar обрабатывает случай, когда $ экранирован обратным слешем.
-// Если $ экранирован (нечетное количество \), записывает текст до $ без последнего \
-// и сам $ как обычный символ. Возвращает true, если $ был экранирован.
-func (e *Expander) handleEscapedDollar(result *strings.Builder, s string, i *int, dollarIdx, backslashCount int) bool {
-	if backslashCount%2 == 1 {
-		// $ экранирован - записываем текст до $ без последнего экранирующего \
-		result.WriteString(s[*i : dollarIdx-1])
-		result.WriteRune('$')
-		*i = dollarIdx + 1
-		return true
+// expandString выполняет подстановку переменных и команд в строке и снимает экранирование.
+// Одинарные кавычки обрабатываются на уровне expandArgument, здесь всегда выполняются подстановки.
+// quoted означает, что строка была в двойных кавычках (см. scanExpansions).
+// Поддерживает синтаксис $VAR, ${VAR}, $(команда) и `команда`.
+// Результат всегда является одной строкой: "$@" объединяется через пробел.
+func (e *Expander) expandString(s string, quoted bool) (string, error) {
+	var result strings.Builder
+	err := e.scanExpansions(s, quoted,
+		func(text string) { result.WriteString(glob.Unescape(text)) },
+		func(_, value string, _ []operandPart) { result.WriteString(value) },
+	)
+	if err != nil {
+		return "", err
 	}
-	return false
+	return result.String(), nil
 }
 
-// writeTextBeforeDollar записывает текст до символа $ в результат.
-// Обрабатывает пары обратных слешей: каждая пара \\ становится одним \ в результате.
-func (e *Expander) writeTextBeforeDollar(result *strings.Builder, s string, start, dollarIdx, backslashCount int) {
-	if backslashCount > 0 {
-		// Записываем текст до начала последовательности \
-		result.WriteString(s[start : dollarIdx-backslashCount])
-		// Записываем половину обратных слешей (каждая пара \\ → один \)
-		for j := 0; j < backslashCount/2; j++ {
-			result.WriteRune('\\')
+// expandPattern выполняет подстановки в шаблоне без кавычек (например, в ${VAR#шаблон}).
+// Экранирование в тексте сохраняется, чтобы экранированные *, ? и [ сопоставлялись буквально,
+// как и части в кавычках слова подстановки ${VAR:-word}.
+func (e *Expander) expandPattern(s string) (string, error) {
+	var result strings.Builder
+	err := e.scanExpansions(s, false,
+		func(text string) { result.WriteString(text) },
+		func(_, value string, parts []operandPart) {
+			if parts == nil {
+				result.WriteString(value)
+				return
+			}
+			for _, part := range parts {
+				if part.quoted {
+					result.WriteString(glob.QuoteMeta(part.text))
+				} else {
+					result.WriteString(part.text)
+				}
+			}
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// scanExpansions разбирает строку на обычный текст и подстановки.
+// Лексер сохраняет экранирование в словах, а снимается оно здесь, после подстановок.
+// Текст передается в text в виде шаблона: экранированный символ записывается как \x,
+// поэтому glob.Unescape дает буквальный текст, а неэкранированные *, ? и [ остаются
+// метасимволами. Вне кавычек \ экранирует любой символ, а в двойных кавычках (quoted)
+// только $, ` и \ - перед остальными символами обратный слеш остается буквальным.
+// Для каждой подстановки вызывается expansion с её исходным текстом (например, "$@"),
+// значением и частями слова, давшего значение подстановки ${VAR:-word} или ${VAR:+word}
+// (для остальных подстановок parts равен nil).
+func (e *Expander) scanExpansions(s string, quoted bool, text func(string), expansion func(source, value string, parts []operandPart)) error {
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			text(literal.String())
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			if quoted && strings.IndexByte("$`\\", s[i+1]) == -1 {
+				// Буквальный обратный слеш в двойных кавычках
+				literal.WriteString(`\\`)
+				i++
+				continue
+			}
+			literal.WriteString(s[i : i+2])
+			i += 2
+		case c == '$' || c == '`':
+			flush()
+			var value strings.Builder
+			newPos, parts, err := e.expandVariable(&value, s, i)
+			if err != nil {
+				return err
+			}
+			expansion(s[i:newPos], value.String(), parts)
+			i = newPos
+		default:
+			literal.WriteByte(c)
+			i++
 		}
-	} else {
-		// Нет обратных слешей - записываем весь текст до $
-		result.WriteString(s[start:dollarIdx])
 	}
+
+	flush()
+	return nil
 }
 
-// expandVariable обрабатывает подстановку переменной после символа $.
-// Поддерживает синтаксис ${VAR} и $VAR с умным fallback для $VAR_suffix.
-// Возвращает новую позицию в строке после обработки переменной.
-func (e *Expander) expandVariable(result *strings.Builder, s string, dollarIdx int) (int, error) {
+// expandVariable обрабатывает подстановку после символа $ (или `).
+// Поддерживает синтаксис ${VAR} и $VAR с умным fallback для $VAR_suffix,
+// а также подстановку команд $(команда), `команда` и арифметическую подстановку $((выражение)).
+// Возвращает новую позицию в строке после обработки подстановки и части слова,
+// давшего значение подстановки ${VAR:-word} или ${VAR:+word} (см. applyDefault).
+func (e *Expander) expandVariable(result *strings.Builder, s string, dollarIdx int) (int, []operandPart, error) {
+	if s[dollarIdx] == '`' {
+		end, err := e.expandBackquote(result, s, dollarIdx)
+		return end, nil, err
+	}
+
 	// $ в конце строки - оставляем как есть
 	if dollarIdx+1 >= len(s) {
 		result.WriteRune('$')
-		return len(s), nil
+		return len(s), nil, nil
 	}
 
 	next := s[dollarIdx+1]
+	if next == '(' {
+		// Арифметическая подстановка $((выражение)) или подстановка команды $(команда)
+		end, err := e.expandCommandSubstitution(result, s, dollarIdx)
+		return end, nil, err
+	}
 	if next == '{' {
 		// Подстановка вида ${VAR}
 		return e.expandBracedVariable(result, s, dollarIdx)
 	}
 	if isValidVariableStart(rune(next)) {
 		// Подстановка вида $VAR
-		return e.expandSimpleVariable(result, s, dollarIdx), nil
+		return e.expandSimpleVariable(result, s, dollarIdx), nil, nil
+	}
+	if isSpecialParameter(next) || isDigit(next) {
+		// Специальный параметр ($?, $$, $!, $#, $@, $*) или позиционный параметр $0..$9;
+		// параметры с номером больше 9 записываются в скобках: ${10}
+		result.WriteString(e.getVariableValue(string(next)))
+		return dollarIdx + 2, nil, nil
 	}
 	// $ не является началом переменной
 	result.WriteRune('$')
-	return dollarIdx + 1, nil
-}
-
-// expandBracedVariable обрабатывает подстановку переменной в фигурных скобках ${VAR}.
-// Возвращает новую позицию в строке после закрывающей скобки.
-func (e *Expander) expandBracedVariable(result *strings.Builder, s string, dollarIdx int) (int, error) {
-	closeIdx := -1
-	for j := dollarIdx + 2; j < len(s); j++ {
-		if s[j] == '}' {
-			closeIdx = j
-			break
-		}
-	}
-	if closeIdx == -1 {
-		return 0, fmt.Errorf("unclosed ${ variable")
-	}
-	varName := s[dollarIdx+2 : closeIdx]
-	result.WriteString(e.getVariableValue(varName))
-	return closeIdx + 1, nil
+	return dollarIdx + 1, nil, nil
 }
 
 // expandSimpleVariable обрабатывает подстановку переменной без фигурных скобок $VAR.
@@ -326,11 +535,14 @@ func isValidVariableChar(r rune) bool {
 	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
 }
 
-// getVariableValue получает значение переменной из окружения.
-// Если переменная не существует, возвращает пустую строку (как в POSIX).
+// isSpecialParameter проверяет, является ли символ именем специального параметра.
+func isSpecialParameter(c byte) bool {
+	return strings.IndexByte("!?$#@*", c) != -1
+}
+
+// getVariableValue получает значение переменной, специального или позиционного параметра.
+// Если параметр не задан, возвращает пустую строку (как в POSIX).
 func (e *Expander) getVariableValue(name string) string {
-	if v, ok := e.environment.Get(name); ok {
-		return v
-	}
-	return ""
+	value, _ := e.lookupParameter(name)
+	return value
 }
@@ -1,9 +1,12 @@
 package expander
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
 	"gocli/internal/environment"
+	"gocli/internal/lexer"
 	"gocli/internal/parser"
 )
 
@@ -399,9 +402,9 @@ func TestExpander_ExpandTripleBackslash(t *testing.T) {
 	}
 
 	// \\\$VAR: первый \ экранирует второй \, третий \ экранирует $
-	// Результат: \\ + $VAR = \\$VAR
-	if expanded.Value != "\\\\$VAR" {
-		t.Errorf("expected '\\\\$VAR', got '%s'", expanded.Value)
+	// Результат: \ + $VAR = \$VAR
+	if expanded.Value != "\\$VAR" {
+		t.Errorf("expected '\\$VAR', got '%s'", expanded.Value)
 	}
 }
 
@@ -421,9 +424,18 @@ func TestExpander_ExpandInvalidVariableName(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// $123 не является валидным именем переменной, поэтому $ остается как есть
-	if expanded.Value != "$123" {
-		t.Errorf("expected '$123', got '%s'", expanded.Value)
+	// $123 - это позиционный параметр $1 (не задан) и текст "23", как в POSIX
+	if expanded.Value != "23" {
+		t.Errorf("expected '23', got '%s'", expanded.Value)
+	}
+
+	// $% не является подстановкой, поэтому $ остается как есть
+	expanded, err = exp.expandArgument(&parser.Argument{Value: "$%abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expanded.Value != "$%abc" {
+		t.Errorf("expected '$%%abc', got '%s'", expanded.Value)
 	}
 }
 
@@ -589,3 +601,232 @@ func TestExpander_ExpandGrepWithAssignment(t *testing.T) {
 		t.Errorf("expected assignment value '%s', got '%s'", testPattern, expandedCmd.Assignments[0].Value.Value)
 	}
 }
+
+// TestExpander_ExpandCommandRestoresAssignments проверяет, что переменные из assignments
+// не остаются в окружении после расширения команды.
+func TestExpander_ExpandCommandRestoresAssignments(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("EXISTING", "old")
+	exp := NewExpander(env)
+
+	cmd := &parser.Command{
+		Name: "echo",
+		Args: []*parser.Argument{{Value: "$NEW$EXISTING", QuoteType: parser.NoQuote}},
+		Assignments: []*parser.Assignment{
+			{Name: "NEW", Value: &parser.Argument{Value: "n", QuoteType: parser.NoQuote}},
+			{Name: "EXISTING", Value: &parser.Argument{Value: "e", QuoteType: parser.NoQuote}},
+		},
+	}
+
+	expanded, err := exp.ExpandCommand(cmd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expanded.Args[0].Value != "ne" {
+		t.Errorf("expected 'ne', got '%s'", expanded.Args[0].Value)
+	}
+	if _, exists := env.Get("NEW"); exists {
+		t.Error("NEW should be removed after expansion")
+	}
+	if value, _ := env.Get("EXISTING"); value != "old" {
+		t.Errorf("EXISTING should be restored to 'old', got '%s'", value)
+	}
+}
+
+// TestExpander_ExpandBackslashEscapes проверяет снятие экранирования после подстановок:
+// экранированные символы не раскрываются и не разбиваются, а обратный слеш
+// из значения переменной остается буквальным.
+func TestExpander_ExpandBackslashEscapes(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("VAR", `a\b *`)
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name     string
+		arg      *parser.Argument
+		expected []string
+	}{
+		{"escaped space", &parser.Argument{Value: `a\ b`}, []string{"a b"}},
+		{"escaped glob", &parser.Argument{Value: `\*.go`}, []string{"*.go"}},
+		{"escaped backslash", &parser.Argument{Value: `a\\b`}, []string{`a\b`}},
+		{"literal backslash in quotes", &parser.Argument{Value: `a\b\\c\$`, QuoteType: parser.DoubleQuote}, []string{`a\b\c$`}},
+		{"backslash in value", &parser.Argument{Value: "$VAR", QuoteType: parser.DoubleQuote}, []string{`a\b *`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := exp.expandArgumentFields(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(argumentValues(args), tt.expected) {
+				t.Errorf("expandArgumentFields(%q) = %q, expected %q", tt.arg.Value, argumentValues(args), tt.expected)
+			}
+		})
+	}
+}
+
+// compositeArgument создает аргумент из частей составного слова.
+func compositeArgument(parts ...parser.WordPart) *parser.Argument {
+	var value string
+	for _, part := range parts {
+		value += part.Value
+	}
+	return &parser.Argument{Value: value, Parts: parts}
+}
+
+// TestExpander_ExpandCompositeWords проверяет, что части составного слова раскрываются
+// по правилам своих кавычек и объединяются в одно слово.
+func TestExpander_ExpandCompositeWords(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("VAR", "a b")
+	env.Set("EMPTY", "")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name     string
+		arg      *parser.Argument
+		expected []string
+	}{
+		{"quotes concatenated", compositeArgument(
+			parser.WordPart{Value: "foo"},
+			parser.WordPart{Value: "bar", QuoteType: parser.DoubleQuote},
+			parser.WordPart{Value: "baz", QuoteType: parser.SingleQuote},
+		), []string{"foobarbaz"}},
+		{"quoted part is not split", compositeArgument(
+			parser.WordPart{Value: "x"},
+			parser.WordPart{Value: "$VAR", QuoteType: parser.DoubleQuote},
+		), []string{"xa b"}},
+		{"unquoted part is split", compositeArgument(
+			parser.WordPart{Value: "$VAR"},
+			parser.WordPart{Value: "$VAR", QuoteType: parser.DoubleQuote},
+		), []string{"a", "ba b"}},
+		{"single quotes are literal", compositeArgument(
+			parser.WordPart{Value: "$VAR", QuoteType: parser.SingleQuote},
+			parser.WordPart{Value: "*"},
+			parser.WordPart{Value: "*", QuoteType: parser.DoubleQuote},
+		), []string{"$VAR**"}},
+		{"empty quotes keep the word", compositeArgument(
+			parser.WordPart{Value: "$EMPTY"},
+			parser.WordPart{Value: "", QuoteType: parser.SingleQuote},
+		), []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := exp.expandArgumentFields(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(argumentValues(args), tt.expected) {
+				t.Errorf("expandArgumentFields(%s) = %q, expected %q", tt.arg, argumentValues(args), tt.expected)
+			}
+		})
+	}
+}
+
+// TestExpander_ExpandCommandName проверяет, что имя команды раскрывается как слово:
+// подстановка может дать имя с аргументами или исчезнуть.
+func TestExpander_ExpandCommandName(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("CMD", "ls -l")
+	env.Set("EMPTY", "")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name         string
+		cmd          *parser.Command
+		expectedName string
+		expectedArgs []string
+	}{
+		{"split name", &parser.Command{Name: "$CMD", Args: []*parser.Argument{{Value: "dir"}}}, "ls", []string{"-l", "dir"}},
+		{"quoted name", &parser.Command{Name: "$CMD", NameArg: &parser.Argument{Value: "$CMD", QuoteType: parser.DoubleQuote}}, "ls -l", nil},
+		{"empty name", &parser.Command{Name: "$EMPTY", Args: []*parser.Argument{{Value: "echo"}, {Value: "hi"}}}, "echo", []string{"hi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := exp.ExpandCommand(tt.cmd)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cmd.Name != tt.expectedName || !reflect.DeepEqual(argumentValues(cmd.Args), tt.expectedArgs) {
+				t.Errorf("ExpandCommand() = %q %q, expected %q %q", cmd.Name, argumentValues(cmd.Args), tt.expectedName, tt.expectedArgs)
+			}
+		})
+	}
+}
+
+// TestExpander_ExpandRegex проверяет раскрытие операнда =~: части в кавычках
+// экранируются и сравниваются буквально, текст без кавычек остается регулярным выражением.
+func TestExpander_ExpandRegex(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("RE", "[0-9]+")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name     string
+		arg      *parser.Argument
+		expected string
+	}{
+		{"unquoted regex", &parser.Argument{Value: "^a.(b|c)$"}, "^a.(b|c)$"},
+		{"escape is kept", &parser.Argument{Value: `a\.b`}, `a\.b`},
+		{"variable is regex", &parser.Argument{Value: "^$RE$"}, "^[0-9]+$"},
+		{"quoted variable is literal", compositeArgument(
+			parser.WordPart{Value: "^"},
+			parser.WordPart{Value: "$RE", QuoteType: parser.DoubleQuote},
+		), `^\[0-9\]\+`},
+		{"single quotes are literal", compositeArgument(
+			parser.WordPart{Value: "a.b", QuoteType: parser.SingleQuote},
+			parser.WordPart{Value: ".*"},
+		), `a\.b.*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regex, err := exp.ExpandRegex(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if regex != tt.expected {
+				t.Errorf("ExpandRegex(%s) = %q, expected %q", tt.arg, regex, tt.expected)
+			}
+		})
+	}
+}
+
+// TestExpander_ErrorPositions проверяет, что ошибка подстановки имеет тип *lexer.PosError
+// с позицией слова, в котором она произошла, а позиции команды и слов сохраняются.
+func TestExpander_ErrorPositions(t *testing.T) {
+	env := environment.NewEnvironment()
+	exp := NewExpander(env)
+	pos := lexer.Position{Offset: 12, Line: 2, Column: 6}
+
+	cmd := &parser.Command{
+		Name:     "echo",
+		Args:     []*parser.Argument{{Value: "ok"}, {Value: "${MISSING:?not set}", Position: pos}},
+		Position: lexer.Position{Offset: 7, Line: 2, Column: 1},
+	}
+	_, err := exp.ExpandCommand(cmd)
+	var posErr *lexer.PosError
+	if !errors.As(err, &posErr) {
+		t.Fatalf("ExpandCommand() error = %v, expected *lexer.PosError", err)
+	}
+	if posErr.Pos != pos {
+		t.Errorf("ExpandCommand() error position = %v, expected %v", posErr.Pos, pos)
+	}
+
+	cmd.Args = cmd.Args[:1]
+	expanded, err := exp.ExpandCommand(cmd)
+	if err != nil {
+		t.Fatalf("ExpandCommand() error = %v", err)
+	}
+	if expanded.Pos() != cmd.Position {
+		t.Errorf("expanded command position = %v, expected %v", expanded.Pos(), cmd.Position)
+	}
+
+	if _, err := exp.expandArgument(&parser.Argument{Value: "${VAR"}); errors.As(err, &posErr) {
+		t.Errorf("expandArgument() without position error = %v, expected plain error", err)
+	}
+}
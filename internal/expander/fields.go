@@ -0,0 +1,224 @@
+package expander
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"gocli/internal/glob"
+	"gocli/internal/parser"
+)
+
+// defaultIFS - разделители полей, если переменная IFS не задана.
+const defaultIFS = " \t\n"
+
+// fieldBuilder собирает поля (отдельные слова), в которые раскрывается один аргумент.
+// Результаты подстановок без кавычек разбиваются на поля по символам IFS,
+// а "$@" дает по полю на каждый позиционный параметр.
+// Поля собираются в виде шаблонов (см. scanExpansions): результаты подстановок в кавычках
+// экранируются целиком, а без кавычек - только обратные слеши, поэтому *, ? и [
+// из значений переменных раскрываются как шаблоны имен файлов, как в POSIX shell.
+type fieldBuilder struct {
+	fields  []string
+	current strings.Builder
+	started bool   // Текущее поле начато (в нем есть текст или пустой параметр из "$@")
+	quoted  bool   // Текущая часть слова в двойных кавычках: разбиение по IFS не выполняется
+	ifs     string // Разделители полей
+}
+
+// newFieldBuilder создает сборщик полей с разделителями ifs.
+func newFieldBuilder(ifs string) *fieldBuilder {
+	return &fieldBuilder{ifs: ifs}
+}
+
+// text дописывает к текущему полю текст шаблона, который не подлежит разбиению.
+func (b *fieldBuilder) text(s string) {
+	if s == "" {
+		return
+	}
+	b.current.WriteString(s)
+	b.started = true
+}
+
+// quotedText дописывает текст из двойных кавычек: его метасимволы экранируются.
+func (b *fieldBuilder) quotedText(s string) {
+	b.text(glob.QuoteMeta(glob.Unescape(s)))
+}
+
+// literal дописывает текст из одинарных кавычек; даже пустые кавычки начинают поле.
+func (b *fieldBuilder) literal(s string) {
+	b.current.WriteString(glob.QuoteMeta(s))
+	b.started = true
+}
+
+// expansion дописывает результат подстановки. Без кавычек результат разбивается по IFS.
+func (b *fieldBuilder) expansion(value string) {
+	if b.quoted {
+		b.text(glob.QuoteMeta(value))
+		return
+	}
+	b.split(value)
+}
+
+// operand дописывает результат подстановки ${VAR:-word} или ${VAR:+word} без кавычек
+// по частям слова word: части в кавычках не разбиваются по IFS и не раскрываются
+// как шаблоны, а пустые кавычки дают поле, как ${V:-""}.
+func (b *fieldBuilder) operand(parts []operandPart) {
+	for _, part := range parts {
+		if part.quoted {
+			b.literal(part.text)
+			continue
+		}
+		b.split(part.text)
+	}
+}
+
+// params раскрывает позиционные параметры: первый дописывается к текущему полю,
+// каждый следующий начинает новое поле, как "$@" в "a$@b" -> "a1" "2b".
+// Без кавычек каждый параметр дополнительно разбивается по IFS, а пустые параметры исчезают.
+func (b *fieldBuilder) params(params []string) {
+	for i, param := range params {
+		if b.quoted {
+			if i > 0 {
+				b.endField()
+			}
+			b.current.WriteString(glob.QuoteMeta(param))
+			b.started = true
+			continue
+		}
+
+		if i > 0 && b.started {
+			b.endField()
+		}
+		b.split(param)
+	}
+}
+
+// split разбивает значение на поля по правилам POSIX:
+//   - последовательность пробельных символов IFS (пробел, табуляция, перевод строки)
+//     разделяет поля и не порождает пустых полей, в том числе в начале и в конце значения;
+//   - каждый непробельный символ IFS (вместе с окружающими пробельными) завершает поле,
+//     даже пустое: при IFS=: значение "a::b" дает поля "a", "" и "b".
+func (b *fieldBuilder) split(value string) {
+	if b.ifs == "" {
+		b.text(value)
+		return
+	}
+
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if !strings.ContainsRune(b.ifs, r) {
+			if r == '\\' {
+				// Обратный слеш из значения буквальный
+				b.current.WriteByte('\\')
+			}
+			b.current.WriteString(value[i : i+size])
+			b.started = true
+			i += size
+			continue
+		}
+
+		j := b.skipWhitespace(value, i)
+		delimited := false
+		if j < len(value) {
+			next, nextSize := utf8.DecodeRuneInString(value[j:])
+			if strings.ContainsRune(b.ifs, next) && !isIFSWhitespace(next) {
+				delimited = true
+				j = b.skipWhitespace(value, j+nextSize)
+			}
+		}
+		if delimited || b.started {
+			b.endField()
+		}
+		i = j
+	}
+}
+
+// skipWhitespace пропускает пробельные символы IFS, начиная с позиции i.
+func (b *fieldBuilder) skipWhitespace(value string, i int) int {
+	for i < len(value) {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if !isIFSWhitespace(r) || !strings.ContainsRune(b.ifs, r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// endField завершает текущее поле.
+func (b *fieldBuilder) endField() {
+	b.fields = append(b.fields, b.current.String())
+	b.current.Reset()
+	b.started = false
+}
+
+// finish завершает сборку и возвращает поля.
+// Слово без кавычек, раскрывшееся в пустую строку, не дает ни одного поля.
+func (b *fieldBuilder) finish() []string {
+	if b.started {
+		b.endField()
+	}
+	return b.fields
+}
+
+// expandFields выполняет подстановки в частях слова и возвращает получившиеся поля
+// в виде шаблонов. В частях в двойных кавычках результаты подстановок не разбиваются
+// по IFS, а $* объединяет параметры в одно поле. Часть в кавычках дает хотя бы
+// одно (возможно, пустое) поле, кроме "$@" без параметров.
+func (e *Expander) expandFields(parts []parser.WordPart) ([]string, error) {
+	builder := newFieldBuilder(e.fieldSeparators())
+	for _, part := range parts {
+		if part.QuoteType == parser.SingleQuote {
+			builder.literal(part.Value)
+			continue
+		}
+
+		quoted := part.QuoteType == parser.DoubleQuote
+		builder.quoted = quoted
+		text := builder.text
+		if quoted {
+			text = builder.quotedText
+		}
+
+		allParams := false
+		err := e.scanExpansions(part.Value, quoted, text, func(source, value string, operand []operandPart) {
+			if isAllParameters(source, "@") || (!quoted && isAllParameters(source, "*")) {
+				allParams = true
+				builder.params(e.environment.Positional())
+				return
+			}
+			if operand != nil && !quoted {
+				builder.operand(operand)
+				return
+			}
+			builder.expansion(value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if quoted && !allParams {
+			// "" дает пустое поле
+			builder.started = true
+		}
+	}
+	return builder.finish(), nil
+}
+
+// fieldSeparators возвращает разделители полей: значение IFS или пробел, табуляцию
+// и перевод строки, если IFS не задана. Пустая IFS отключает разбиение.
+func (e *Expander) fieldSeparators() string {
+	if ifs, ok := e.environment.Get("IFS"); ok {
+		return ifs
+	}
+	return defaultIFS
+}
+
+// isAllParameters проверяет, что подстановка source - это $name или ${name}.
+func isAllParameters(source, name string) bool {
+	return source == "$"+name || source == "${"+name+"}"
+}
+
+// isIFSWhitespace проверяет, является ли символ пробельным с точки зрения IFS.
+func isIFSWhitespace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
@@ -0,0 +1,185 @@
+package expander
+
+import (
+	"reflect"
+	"testing"
+
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// TestExpander_ExpandPositionalParameters проверяет подстановку позиционных и специальных параметров.
+func TestExpander_ExpandPositionalParameters(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.SetPositional([]string{"a", "b c", "d"})
+	env.SetSpecial("?", "1")
+	env.SetSpecial("$", "4242")
+	env.SetSpecial("0", "gocli")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"$1", "a"},
+		{"$2", "b c"},
+		{"$4", ""},
+		{"$10", "a0"},
+		{"${10}", ""},
+		{"$#", "3"},
+		{"$?", "1"},
+		{"$$", "4242"},
+		{"$0", "gocli"},
+		{"$@", "a b c d"},
+		{"$*", "a b c d"},
+		{"${#}", "3"},
+		{"${#1}", "1"},
+		{"${2:-x}", "b c"},
+		{"${5:-x}", "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			expanded, err := exp.expandArgument(&parser.Argument{Value: tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expanded.Value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, expanded.Value)
+			}
+		})
+	}
+
+	// $* в кавычках объединяет параметры через первый символ IFS
+	env.Set("IFS", ":")
+	expanded, err := exp.expandArgument(&parser.Argument{Value: "$*", QuoteType: parser.DoubleQuote})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expanded.Value != "a:b c:d" {
+		t.Errorf(`"$*" with IFS=: = %q, expected "a:b c:d"`, expanded.Value)
+	}
+}
+
+// TestExpander_ExpandQuotedAt проверяет, что "$@" раскрывается в отдельное слово
+// для каждого позиционного параметра.
+func TestExpander_ExpandQuotedAt(t *testing.T) {
+	env := environment.NewEnvironment()
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name     string
+		params   []string
+		arg      *parser.Argument
+		expected []string
+	}{
+		{"quoted at", []string{"a", "b c"}, &parser.Argument{Value: "$@", QuoteType: parser.DoubleQuote}, []string{"a", "b c"}},
+		{"quoted braced at", []string{"a", "b c"}, &parser.Argument{Value: "${@}", QuoteType: parser.DoubleQuote}, []string{"a", "b c"}},
+		{"with prefix and suffix", []string{"1", "2", "3"}, &parser.Argument{Value: "x$@y", QuoteType: parser.DoubleQuote}, []string{"x1", "2", "3y"}},
+		{"no parameters", nil, &parser.Argument{Value: "$@", QuoteType: parser.DoubleQuote}, nil},
+		{"no parameters with text", nil, &parser.Argument{Value: "x$@", QuoteType: parser.DoubleQuote}, []string{"x"}},
+		{"quoted star", []string{"a", "b c"}, &parser.Argument{Value: "$*", QuoteType: parser.DoubleQuote}, []string{"a b c"}},
+		{"unquoted star", []string{"a", "b"}, &parser.Argument{Value: "$*"}, []string{"a", "b"}},
+		{"empty parameter kept", []string{"", "b"}, &parser.Argument{Value: "$@", QuoteType: parser.DoubleQuote}, []string{"", "b"}},
+		{"empty quoted string", nil, &parser.Argument{Value: "", QuoteType: parser.DoubleQuote}, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.SetPositional(tt.params)
+			args, err := exp.expandArgumentFields(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := argumentValues(args); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestExpander_FieldSplitting проверяет разбиение результатов подстановок на поля по IFS.
+func TestExpander_FieldSplitting(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifs      *string
+		value    string
+		arg      *parser.Argument
+		expected []string
+	}{
+		{"default whitespace", nil, "a b  c", &parser.Argument{Value: "$V"}, []string{"a", "b", "c"}},
+		{"leading and trailing whitespace", nil, "  a\tb\n", &parser.Argument{Value: "$V"}, []string{"a", "b"}},
+		{"joined with literal text", nil, "a b", &parser.Argument{Value: "x${V}y"}, []string{"xa", "by"}},
+		{"whitespace at edges splits text", nil, " a ", &parser.Argument{Value: "x${V}y"}, []string{"x", "a", "y"}},
+		{"empty expansion removed", nil, "", &parser.Argument{Value: "$V"}, nil},
+		{"whitespace only removed", nil, "   ", &parser.Argument{Value: "$V"}, nil},
+		{"empty expansion with text", nil, "", &parser.Argument{Value: "a$V"}, []string{"a"}},
+		{"double quoted not split", nil, "a  b", &parser.Argument{Value: "$V", QuoteType: parser.DoubleQuote}, []string{"a  b"}},
+		{"double quoted empty kept", nil, "", &parser.Argument{Value: "$V", QuoteType: parser.DoubleQuote}, []string{""}},
+		{"literal text not split", nil, "", &parser.Argument{Value: "a b"}, []string{"a b"}},
+		{"custom separator", strPtr(":"), "a:b:c", &parser.Argument{Value: "$V"}, []string{"a", "b", "c"}},
+		{"empty fields between separators", strPtr(":"), "a::b", &parser.Argument{Value: "$V"}, []string{"a", "", "b"}},
+		{"leading separator", strPtr(":"), ":a", &parser.Argument{Value: "$V"}, []string{"", "a"}},
+		{"trailing separator", strPtr(":"), "a:", &parser.Argument{Value: "$V"}, []string{"a"}},
+		{"spaces kept with non-whitespace IFS", strPtr(":"), "a b:c", &parser.Argument{Value: "$V"}, []string{"a b", "c"}},
+		{"whitespace around separator", strPtr(" :"), "a : b  c", &parser.Argument{Value: "$V"}, []string{"a", "b", "c"}},
+		{"empty IFS disables splitting", strPtr(""), "a b", &parser.Argument{Value: "$V"}, []string{"a b"}},
+		{"command substitution", nil, "", &parser.Argument{Value: "$(list)"}, []string{"<list>"}},
+		{"operand word split", nil, "", &parser.Argument{Value: "${U:-a  b}"}, []string{"a", "b"}},
+		{"quoted operand word not split", nil, "", &parser.Argument{Value: `${U:-"a  b"}`}, []string{"a  b"}},
+		{"mixed operand word", nil, "", &parser.Argument{Value: `${U:-x  'a  b'c}`}, []string{"x", "a  bc"}},
+		{"escaped operand word", nil, "", &parser.Argument{Value: `${U:-a\ b}`}, []string{"a b"}},
+		{"expansion in quoted operand word", nil, "1  2", &parser.Argument{Value: `${U:-"$V"}`}, []string{"1  2"}},
+		{"expansion in operand word", nil, "1  2", &parser.Argument{Value: "${U:-$V}"}, []string{"1", "2"}},
+		{"quoted alternative word", nil, "set", &parser.Argument{Value: `${V:+"a  b"}`}, []string{"a  b"}},
+		{"empty quoted operand word", nil, "", &parser.Argument{Value: `${U:-""}`}, []string{""}},
+		{"nested quoted operand word", nil, "", &parser.Argument{Value: `${U:-${W:-"a  b"}}`}, []string{"a  b"}},
+		{"assigned value split", nil, "", &parser.Argument{Value: `${U:="a  b"}`}, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := environment.NewEnvironment()
+			env.Set("V", tt.value)
+			if tt.ifs != nil {
+				env.Set("IFS", *tt.ifs)
+			} else {
+				env.Unset("IFS")
+			}
+			exp := NewExpander(env)
+			exp.SetCommandRunner(echoRunner)
+
+			args, err := exp.expandArgumentFields(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := argumentValues(args); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestExpander_FieldSplittingParameters проверяет разбиение $@ и $* без кавычек.
+func TestExpander_FieldSplittingParameters(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Unset("IFS")
+	env.SetPositional([]string{"a b", "", "c"})
+	exp := NewExpander(env)
+
+	for _, value := range []string{"$@", "$*"} {
+		args, err := exp.expandArgumentFields(&parser.Argument{Value: value})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []string{"a", "b", "c"}
+		if got := argumentValues(args); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %q, got %q", value, expected, got)
+		}
+	}
+}
+
+// strPtr возвращает указатель на строку (для необязательных значений в таблицах тестов).
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,37 @@
+package expander
+
+import (
+	"fmt"
+
+	"gocli/internal/environment"
+	"gocli/internal/glob"
+	"gocli/internal/parser"
+)
+
+// expandPathname раскрывает шаблон в список путей относительно рабочего каталога shell'а.
+// Если совпадений нет, поведение определяется параметрами shell'а:
+// по умолчанию шаблон остается как есть (без экранирования), с nullglob он удаляется,
+// с failglob раскрытие завершается ошибкой.
+func (e *Expander) expandPathname(pattern string) ([]*parser.Argument, error) {
+	matches, err := glob.Expand(e.environment.Dir(), pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		switch {
+		case e.environment.Option(environment.OptionFailGlob):
+			return nil, fmt.Errorf("no match: %s", pattern)
+		case e.environment.Option(environment.OptionNullGlob):
+			return nil, nil
+		default:
+			return []*parser.Argument{{Value: glob.Unescape(pattern), QuoteType: parser.NoQuote}}, nil
+		}
+	}
+
+	args := make([]*parser.Argument, 0, len(matches))
+	for _, match := range matches {
+		args = append(args, &parser.Argument{Value: match, QuoteType: parser.NoQuote})
+	}
+	return args, nil
+}
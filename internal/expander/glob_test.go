@@ -0,0 +1,99 @@
+package expander
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// argumentValues возвращает значения аргументов для сравнения в тестах.
+func argumentValues(args []*parser.Argument) []string {
+	var values []string
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	return values
+}
+
+// TestExpander_ExpandPathname проверяет раскрытие шаблонов имен файлов в аргументах команды.
+func TestExpander_ExpandPathname(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt", "c.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+
+	env := environment.NewEnvironment()
+	env.Set("PATTERN", "*.go")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name     string
+		arg      *parser.Argument
+		expected []string
+	}{
+		{"star", &parser.Argument{Value: "*.txt"}, []string{"a.txt", "b.txt"}},
+		{"question mark", &parser.Argument{Value: "?.go"}, []string{"c.go"}},
+		{"bracket", &parser.Argument{Value: "[ac].*"}, []string{"a.txt", "c.go"}},
+		{"from variable", &parser.Argument{Value: "$PATTERN"}, []string{"c.go"}},
+		{"double quoted", &parser.Argument{Value: "*.txt", Quoted: true, QuoteType: parser.DoubleQuote}, []string{"*.txt"}},
+		{"single quoted", &parser.Argument{Value: "*.txt", Quoted: true, QuoteType: parser.SingleQuote}, []string{"*.txt"}},
+		{"no metacharacters", &parser.Argument{Value: "a.txt"}, []string{"a.txt"}},
+		{"no match keeps pattern", &parser.Argument{Value: "*.none"}, []string{"*.none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := exp.expandArgumentFields(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := argumentValues(args); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	// Шаблон раскрывается в несколько аргументов команды
+	cmd, err := exp.ExpandCommand(&parser.Command{
+		Name: "echo",
+		Args: []*parser.Argument{{Value: "first"}, {Value: "*.txt"}, {Value: "last"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"first", "a.txt", "b.txt", "last"}
+	if got := argumentValues(cmd.Args); !reflect.DeepEqual(got, expected) {
+		t.Errorf("command args = %q, expected %q", got, expected)
+	}
+}
+
+// TestExpander_ExpandPathnameNoMatch проверяет режимы nullglob и failglob.
+func TestExpander_ExpandPathnameNoMatch(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	env := environment.NewEnvironment()
+	exp := NewExpander(env)
+	arg := &parser.Argument{Value: "*.none"}
+
+	env.SetOption(environment.OptionNullGlob, true)
+	args, err := exp.expandArgumentFields(arg)
+	if err != nil {
+		t.Fatalf("nullglob: unexpected error: %v", err)
+	}
+	if len(args) != 0 {
+		t.Errorf("nullglob: expected no arguments, got %q", argumentValues(args))
+	}
+
+	// failglob имеет приоритет над nullglob, как в bash
+	env.SetOption(environment.OptionFailGlob, true)
+	if _, err := exp.expandArgumentFields(arg); err == nil {
+		t.Error("failglob: expected error for pattern without matches")
+	}
+}
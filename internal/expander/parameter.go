@@ -0,0 +1,641 @@
+package expander
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gocli/internal/glob"
+	"gocli/internal/lexer"
+)
+
+// expandBracedVariable обрабатывает подстановку в фигурных скобках ${...}.
+// Кроме простой подстановки ${VAR} поддерживаются операторы POSIX и расширения bash:
+//   - ${VAR:-word}, ${VAR-word} - значение по умолчанию;
+//   - ${VAR:=word}, ${VAR=word} - значение по умолчанию с присваиванием;
+//   - ${VAR:?word}, ${VAR?word} - ошибка, если переменная не задана;
+//   - ${VAR:+word}, ${VAR+word} - альтернативное значение;
+//   - ${#VAR} - длина значения в символах;
+//   - ${VAR#pat}, ${VAR##pat}, ${VAR%pat}, ${VAR%%pat} - удаление префикса или суффикса;
+//   - ${VAR:offset}, ${VAR:offset:length} - подстрока;
+//   - ${VAR/pat/rep}, ${VAR//pat/rep}, ${VAR/#pat/rep}, ${VAR/%pat/rep} - замена;
+//   - ${VAR^}, ${VAR^^}, ${VAR,}, ${VAR,,} - изменение регистра;
+//   - ${ARR[i]}, ${ARR[@]}, ${#ARR[@]} - элемент массива, все элементы и их число.
+//
+// Формы с двоеточием считают пустое значение незаданным.
+// Возвращает новую позицию в строке после закрывающей скобки и части слова word,
+// если значение дали операторы - или + (см. applyDefault).
+func (e *Expander) expandBracedVariable(result *strings.Builder, s string, dollarIdx int) (int, []operandPart, error) {
+	end, err := lexer.SubstitutionEnd(s, dollarIdx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("unclosed ${ variable")
+	}
+
+	value, parts, err := e.expandParameter(s[dollarIdx+2 : end-1])
+	if err != nil {
+		return 0, nil, err
+	}
+	result.WriteString(value)
+	return end, parts, nil
+}
+
+// expandParameter вычисляет содержимое ${...} без скобок. Для операторов - и +
+// возвращает также части слова, давшего значение (см. applyDefault).
+func (e *Expander) expandParameter(content string) (string, []operandPart, error) {
+	// Длина значения ${#VAR}; ${#} - это специальный параметр #
+	if len(content) > 1 && content[0] == '#' {
+		name := parameterName(content[1:])
+		if name == "" || len(name) != len(content)-1 {
+			return "", nil, badSubstitution(content)
+		}
+		if name == "@" || name == "*" {
+			// ${#@} и ${#*} - число позиционных параметров
+			return strconv.Itoa(len(e.environment.Positional())), nil, nil
+		}
+		if base, subscript, ok := splitSubscript(name); ok && (subscript == "@" || subscript == "*") {
+			// ${#ARR[@]} - число элементов массива
+			values, _ := e.environment.Array(base)
+			return strconv.Itoa(len(values)), nil, nil
+		}
+		value, _, err := e.lookupParameterElement(name)
+		if err != nil {
+			return "", nil, err
+		}
+		return strconv.Itoa(utf8.RuneCountInString(value)), nil, nil
+	}
+
+	name := parameterName(content)
+	if name == "" {
+		return "", nil, badSubstitution(content)
+	}
+	value, set, err := e.lookupParameterElement(name)
+	if err != nil {
+		return "", nil, err
+	}
+	rest := content[len(name):]
+	if rest == "" {
+		return value, nil, nil
+	}
+
+	switch {
+	case strings.HasPrefix(rest, ":-"), strings.HasPrefix(rest, ":="),
+		strings.HasPrefix(rest, ":?"), strings.HasPrefix(rest, ":+"):
+		return e.applyDefault(name, value, set && value != "", rest[1], rest[2:])
+	case rest[0] == '-', rest[0] == '=', rest[0] == '?', rest[0] == '+':
+		return e.applyDefault(name, value, set, rest[0], rest[1:])
+	case rest[0] == ':':
+		return withoutParts(e.substring(value, rest[1:]))
+	case rest[0] == '#', rest[0] == '%':
+		return withoutParts(e.removeAffix(value, rest))
+	case rest[0] == '/':
+		return withoutParts(e.replace(value, rest[1:]))
+	case rest[0] == '^', rest[0] == ',':
+		return withoutParts(e.convertCase(value, rest))
+	default:
+		return "", nil, badSubstitution(content)
+	}
+}
+
+// withoutParts дополняет результат оператора, значение которого не составлено из слова word,
+// пустым списком частей.
+func withoutParts(value string, err error) (string, []operandPart, error) {
+	return value, nil, err
+}
+
+// parameterName возвращает имя параметра в начале содержимого ${...}:
+// имя переменной (возможно, с индексом массива: ARR[1]), номер позиционного параметра
+// или специальный параметр из одного символа.
+// Возвращает пустую строку, если содержимое не начинается с имени.
+func parameterName(content string) string {
+	if content == "" {
+		return ""
+	}
+
+	first := rune(content[0])
+	switch {
+	case isValidVariableStart(first):
+		end := 1
+		for end < len(content) && isValidVariableChar(rune(content[end])) {
+			end++
+		}
+		if closing := strings.IndexByte(content[end:], ']'); end < len(content) && content[end] == '[' && closing > 1 {
+			end += closing + 1
+		}
+		return content[:end]
+	case unicode.IsDigit(first):
+		end := 1
+		for end < len(content) && unicode.IsDigit(rune(content[end])) {
+			end++
+		}
+		return content[:end]
+	case isSpecialParameter(content[0]):
+		return content[:1]
+	default:
+		return ""
+	}
+}
+
+// lookupParameter возвращает значение параметра и флаг того, что параметр задан.
+// Кроме переменных поддерживаются специальные параметры, сохраненные shell'ом ($?, $$, $!, $0),
+// число позиционных параметров $#, позиционные параметры $1, $2, ... и их объединение $@ и $*.
+func (e *Expander) lookupParameter(name string) (string, bool) {
+	if v, ok := e.environment.GetSpecial(name); ok {
+		return v, true
+	}
+
+	switch {
+	case name == "#":
+		return strconv.Itoa(len(e.environment.Positional())), true
+	case name == "@" || name == "*":
+		params := e.environment.Positional()
+		return strings.Join(params, e.parameterSeparator(name)), len(params) > 0
+	case isDigit(name[0]):
+		n, err := strconv.Atoi(name)
+		params := e.environment.Positional()
+		if err != nil || n < 1 || n > len(params) {
+			return "", false
+		}
+		return params[n-1], true
+	}
+
+	return e.environment.Get(name)
+}
+
+// splitSubscript разделяет имя вида ARR[index] на имя массива и индекс.
+func splitSubscript(name string) (string, string, bool) {
+	open := strings.IndexByte(name, '[')
+	if open < 0 || !strings.HasSuffix(name, "]") {
+		return "", "", false
+	}
+	return name[:open], name[open+1 : len(name)-1], true
+}
+
+// lookupParameterElement возвращает значение параметра, как lookupParameter, а для имени
+// с индексом - элемент массива. Индекс - арифметическое выражение; отрицательный индекс
+// отсчитывается от конца массива. Индексы @ и * дают все элементы через пробел
+// (для * - через первый символ IFS).
+func (e *Expander) lookupParameterElement(name string) (string, bool, error) {
+	base, subscript, ok := splitSubscript(name)
+	if !ok {
+		value, set := e.lookupParameter(name)
+		return value, set, nil
+	}
+
+	values, _ := e.environment.Array(base)
+	if subscript == "@" || subscript == "*" {
+		return strings.Join(values, e.parameterSeparator(subscript)), len(values) > 0, nil
+	}
+
+	index, err := e.EvaluateArithmetic(subscript)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: bad array subscript", name)
+	}
+	if index < 0 {
+		index += int64(len(values))
+	}
+	if index < 0 || index >= int64(len(values)) {
+		return "", false, nil
+	}
+	return values[index], true, nil
+}
+
+// parameterSeparator возвращает разделитель при объединении позиционных параметров в строку:
+// для $* - первый символ IFS (пробел, если IFS не задана), для $@ - пробел.
+func (e *Expander) parameterSeparator(name string) string {
+	if name == "*" {
+		if ifs, ok := e.environment.Get("IFS"); ok {
+			if ifs == "" {
+				return ""
+			}
+			_, size := utf8.DecodeRuneInString(ifs)
+			return ifs[:size]
+		}
+	}
+	return " "
+}
+
+// applyDefault применяет операторы -, =, ? и + к параметру.
+// isSet означает, что параметр задан (для форм с двоеточием - задан и не пуст).
+// Если значением становится слово word операторов - и +, возвращаются и его части,
+// чтобы части в кавычках не разбивались по IFS: ${V:-"a  b"} дает одно поле.
+// Значение оператора = - значение переменной, и оно разбивается целиком.
+func (e *Expander) applyDefault(name, value string, isSet bool, op byte, word string) (string, []operandPart, error) {
+	switch op {
+	case '+':
+		if !isSet {
+			return "", nil, nil
+		}
+		return e.expandOperandWord(word)
+	case '-':
+		if isSet {
+			return value, nil, nil
+		}
+		return e.expandOperandWord(word)
+	case '=':
+		if isSet {
+			return value, nil, nil
+		}
+		expanded, err := e.expandOperand(word, false)
+		if err != nil {
+			return "", nil, err
+		}
+		if !isValidVariableStart(rune(name[0])) || strings.ContainsRune(name, '[') {
+			return "", nil, fmt.Errorf("$%s: cannot assign in this way", name)
+		}
+		e.environment.Set(name, expanded)
+		return expanded, nil, nil
+	default: // '?'
+		if isSet {
+			return value, nil, nil
+		}
+		message, err := e.expandOperand(word, false)
+		if err != nil {
+			return "", nil, err
+		}
+		if message == "" {
+			message = "parameter null or not set"
+		}
+		return "", nil, &UnsetParameterError{Name: name, Message: message}
+	}
+}
+
+// UnsetParameterError - ошибка подстановки ${VAR:?word} или ${VAR?word} для незаданного
+// параметра. В отличие от прочих ошибок подстановки, она завершает неинтерактивный shell.
+type UnsetParameterError struct {
+	Name    string // Имя параметра
+	Message string // Раскрытое слово word или стандартное сообщение
+}
+
+// Error возвращает сообщение в формате "имя: сообщение".
+func (e *UnsetParameterError) Error() string {
+	return e.Name + ": " + e.Message
+}
+
+// substring вычисляет ${VAR:offset} и ${VAR:offset:length}.
+// Смещение и длина - арифметические выражения. Отрицательное смещение отсчитывается
+// от конца значения, отрицательная длина задает позицию конца, отсчитанную от конца.
+func (e *Expander) substring(value, spec string) (string, error) {
+	runes := []rune(value)
+	offsetExpr, lengthExpr, hasLength := strings.Cut(spec, ":")
+
+	offset, err := e.evaluateIndex(offsetExpr)
+	if err != nil {
+		return "", err
+	}
+	if offset < 0 {
+		offset += len(runes)
+	}
+	if offset < 0 || offset > len(runes) {
+		return "", nil
+	}
+
+	end := len(runes)
+	if hasLength {
+		length, err := e.evaluateIndex(lengthExpr)
+		if err != nil {
+			return "", err
+		}
+		if length < 0 {
+			end = len(runes) + length
+			if end < offset {
+				return "", fmt.Errorf("%s: substring expression < 0", strings.TrimSpace(lengthExpr))
+			}
+		} else if offset+length < end {
+			end = offset + length
+		}
+	}
+
+	return string(runes[offset:end]), nil
+}
+
+// evaluateIndex вычисляет арифметическое выражение смещения или длины подстроки.
+func (e *Expander) evaluateIndex(expr string) (int, error) {
+	value, err := e.expandArithmetic(expr)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid substring index %q", value)
+	}
+	return n, nil
+}
+
+// removeAffix удаляет из значения префикс (#, ##) или суффикс (%, %%), соответствующий шаблону.
+// Одиночный оператор удаляет кратчайшее совпадение, двойной - самое длинное.
+func (e *Expander) removeAffix(value, spec string) (string, error) {
+	op := spec[:1]
+	longest := len(spec) > 1 && spec[1] == spec[0]
+	patternText := spec[1:]
+	if longest {
+		patternText = spec[2:]
+	}
+
+	pattern, err := e.expandOperand(patternText, true)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := runeBoundaries(value)
+	if op == "#" {
+		for i := range bounds {
+			idx := bounds[i]
+			if longest {
+				idx = bounds[len(bounds)-1-i]
+			}
+			if glob.Match(pattern, value[:idx]) {
+				return value[idx:], nil
+			}
+		}
+		return value, nil
+	}
+
+	for i := range bounds {
+		idx := bounds[len(bounds)-1-i]
+		if longest {
+			idx = bounds[i]
+		}
+		if glob.Match(pattern, value[idx:]) {
+			return value[:idx], nil
+		}
+	}
+	return value, nil
+}
+
+// replace вычисляет замену ${VAR/pat/rep}. Форма // заменяет все совпадения,
+// /# - совпадение в начале значения, /% - совпадение в конце. Заменяется самое длинное совпадение.
+func (e *Expander) replace(value, spec string) (string, error) {
+	mode := byte(0)
+	if spec != "" && (spec[0] == '/' || spec[0] == '#' || spec[0] == '%') {
+		mode = spec[0]
+		spec = spec[1:]
+	}
+
+	patternText, replacementText := splitReplacement(spec)
+	pattern, err := e.expandOperand(patternText, true)
+	if err != nil {
+		return "", err
+	}
+	if pattern == "" {
+		return value, nil
+	}
+	replacement, err := e.expandOperand(replacementText, false)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := runeBoundaries(value)
+	switch mode {
+	case '#':
+		for i := len(bounds) - 1; i >= 0; i-- {
+			if glob.Match(pattern, value[:bounds[i]]) {
+				return replacement + value[bounds[i]:], nil
+			}
+		}
+		return value, nil
+	case '%':
+		for _, idx := range bounds {
+			if glob.Match(pattern, value[idx:]) {
+				return value[:idx] + replacement, nil
+			}
+		}
+		return value, nil
+	}
+
+	var result strings.Builder
+	last := 0
+	for i := 0; i < len(bounds)-1; i++ {
+		start := bounds[i]
+		if start < last {
+			continue
+		}
+		matchEnd := -1
+		for j := len(bounds) - 1; j > i; j-- {
+			if glob.Match(pattern, value[start:bounds[j]]) {
+				matchEnd = bounds[j]
+				break
+			}
+		}
+		if matchEnd == -1 {
+			continue
+		}
+
+		result.WriteString(value[last:start])
+		result.WriteString(replacement)
+		last = matchEnd
+		if mode != '/' {
+			break
+		}
+	}
+	result.WriteString(value[last:])
+	return result.String(), nil
+}
+
+// splitReplacement разделяет pat/rep по первому неэкранированному символу /.
+func splitReplacement(spec string) (string, string) {
+	for i := 0; i < len(spec); i++ {
+		switch spec[i] {
+		case '\\':
+			i++
+		case '/':
+			return spec[:i], spec[i+1:]
+		}
+	}
+	return spec, ""
+}
+
+// convertCase вычисляет ${VAR^}, ${VAR^^}, ${VAR,} и ${VAR,,}: перевод в верхний (^)
+// или нижний (,) регистр первого символа или всех символов.
+// Необязательный шаблон после оператора ограничивает изменяемые символы.
+func (e *Expander) convertCase(value, spec string) (string, error) {
+	convert := unicode.ToUpper
+	if spec[0] == ',' {
+		convert = unicode.ToLower
+	}
+	all := len(spec) > 1 && spec[1] == spec[0]
+	patternText := spec[1:]
+	if all {
+		patternText = spec[2:]
+	}
+
+	pattern := "?"
+	if patternText != "" {
+		expanded, err := e.expandOperand(patternText, true)
+		if err != nil {
+			return "", err
+		}
+		pattern = expanded
+	}
+
+	runes := []rune(value)
+	for i, r := range runes {
+		if glob.Match(pattern, string(r)) {
+			runes[i] = convert(r)
+		}
+		if !all {
+			break
+		}
+	}
+	return string(runes), nil
+}
+
+// operandPart - часть раскрытого слова-операнда: текст в кавычках (или экранированный
+// символ) или результат подстановок и текст без кавычек.
+type operandPart struct {
+	text   string
+	quoted bool
+}
+
+// expandOperand выполняет подстановки в слове-операнде оператора (значении по умолчанию,
+// шаблоне или замене) и убирает кавычки. Если pattern равен true, текст в кавычках
+// экранируется и сопоставляется буквально, как в ${VAR#"*"}.
+func (e *Expander) expandOperand(word string, pattern bool) (string, error) {
+	var result strings.Builder
+	err := e.scanOperand(word, pattern, func(text string, quoted bool) {
+		if pattern && quoted {
+			text = glob.QuoteMeta(text)
+		}
+		result.WriteString(text)
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// expandOperandWord раскрывает слово word операторов - и + и возвращает значение
+// вместе с частями слова (см. scanExpansions).
+func (e *Expander) expandOperandWord(word string) (string, []operandPart, error) {
+	var result strings.Builder
+	var parts []operandPart
+	err := e.scanOperand(word, false, func(text string, quoted bool) {
+		result.WriteString(text)
+		parts = append(parts, operandPart{text: text, quoted: quoted})
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return result.String(), parts, nil
+}
+
+// scanOperand выполняет подстановки в слове-операнде и передает в add его части без кавычек
+// с признаком quoted для текста в кавычках. Если pattern равен true, части без кавычек
+// передаются в виде шаблона (см. expandPattern), иначе экранированные символы в них
+// передаются как текст в кавычках.
+func (e *Expander) scanOperand(word string, pattern bool, add func(text string, quoted bool)) error {
+	for i := 0; i < len(word); {
+		switch word[i] {
+		case '\'':
+			end := strings.IndexByte(word[i+1:], '\'')
+			if end == -1 {
+				return fmt.Errorf("unterminated quote in %q", word)
+			}
+			add(word[i+1:i+1+end], true)
+			i += end + 2
+		case '"':
+			end := closingDoubleQuote(word, i+1)
+			if end == -1 {
+				return fmt.Errorf("unterminated quote in %q", word)
+			}
+			expanded, err := e.expandString(word[i+1:end], true)
+			if err != nil {
+				return err
+			}
+			add(expanded, true)
+			i = end + 1
+		default:
+			end := i
+			for end < len(word) && word[end] != '\'' && word[end] != '"' {
+				switch word[end] {
+				case '\\':
+					end += 2
+				case '$', '`':
+					end = skipSubstitution(word, end)
+				default:
+					end++
+				}
+			}
+			end = min(end, len(word))
+			if pattern {
+				expanded, err := e.expandPattern(word[i:end])
+				if err != nil {
+					return err
+				}
+				add(expanded, false)
+			} else {
+				err := e.scanExpansions(word[i:end], false,
+					func(text string) { unescapeParts(text, add) },
+					func(_, value string, parts []operandPart) {
+						if parts == nil {
+							add(value, false)
+							return
+						}
+						// Части вложенной подстановки ${W:-"a  b"} сохраняют кавычки
+						for _, part := range parts {
+							add(part.text, part.quoted)
+						}
+					},
+				)
+				if err != nil {
+					return err
+				}
+			}
+			i = end
+		}
+	}
+	return nil
+}
+
+// unescapeParts передает в add текст шаблона по частям: экранированные символы -
+// как текст в кавычках, остальной текст - как есть.
+func unescapeParts(text string, add func(text string, quoted bool)) {
+	for {
+		i := strings.IndexByte(text, '\\')
+		if i == -1 || i+1 == len(text) {
+			if text != "" {
+				add(text, false)
+			}
+			return
+		}
+		if i > 0 {
+			add(text[:i], false)
+		}
+		_, size := utf8.DecodeRuneInString(text[i+1:])
+		add(text[i+1:i+1+size], true)
+		text = text[i+1+size:]
+	}
+}
+
+// closingDoubleQuote ищет закрывающую двойную кавычку, начиная с позиции start,
+// пропуская экранированные символы и подстановки.
+func closingDoubleQuote(word string, start int) int {
+	for i := start; i < len(word); {
+		switch word[i] {
+		case '\\':
+			i += 2
+		case '$', '`':
+			i = skipSubstitution(word, i)
+		case '"':
+			return i
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// runeBoundaries возвращает позиции границ символов в строке, включая 0 и len(s).
+func runeBoundaries(s string) []int {
+	bounds := make([]int, 0, len(s)+1)
+	for i := range s {
+		bounds = append(bounds, i)
+	}
+	return append(bounds, len(s))
+}
+
+// badSubstitution возвращает ошибку некорректной подстановки ${...}.
+func badSubstitution(content string) error {
+	return fmt.Errorf("${%s}: bad substitution", content)
+}
@@ -0,0 +1,186 @@
+package expander
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// newParameterTestExpander создает expander с переменными для тестов подстановок ${...}.
+func newParameterTestExpander() (*Expander, *environment.Environment) {
+	env := environment.NewEnvironment()
+	env.Set("FILE", "/home/user/archive.tar.gz")
+	env.Set("EMPTY", "")
+	env.Set("WORD", "hello world")
+	env.Set("RU", "привет")
+	env.Set("STAR", "*")
+	env.SetArray("ARR", []string{"zero", "one", "two"})
+	env.Unset("UNSET")
+	return NewExpander(env), env
+}
+
+// TestExpander_ExpandParameterOperators проверяет операторы подстановки ${...}.
+func TestExpander_ExpandParameterOperators(t *testing.T) {
+	exp, _ := newParameterTestExpander()
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		// Значения по умолчанию и альтернативные значения
+		{"${UNSET:-default}", "default"},
+		{"${EMPTY:-default}", "default"},
+		{"${EMPTY-default}", ""},
+		{"${UNSET-default}", "default"},
+		{"${WORD:-default}", "hello world"},
+		{"${UNSET:-$WORD}", "hello world"},
+		{"${UNSET:-${EMPTY:-nested}}", "nested"},
+		{`${UNSET:-"a b"}`, "a b"},
+		{"${UNSET:-'$WORD'}", "$WORD"},
+		{"${WORD:+alt}", "alt"},
+		{"${EMPTY:+alt}", ""},
+		{"${EMPTY+alt}", "alt"},
+		{"${UNSET+alt}", ""},
+
+		// Длина
+		{"${#WORD}", "11"},
+		{"${#RU}", "6"},
+		{"${#UNSET}", "0"},
+
+		// Удаление префикса и суффикса
+		{"${FILE#*/}", "home/user/archive.tar.gz"},
+		{"${FILE##*/}", "archive.tar.gz"},
+		{"${FILE%.*}", "/home/user/archive.tar"},
+		{"${FILE%%.*}", "/home/user/archive"},
+		{"${FILE%/*}", "/home/user"},
+		{"${FILE#nomatch}", "/home/user/archive.tar.gz"},
+		{"${STAR#\"*\"}", ""},
+		{"${WORD#${UNSET:-\"h*\"}}", "hello world"},
+		{"${WORD#h[aeiou]}", "llo world"},
+		{"${RU%?}", "приве"},
+
+		// Подстрока
+		{"${WORD:6}", "world"},
+		{"${WORD:0:5}", "hello"},
+		{"${WORD: -5}", "world"},
+		{"${WORD:(-5):3}", "wor"},
+		{"${WORD:2:-2}", "llo wor"},
+		{"${WORD:1+1:2*2}", "llo "},
+		{"${WORD:20}", ""},
+		{"${RU:1:3}", "рив"},
+
+		// Замена
+		{"${WORD/o/0}", "hell0 world"},
+		{"${WORD//o/0}", "hell0 w0rld"},
+		{"${WORD/#hello/bye}", "bye world"},
+		{"${WORD/%world/there}", "hello there"},
+		{"${WORD/#world/x}", "hello world"},
+		{"${WORD/l*o/L}", "heLrld"},
+		{"${WORD// /_}", "hello_world"},
+		{"${WORD/o}", "hell world"},
+		{"${FILE//\\//:}", ":home:user:archive.tar.gz"},
+
+		// Регистр
+		{"${WORD^}", "Hello world"},
+		{"${WORD^^}", "HELLO WORLD"},
+		{"${RU^^}", "ПРИВЕТ"},
+		{"${WORD^^[lo]}", "heLLO wOrLd"},
+		{"${WORD,,}", "hello world"},
+
+		// Массивы
+		{"${ARR[1]}", "one"},
+		{"${ARR[0]}", "zero"},
+		{"$ARR", "zero"},
+		{"${ARR[-1]}", "two"},
+		{"${ARR[1+1]}", "two"},
+		{"${ARR[5]:-none}", "none"},
+		{"${ARR[@]}", "zero one two"},
+		{"${#ARR[@]}", "3"},
+		{"${#ARR[1]}", "3"},
+		{"${ARR[2]^^}", "TWO"},
+		{"${WORD[0]}", "hello world"},
+		{"${#UNSET[@]}", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			expanded, err := exp.expandArgument(&parser.Argument{Value: tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expanded.Value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, expanded.Value)
+			}
+		})
+	}
+}
+
+// TestExpander_ExpandParameterAssignDefault проверяет присваивание значения по умолчанию.
+func TestExpander_ExpandParameterAssignDefault(t *testing.T) {
+	exp, env := newParameterTestExpander()
+
+	expanded, err := exp.expandArgument(&parser.Argument{Value: "${NEW:=value}"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expanded.Value != "value" {
+		t.Errorf("expected %q, got %q", "value", expanded.Value)
+	}
+	if v, _ := env.Get("NEW"); v != "value" {
+		t.Errorf("NEW = %q, expected %q", v, "value")
+	}
+
+	// Заданная переменная не перезаписывается
+	if _, err := exp.expandArgument(&parser.Argument{Value: "${NEW:=other}"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := env.Get("NEW"); v != "value" {
+		t.Errorf("NEW = %q after second assignment, expected %q", v, "value")
+	}
+}
+
+// TestExpander_ExpandParameterErrors проверяет ошибки подстановок ${...}.
+func TestExpander_ExpandParameterErrors(t *testing.T) {
+	exp, _ := newParameterTestExpander()
+
+	tests := []struct {
+		value    string
+		errorMsg string
+	}{
+		{"${UNSET:?}", "UNSET: parameter null or not set"},
+		{"${EMPTY:?must be set}", "EMPTY: must be set"},
+		{"${UNSET?custom}", "UNSET: custom"},
+		{"${WORD@}", "${WORD@}: bad substitution"},
+		{"${}", "${}: bad substitution"},
+		{"${#WORD:-x}", "${#WORD:-x}: bad substitution"},
+		{"${WORD:x/0}", "division by zero"},
+		{"${WORD", "unclosed ${ variable"},
+		{"${ARR[7]:=x}", "cannot assign in this way"},
+		{"${ARR[1+]}", "bad array subscript"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, err := exp.expandArgument(&parser.Argument{Value: tt.value})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.errorMsg)
+			}
+			// Только ошибка ${VAR:?word} имеет тип UnsetParameterError
+			var unset *UnsetParameterError
+			if errors.As(err, &unset) != strings.Contains(tt.value, "?") {
+				t.Errorf("errors.As(%v, *UnsetParameterError) = %v", err, !strings.Contains(tt.value, "?"))
+			}
+		})
+	}
+
+	// ${VAR:?} с заданной переменной не является ошибкой
+	if expanded, err := exp.expandArgument(&parser.Argument{Value: "${WORD:?}"}); err != nil || expanded.Value != "hello world" {
+		t.Errorf("${WORD:?} = %q, %v", expanded.Value, err)
+	}
+}
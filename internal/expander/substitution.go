@@ -0,0 +1,83 @@
+package expander
+
+import (
+	"fmt"
+	"strings"
+
+	"gocli/internal/lexer"
+)
+
+// expandCommandSubstitution выполняет подстановку команды $(команда).
+// Если содержимое скобок целиком заключено во вторые скобки ($((...))),
+// выполняется арифметическая подстановка.
+// Вложенные подстановки и кавычки внутри команды обрабатываются при её выполнении.
+// Возвращает новую позицию в строке после закрывающей скобки.
+func (e *Expander) expandCommandSubstitution(result *strings.Builder, s string, dollarIdx int) (int, error) {
+	end, err := lexer.SubstitutionEnd(s, dollarIdx)
+	if err != nil {
+		return 0, err
+	}
+
+	inner := s[dollarIdx+2 : end-1]
+	if isArithmetic(inner) {
+		value, err := e.expandArithmetic(inner[1 : len(inner)-1])
+		if err != nil {
+			return 0, err
+		}
+		result.WriteString(value)
+		return end, nil
+	}
+
+	output, err := e.runCommand(inner)
+	if err != nil {
+		return 0, err
+	}
+	result.WriteString(output)
+	return end, nil
+}
+
+// expandBackquote выполняет подстановку команды в обратных кавычках `команда`.
+// Внутри обратных кавычек \$, \` и \\ заменяются на $, ` и \ перед выполнением команды,
+// что позволяет записывать вложенные подстановки в обратных кавычках.
+// Возвращает новую позицию в строке после закрывающей обратной кавычки.
+func (e *Expander) expandBackquote(result *strings.Builder, s string, idx int) (int, error) {
+	end, err := lexer.SubstitutionEnd(s, idx)
+	if err != nil {
+		return 0, err
+	}
+
+	output, err := e.runCommand(unescapeBackquoted(s[idx+1 : end-1]))
+	if err != nil {
+		return 0, err
+	}
+	result.WriteString(output)
+	return end, nil
+}
+
+// runCommand выполняет команду подстановки и возвращает её вывод
+// без завершающих переводов строки, как в POSIX shell.
+func (e *Expander) runCommand(command string) (string, error) {
+	if e.runner == nil {
+		return "", fmt.Errorf("command substitution is not supported")
+	}
+
+	output, status, err := e.runner(command)
+	if err != nil {
+		return "", fmt.Errorf("command substitution failed: %w", err)
+	}
+	e.substitutionStatus, e.substituted = status, true
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// unescapeBackquoted убирает экранирование \$, \` и \\ из текста в обратных кавычках.
+// Прочие обратные слеши сохраняются.
+func unescapeBackquoted(s string) string {
+	var result strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("$`\\", s[i+1]) != -1 {
+			i++
+		}
+		result.WriteByte(s[i])
+	}
+	return result.String()
+}
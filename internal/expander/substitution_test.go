@@ -0,0 +1,109 @@
+package expander
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// echoRunner имитирует выполнение команды: возвращает текст команды с переводами строк,
+// чтобы проверить, какая команда была передана на выполнение.
+func echoRunner(command string) (string, int, error) {
+	return "<" + command + ">\n\n", 0, nil
+}
+
+// TestExpander_ExpandCommandSubstitution проверяет подстановку команд $(...) и `...`.
+func TestExpander_ExpandCommandSubstitution(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("VAR", "value")
+	exp := NewExpander(env)
+	exp.SetCommandRunner(echoRunner)
+
+	tests := []struct {
+		name     string
+		arg      *parser.Argument
+		expected string
+	}{
+		{"dollar paren", &parser.Argument{Value: "$(echo hi)"}, "<echo hi>"},
+		{"with surrounding text", &parser.Argument{Value: "a$(pwd)b"}, "a<pwd>b"},
+		{"in double quotes", &parser.Argument{Value: "$VAR $(cat | wc)", Quoted: true, QuoteType: parser.DoubleQuote}, "value <cat | wc>"},
+		{"nested", &parser.Argument{Value: "$(echo $(pwd))"}, "<echo $(pwd)>"},
+		{"quoted paren inside", &parser.Argument{Value: `$(echo ")")`}, `<echo ")">`},
+		{"backquote", &parser.Argument{Value: "`date`"}, "<date>"},
+		{"escaped backquote inside backquote", &parser.Argument{Value: "`echo \\`pwd\\` \\$HOME`"}, "<echo `pwd` $HOME>"},
+		{"escaped backquote", &parser.Argument{Value: "\\`date"}, "`date"},
+		{"single quotes", &parser.Argument{Value: "$(pwd)", Quoted: true, QuoteType: parser.SingleQuote}, "$(pwd)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := exp.expandArgument(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expanded.Value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, expanded.Value)
+			}
+		})
+	}
+}
+
+// TestExpander_ExpandCommandSubstitutionErrors проверяет ошибки подстановки команд.
+func TestExpander_ExpandCommandSubstitutionErrors(t *testing.T) {
+	exp := NewExpander(environment.NewEnvironment())
+
+	// Без CommandRunner подстановка команд невозможна
+	if _, err := exp.expandArgument(&parser.Argument{Value: "$(pwd)"}); err == nil {
+		t.Error("expected error without command runner")
+	}
+
+	failure := errors.New("parse error")
+	exp.SetCommandRunner(func(string) (string, int, error) { return "", 0, failure })
+	if _, err := exp.expandArgument(&parser.Argument{Value: "$(pwd)"}); !errors.Is(err, failure) {
+		t.Errorf("expected runner error, got %v", err)
+	}
+
+	exp.SetCommandRunner(echoRunner)
+	if _, err := exp.expandArgument(&parser.Argument{Value: "$(pwd"}); err == nil || !strings.Contains(err.Error(), "unterminated") {
+		t.Errorf("expected unterminated substitution error, got %v", err)
+	}
+}
+
+// TestExpander_SubstitutionStatus проверяет сохранение кода возврата последней
+// подстановки команды в раскрытой команде.
+func TestExpander_SubstitutionStatus(t *testing.T) {
+	exp := NewExpander(environment.NewEnvironment())
+	exp.SetCommandRunner(func(command string) (string, int, error) {
+		if command == "false" {
+			return "", 1, nil
+		}
+		return "", 0, nil
+	})
+
+	tests := []struct {
+		name        string
+		cmd         *parser.Command
+		status      int
+		substituted bool
+	}{
+		{"no substitution", &parser.Command{Name: "echo", Args: []*parser.Argument{{Value: "a"}}}, 0, false},
+		{"failed substitution", &parser.Command{Assignments: []*parser.Assignment{{Name: "x", Value: &parser.Argument{Value: "$(false)"}}}}, 1, true},
+		{"last substitution wins", &parser.Command{Assignments: []*parser.Assignment{
+			{Name: "x", Value: &parser.Argument{Value: "$(false)"}},
+			{Name: "y", Value: &parser.Argument{Value: "`true`"}},
+		}}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exp.ExpandCommand(tt.cmd); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status, substituted := exp.SubstitutionStatus(); status != tt.status || substituted != tt.substituted {
+				t.Errorf("SubstitutionStatus() = %d, %v, expected %d, %v", status, substituted, tt.status, tt.substituted)
+			}
+		})
+	}
+}
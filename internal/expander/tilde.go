@@ -0,0 +1,146 @@
+package expander
+
+import (
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+
+	"gocli/internal/parser"
+)
+
+// expandTilde раскрывает тильду в начале слова: префикс до первого / заменяется каталогом.
+//   - ~ - домашний каталог текущего пользователя ($HOME);
+//   - ~user - домашний каталог пользователя user из базы пользователей;
+//   - ~+ - текущий каталог ($PWD), ~- - предыдущий каталог ($OLDPWD).
+//
+// Если префикс не удается раскрыть (неизвестный пользователь, OLDPWD не задан),
+// слово остается без изменений. Подставленный каталог экранируется,
+// чтобы символы $, `, \ и метасимволы шаблонов в нем не вызывали повторных подстановок.
+func (e *Expander) expandTilde(word string) string {
+	if !strings.HasPrefix(word, "~") {
+		return word
+	}
+
+	end := strings.IndexByte(word, '/')
+	if end == -1 {
+		end = len(word)
+	}
+
+	dir, ok := e.tildeDirectory(word[1:end])
+	if !ok {
+		return word
+	}
+	return escapeLiteral(dir) + word[end:]
+}
+
+// expandAssignmentTildes раскрывает тильды в значении присваивания:
+// в начале значения и после каждого двоеточия, как в PATH=~/bin:~user/bin.
+func (e *Expander) expandAssignmentTildes(value string) string {
+	if !strings.Contains(value, "~") {
+		return value
+	}
+
+	parts := strings.Split(value, ":")
+	for i, part := range parts {
+		parts[i] = e.expandTilde(part)
+	}
+	return strings.Join(parts, ":")
+}
+
+// expandWordTilde раскрывает тильду в начале слова, состоящего из частей.
+// Тильда раскрывается только в первой части без кавычек, и весь префикс до / должен
+// быть без кавычек: в ~"user" и "~" тильда остается буквальной.
+func (e *Expander) expandWordTilde(parts []parser.WordPart) []parser.WordPart {
+	if len(parts) == 0 || parts[0].QuoteType != parser.NoQuote {
+		return parts
+	}
+	if len(parts) > 1 && !strings.Contains(parts[0].Value, "/") {
+		return parts
+	}
+
+	result := slices.Clone(parts)
+	result[0].Value = e.expandTilde(result[0].Value)
+	return result
+}
+
+// expandAssignmentWordTildes раскрывает тильды в частях значения присваивания без кавычек:
+// в начале значения и после каждого двоеточия.
+func (e *Expander) expandAssignmentWordTildes(parts []parser.WordPart) []parser.WordPart {
+	result := slices.Clone(parts)
+	for i, part := range result {
+		if part.QuoteType != parser.NoQuote {
+			continue
+		}
+		if i == 0 {
+			result[i].Value = e.expandAssignmentTildes(part.Value)
+			continue
+		}
+		// Текст до первого двоеточия продолжает предыдущую часть и тильду не раскрывает
+		if colon := strings.IndexByte(part.Value, ':'); colon != -1 {
+			result[i].Value = part.Value[:colon+1] + e.expandAssignmentTildes(part.Value[colon+1:])
+		}
+	}
+	return result
+}
+
+// tildeDirectory возвращает каталог для префикса тильды (текста между ~ и /).
+func (e *Expander) tildeDirectory(prefix string) (string, bool) {
+	switch prefix {
+	case "":
+		if home, ok := e.environment.Get("HOME"); ok {
+			return home, true
+		}
+		home, err := os.UserHomeDir()
+		return home, err == nil
+	case "+":
+		if pwd, ok := e.environment.Get("PWD"); ok && pwd != "" {
+			return pwd, true
+		}
+		if dir := e.environment.Dir(); dir != "" {
+			return dir, true
+		}
+		wd, err := os.Getwd()
+		return wd, err == nil
+	case "-":
+		oldPwd, ok := e.environment.Get("OLDPWD")
+		return oldPwd, ok && oldPwd != ""
+	}
+
+	if !isLoginName(prefix) {
+		return "", false
+	}
+	u, err := user.Lookup(prefix)
+	if err != nil {
+		return "", false
+	}
+	return u.HomeDir, true
+}
+
+// isLoginName проверяет, может ли строка быть именем пользователя.
+// Префиксы с кавычками, подстановками и другими специальными символами не раскрываются.
+func isLoginName(name string) bool {
+	for _, r := range name {
+		if !isValidVariableChar(r) && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeLiteral экранирует символы $, `, \, *, ? и [, чтобы текст без кавычек
+// не подвергался подстановкам и раскрытию шаблонов.
+func escapeLiteral(s string) string {
+	const special = "$`\\*?["
+	if !strings.ContainsAny(s, special) {
+		return s
+	}
+	var result strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			result.WriteRune('\\')
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
@@ -0,0 +1,159 @@
+package expander
+
+import (
+	"os"
+	"os/user"
+	"reflect"
+	"testing"
+
+	"gocli/internal/environment"
+	"gocli/internal/parser"
+)
+
+// TestExpander_ExpandTilde проверяет раскрытие тильды в начале слова.
+func TestExpander_ExpandTilde(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("HOME", "/home/tester")
+	env.Set("PWD", "/work")
+	env.Set("OLDPWD", "/previous")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"~", "/home/tester"},
+		{"~/notes.txt", "/home/tester/notes.txt"},
+		{"~+", "/work"},
+		{"~+/src", "/work/src"},
+		{"~-", "/previous"},
+		{"~-/dir", "/previous/dir"},
+		{"a~", "a~"},
+		{"~no_such_user_gocli", "~no_such_user_gocli"},
+		{"~$USER", "~$USER"},
+		{"plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := exp.expandTilde(tt.word); got != tt.expected {
+				t.Errorf("expandTilde(%q) = %q, expected %q", tt.word, got, tt.expected)
+			}
+		})
+	}
+
+	// С пустым OLDPWD ~- остается буквальным
+	env.Set("OLDPWD", "")
+	if got := exp.expandTilde("~-"); got != "~-" {
+		t.Errorf("expandTilde(~-) without OLDPWD = %q, expected ~-", got)
+	}
+}
+
+// TestExpander_ExpandTildeUser проверяет раскрытие ~user через базу пользователей.
+func TestExpander_ExpandTildeUser(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot determine current user: %v", err)
+	}
+	if !isLoginName(current.Username) {
+		t.Skipf("unsupported user name %q", current.Username)
+	}
+
+	exp := NewExpander(environment.NewEnvironment())
+	expected := current.HomeDir + "/bin"
+	if got := exp.expandTilde("~" + current.Username + "/bin"); got != expected {
+		t.Errorf("expandTilde(~%s/bin) = %q, expected %q", current.Username, got, expected)
+	}
+}
+
+// TestExpander_ExpandTildeInArguments проверяет раскрытие тильды в аргументах,
+// перенаправлениях и присваиваниях.
+func TestExpander_ExpandTildeInArguments(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("HOME", "/home/$tester")
+	env.Set("OLDPWD", "/old")
+	exp := NewExpander(env)
+
+	cmd, err := exp.ExpandCommand(&parser.Command{
+		Name: "cat",
+		Args: []*parser.Argument{
+			{Value: "~/a"},
+			{Value: "~/a", Quoted: true, QuoteType: parser.DoubleQuote},
+			{Value: "~{/x,-}"},
+		},
+		Assignments: []*parser.Assignment{
+			{Name: "P", Value: &parser.Argument{Value: "~/bin:~-/bin:/usr/~"}},
+			{Name: "Q", Value: &parser.Argument{Value: "~", Quoted: true, QuoteType: parser.SingleQuote}},
+		},
+		Redirects: []*parser.Redirect{
+			{Fd: 1, Op: parser.RedirectOutput, Target: &parser.Argument{Value: "~/out.txt"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Символ $ из HOME не вызывает повторной подстановки
+	expectedArgs := []string{"/home/$tester/a", "~/a", "/home/$tester/x", "/old"}
+	if got := argumentValues(cmd.Args); !reflect.DeepEqual(got, expectedArgs) {
+		t.Errorf("args = %q, expected %q", got, expectedArgs)
+	}
+	if got := cmd.Assignments[0].Value.Value; got != "/home/$tester/bin:/old/bin:/usr/~" {
+		t.Errorf("assignment P = %q", got)
+	}
+	if got := cmd.Assignments[1].Value.Value; got != "~" {
+		t.Errorf("quoted assignment Q = %q, expected ~", got)
+	}
+	if got := cmd.Redirects[0].Target.Value; got != "/home/$tester/out.txt" {
+		t.Errorf("redirect target = %q", got)
+	}
+}
+
+// TestExpander_ExpandTildeFallback проверяет, что ~+ при пустом PWD раскрывается в текущий каталог.
+func TestExpander_ExpandTildeFallback(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("PWD", "")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewExpander(env).expandTilde("~+"); got != wd {
+		t.Errorf("expandTilde(~+) with empty PWD = %q, expected %q", got, wd)
+	}
+}
+
+// TestExpander_ExpandTildeInCompositeWords проверяет, что тильда раскрывается,
+// только если префикс до / не содержит кавычек.
+func TestExpander_ExpandTildeInCompositeWords(t *testing.T) {
+	env := environment.NewEnvironment()
+	env.Set("HOME", "/home/tester")
+	exp := NewExpander(env)
+
+	tests := []struct {
+		name     string
+		arg      *parser.Argument
+		expected string
+	}{
+		{"quoted path after prefix", compositeArgument(
+			parser.WordPart{Value: "~/"},
+			parser.WordPart{Value: "my dir", QuoteType: parser.DoubleQuote},
+		), "/home/tester/my dir"},
+		{"quoted user name", compositeArgument(
+			parser.WordPart{Value: "~"},
+			parser.WordPart{Value: "root", QuoteType: parser.DoubleQuote},
+		), "~root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := exp.expandArgumentFields(tt.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := argumentValues(args); len(got) != 1 || got[0] != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package glob
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Expand раскрывает шаблон в отсортированный список путей файловой системы.
+// Шаблон разбивается по / на компоненты; компоненты с метасимволами сопоставляются
+// с содержимым каталогов, остальные используются как есть.
+// Файлы, имена которых начинаются с точки, подходят только под компоненты,
+// которые сами начинаются с точки.
+// Относительный шаблон сопоставляется с содержимым каталога dir (пустой dir - рабочий
+// каталог процесса), при этом найденные пути остаются относительными.
+// Если подходящих путей нет, возвращается пустой список.
+func Expand(dir, pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	components := strings.Split(pattern, "/")
+	prefixes := []string{""}
+	if components[0] == "" {
+		// Абсолютный путь: первый компонент пустой
+		prefixes = []string{"/"}
+		components = components[1:]
+	}
+
+	for i, component := range components {
+		last := i == len(components)-1
+		var next []string
+
+		for _, prefix := range prefixes {
+			switch {
+			case component == "":
+				// Пустой компонент (двойной или завершающий /): путь должен быть каталогом
+				if isDir(resolve(dir, prefix)) {
+					next = append(next, prefix)
+				}
+			case !HasMeta(component):
+				next = append(next, join(prefix, Unescape(component), last))
+			default:
+				matches, err := matchDir(resolve(dir, prefix), component)
+				if err != nil {
+					return nil, err
+				}
+				for _, name := range matches {
+					next = append(next, join(prefix, name, last))
+				}
+			}
+		}
+
+		prefixes = next
+		if len(prefixes) == 0 {
+			return nil, nil
+		}
+	}
+
+	// Буквальные компоненты не проверялись по ходу разбора, поэтому
+	// отбрасываем несуществующие пути в конце
+	var result []string
+	for _, path := range prefixes {
+		if _, err := os.Lstat(resolve(dir, strings.TrimSuffix(path, "/"))); err == nil || path == "/" {
+			result = append(result, path)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// matchDir возвращает имена записей каталога dir, соответствующие компоненту шаблона.
+// Отсутствующий или нечитаемый каталог дает пустой результат.
+func matchDir(dir, component string) ([]string, error) {
+	path := dir
+	if path == "" {
+		path = "."
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, nil
+	}
+
+	allowHidden := strings.HasPrefix(component, ".") || strings.HasPrefix(component, `\.`)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") && !allowHidden {
+			continue
+		}
+		if Match(component, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// resolve возвращает путь path относительно каталога dir. Абсолютный путь и путь
+// при пустом dir не меняются; пустой path означает сам каталог dir.
+func resolve(dir, path string) string {
+	if dir == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "/") {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// join добавляет имя к префиксу пути. Промежуточные компоненты завершаются /,
+// чтобы следующий компонент просто дописывался в конец.
+func join(prefix, name string, last bool) string {
+	if last {
+		return prefix + name
+	}
+	return prefix + name + "/"
+}
+
+// isDir проверяет, является ли путь каталогом (пустой путь означает текущий каталог).
+func isDir(path string) bool {
+	if path == "" {
+		path = "."
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,96 @@
+package glob
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// createTree создает в каталоге dir файлы и каталоги (имена с / на конце).
+func createTree(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if name[len(name)-1] == '/' {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestExpand проверяет раскрытие шаблонов относительно заданного каталога:
+// найденные пути остаются относительными, а рабочий каталог процесса не используется.
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, "b.go", "a.go", "c.txt", ".hidden.go", "src/", "src/x.go", "src/y.txt", "logs/one.txt", "logs/two.txt")
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"*.go", []string{"a.go", "b.go"}},
+		{"?.txt", []string{"c.txt"}},
+		{"[ab].go", []string{"a.go", "b.go"}},
+		{".*.go", []string{".hidden.go"}},
+		{"*/*.txt", []string{"logs/one.txt", "logs/two.txt", "src/y.txt"}},
+		{"src/*", []string{"src/x.go", "src/y.txt"}},
+		{"./src/*.go", []string{"./src/x.go"}},
+		{"*/", []string{"logs/", "src/"}},
+		{"*.none", nil},
+		{"missing/*", nil},
+		{"a.go/*", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := Expand(dir, tt.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expand(%q) = %q, expected %q", tt.pattern, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestExpand_CurrentDirectory проверяет, что при пустом каталоге шаблон
+// раскрывается относительно рабочего каталога процесса.
+func TestExpand_CurrentDirectory(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, "b.go", "a.go", "c.txt")
+	t.Chdir(dir)
+
+	got, err := Expand("", "*.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"a.go", "b.go"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expand = %q, expected %q", got, expected)
+	}
+}
+
+// TestExpand_AbsolutePath проверяет раскрытие шаблона с абсолютным путем.
+func TestExpand_AbsolutePath(t *testing.T) {
+	dir := filepath.ToSlash(t.TempDir())
+	createTree(t, dir, "one.log", "two.log", "three.txt")
+
+	got, err := Expand(t.TempDir(), dir+"/*.log")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{dir + "/one.log", dir + "/two.log"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expand = %q, expected %q", got, expected)
+	}
+}
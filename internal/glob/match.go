@@ -0,0 +1,236 @@
+// Package glob реализует сопоставление с шаблонами shell'а (*, ?, [...])
+// и раскрытие шаблонов в список путей файловой системы.
+package glob
+
+import (
+	"strings"
+	"unicode"
+)
+
+// HasMeta проверяет, содержит ли шаблон неэкранированные метасимволы *, ? или [.
+func HasMeta(pattern string) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '*', '?':
+			return true
+		case '[':
+			if _, _, ok := parseBracket([]rune(pattern[i:]), 0); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// QuoteMeta экранирует метасимволы шаблона, чтобы строка сопоставлялась буквально.
+func QuoteMeta(s string) string {
+	var result strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			result.WriteRune('\\')
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
+// Unescape убирает экранирование из шаблона: \x превращается в x.
+func Unescape(pattern string) string {
+	if !strings.Contains(pattern, `\`) {
+		return pattern
+	}
+	var result strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			i++
+		}
+		result.WriteByte(pattern[i])
+	}
+	return result.String()
+}
+
+// Match проверяет, соответствует ли строка name шаблону pattern целиком.
+//
+// Поддерживаемый синтаксис:
+//   - * - любая последовательность символов (в том числе пустая)
+//   - ? - любой один символ
+//   - [abc], [a-z] - один символ из набора; [!a] и [^a] - символ не из набора
+//   - [[:alpha:]], [[:digit:]] и другие классы символов POSIX внутри скобок
+//   - \x - символ x буквально
+//
+// Символ / не имеет особого значения: разбиение пути на компоненты выполняет Expand.
+func Match(pattern, name string) bool {
+	return match([]rune(pattern), []rune(name))
+}
+
+// match сопоставляет руны с шаблоном. При встрече * запоминается позиция
+// для возврата, что дает линейное по числу звездочек количество откатов.
+func match(pattern, name []rune) bool {
+	p, n := 0, 0
+	starP, starN := -1, 0
+
+	for n < len(name) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starN = p, n
+				p++
+				continue
+			case '?':
+				p++
+				n++
+				continue
+			case '[':
+				if matched, next, ok := parseBracket(pattern, p); ok {
+					if matched(name[n]) {
+						p = next
+						n++
+						continue
+					}
+					break
+				}
+				if name[n] == '[' {
+					p++
+					n++
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == name[n] {
+					p += 2
+					n++
+					continue
+				}
+				if p+1 == len(pattern) && name[n] == '\\' {
+					p++
+					n++
+					continue
+				}
+			default:
+				if pattern[p] == name[n] {
+					p++
+					n++
+					continue
+				}
+			}
+		}
+
+		// Несовпадение: возвращаемся к последней *, расширяя её на один символ
+		if starP == -1 {
+			return false
+		}
+		starN++
+		p, n = starP+1, starN
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// parseBracket разбирает выражение в квадратных скобках, начинающееся с pattern[start] == '['.
+// Возвращает функцию проверки символа, позицию после закрывающей скобки
+// и флаг успешного разбора (false, если закрывающей скобки нет).
+func parseBracket(pattern []rune, start int) (func(rune) bool, int, bool) {
+	i := start + 1
+	negate := false
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negate = true
+		i++
+	}
+
+	var checks []func(rune) bool
+	first := true
+
+	for i < len(pattern) {
+		r := pattern[i]
+
+		if r == ']' && !first {
+			matched := func(c rune) bool {
+				for _, check := range checks {
+					if check(c) {
+						return !negate
+					}
+				}
+				return negate
+			}
+			return matched, i + 1, true
+		}
+		first = false
+
+		// Класс символов [:name:]
+		if r == '[' && i+1 < len(pattern) && pattern[i+1] == ':' {
+			if end := indexClassEnd(pattern, i+2); end != -1 {
+				if check := characterClass(string(pattern[i+2 : end])); check != nil {
+					checks = append(checks, check)
+					i = end + 2
+					continue
+				}
+			}
+		}
+
+		if r == '\\' && i+1 < len(pattern) {
+			i++
+			r = pattern[i]
+		}
+
+		// Диапазон a-z
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			low, high := r, pattern[i+2]
+			checks = append(checks, func(c rune) bool { return c >= low && c <= high })
+			i += 3
+			continue
+		}
+
+		literal := r
+		checks = append(checks, func(c rune) bool { return c == literal })
+		i++
+	}
+
+	return nil, 0, false
+}
+
+// indexClassEnd ищет завершение ":]" класса символов, начиная с позиции start.
+func indexClassEnd(pattern []rune, start int) int {
+	for i := start; i+1 < len(pattern); i++ {
+		if pattern[i] == ':' && pattern[i+1] == ']' {
+			return i
+		}
+	}
+	return -1
+}
+
+// characterClass возвращает функцию проверки для класса символов POSIX
+// или nil, если класс неизвестен.
+func characterClass(name string) func(rune) bool {
+	switch name {
+	case "alpha":
+		return unicode.IsLetter
+	case "digit":
+		return func(r rune) bool { return r >= '0' && r <= '9' }
+	case "alnum":
+		return func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	case "upper":
+		return unicode.IsUpper
+	case "lower":
+		return unicode.IsLower
+	case "space":
+		return unicode.IsSpace
+	case "blank":
+		return func(r rune) bool { return r == ' ' || r == '\t' }
+	case "punct":
+		return unicode.IsPunct
+	case "print":
+		return unicode.IsPrint
+	case "graph":
+		return func(r rune) bool { return unicode.IsPrint(r) && r != ' ' }
+	case "cntrl":
+		return unicode.IsControl
+	case "xdigit":
+		return func(r rune) bool { return strings.ContainsRune("0123456789abcdefABCDEF", r) }
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package glob
+
+import "testing"
+
+// TestMatch проверяет сопоставление строк с шаблонами.
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*", "", true},
+		{"*", "file.go", true},
+		{"*.go", "main.go", true},
+		{"*.go", "main.txt", false},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"?", "a", true},
+		{"?", "", false},
+		{"??.txt", "ab.txt", true},
+		{"??.txt", "abc.txt", false},
+		{"[abc]", "b", true},
+		{"[abc]", "d", false},
+		{"[a-c]x", "bx", true},
+		{"[!a-c]x", "bx", false},
+		{"[^a-c]x", "dx", true},
+		{"[]]", "]", true},
+		{"[!]]", "a", true},
+		{"[[:digit:]]*", "1abc", true},
+		{"[[:digit:]]*", "abc", false},
+		{"[[:upper:][:digit:]]", "Q", true},
+		{"[a-", "[a-", true},
+		{"[", "[", true},
+		{`\*`, "*", true},
+		{`\*`, "a", false},
+		{`a\?b`, "a?b", true},
+		{"файл*", "файл.txt", true},
+		{"?ы", "вы", true},
+		{"dir/*", "dir/file", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"~"+tt.name, func(t *testing.T) {
+			if got := Match(tt.pattern, tt.name); got != tt.expected {
+				t.Errorf("Match(%q, %q) = %v, expected %v", tt.pattern, tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestHasMeta проверяет поиск метасимволов в шаблоне.
+func TestHasMeta(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected bool
+	}{
+		{"plain.txt", false},
+		{"*.txt", true},
+		{"file?", true},
+		{"[ab]", true},
+		{"[", false},
+		{"a]", false},
+		{`\*`, false},
+		{`\\*`, true},
+	}
+
+	for _, tt := range tests {
+		if got := HasMeta(tt.pattern); got != tt.expected {
+			t.Errorf("HasMeta(%q) = %v, expected %v", tt.pattern, got, tt.expected)
+		}
+	}
+}
+
+// TestQuoteMeta проверяет, что экранированная строка сопоставляется только сама с собой.
+func TestQuoteMeta(t *testing.T) {
+	s := `a*b?[c]\d`
+	quoted := QuoteMeta(s)
+
+	if HasMeta(quoted) {
+		t.Errorf("QuoteMeta(%q) = %q still has metacharacters", s, quoted)
+	}
+	if !Match(quoted, s) {
+		t.Errorf("Match(%q, %q) = false, expected true", quoted, s)
+	}
+	if Match(quoted, "aXb?[c]\\d") {
+		t.Errorf("quoted pattern %q should not match other strings", quoted)
+	}
+	if Unescape(quoted) != s {
+		t.Errorf("Unescape(%q) = %q, expected %q", quoted, Unescape(quoted), s)
+	}
+}
@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// State определяет состояние фонового задания.
+type State int
+
+const (
+	Running State = iota // Задание выполняется
+	Done                 // Задание завершилось
+)
+
+// Job представляет фоновое задание - список команд, запущенный с оператором &.
+// Хранит процессы внешних программ задания, его состояние и код возврата.
+type Job struct {
+	ID      int    // Номер задания в таблице (%1, %2, ...)
+	Command string // Текст команды для вывода в jobs и уведомлениях
+	pid     int    // Идентификатор задания для $! (см. Table.Start)
+
+	mu        sync.Mutex
+	state     State
+	status    int           // Код возврата (после завершения)
+	processes []*os.Process // Процессы внешних программ задания
+	done      chan struct{} // Закрывается при завершении задания
+}
+
+// newJob создает задание в состоянии Running.
+func newJob(id, pid int, command string) *Job {
+	return &Job{
+		ID:      id,
+		Command: command,
+		pid:     pid,
+		state:   Running,
+		done:    make(chan struct{}),
+	}
+}
+
+// AddProcess регистрирует процесс внешней программы, запущенный заданием.
+func (j *Job) AddProcess(process *os.Process) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.processes = append(j.processes, process)
+}
+
+// Pid возвращает идентификатор задания для $!. Он известен сразу после запуска,
+// даже если задание еще не запустило (или вовсе не запускает) внешних программ.
+func (j *Job) Pid() int {
+	return j.pid
+}
+
+// Pids возвращает PID всех процессов задания.
+func (j *Job) Pids() []int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	pids := make([]int, len(j.processes))
+	for i, process := range j.processes {
+		pids[i] = process.Pid
+	}
+	return pids
+}
+
+// hasPid проверяет, что pid - идентификатор задания или PID одного из его процессов.
+func (j *Job) hasPid(pid int) bool {
+	return pid == j.pid || slices.Contains(j.Pids(), pid)
+}
+
+// State возвращает текущее состояние задания.
+func (j *Job) State() State {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// Status возвращает код возврата завершившегося задания.
+func (j *Job) Status() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// Wait блокируется до завершения задания и возвращает его код возврата.
+func (j *Job) Wait() int {
+	<-j.done
+	return j.Status()
+}
+
+// finish переводит задание в состояние Done с указанным кодом возврата.
+func (j *Job) finish(status int) {
+	j.mu.Lock()
+	j.state = Done
+	j.status = status
+	j.mu.Unlock()
+	close(j.done)
+}
+
+// Continue возобновляет выполнение процессов задания (аналог SIGCONT).
+func (j *Job) Continue() error {
+	j.mu.Lock()
+	processes := append([]*os.Process(nil), j.processes...)
+	j.mu.Unlock()
+
+	for _, process := range processes {
+		if err := continueProcess(process); err != nil {
+			return fmt.Errorf("cannot continue process %d: %w", process.Pid, err)
+		}
+	}
+	return nil
+}
+
+// StateString возвращает описание состояния задания в формате bash:
+// "Running", "Done" или "Exit N" для завершившегося с ошибкой задания.
+func (j *Job) StateString() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.state == Running {
+		return "Running"
+	}
+	if j.status != 0 {
+		return fmt.Sprintf("Exit %d", j.status)
+	}
+	return "Done"
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package jobs
+
+import (
+	"os"
+	"syscall"
+)
+
+// continueProcess отправляет процессу сигнал SIGCONT.
+func continueProcess(process *os.Process) error {
+	return process.Signal(syscall.SIGCONT)
+}
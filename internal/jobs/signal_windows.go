@@ -0,0 +1,14 @@
+//go:build windows
+
+package jobs
+
+import (
+	"errors"
+	"os"
+)
+
+// continueProcess не поддерживается в Windows: приостановка процессов
+// сигналами SIGSTOP/SIGCONT в этой системе отсутствует.
+func continueProcess(_ *os.Process) error {
+	return errors.New("job control is not supported on windows")
+}
@@ -0,0 +1,225 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Table хранит фоновые задания shell'а.
+// Задания нумеруются с 1; номер освобождается, когда таблица становится пустой.
+// Последнее запущенное задание считается текущим (%+), предпоследнее - предыдущим (%-).
+// Коды возврата заданий, о завершении которых уже сообщено, сохраняются для wait.
+type Table struct {
+	mu       sync.Mutex
+	jobs     []*Job // Задания в порядке запуска
+	reported []*Job // Завершившиеся задания, удаленные из таблицы после уведомления
+	lastPid  int    // Идентификатор последнего запущенного задания
+}
+
+// firstPid - идентификатор первого задания для $!. Задание выполняется в горутине,
+// а не в отдельном процессе, поэтому его идентификатор - не PID: идентификаторы
+// начинаются выше максимального PID Linux, чтобы не совпасть с PID реального процесса.
+const firstPid = 1 << 22
+
+// maxReported - число последних завершившихся заданий, коды возврата которых хранятся
+// после уведомления. Как и bash, shell помнит коды ограниченного числа заданий.
+const maxReported = 64
+
+// NewTable создает пустую таблицу заданий.
+func NewTable() *Table {
+	return &Table{}
+}
+
+// Start запускает функцию run в фоне как новое задание с текстом command и сразу
+// возвращает его, не дожидаясь запуска команд задания. Задание получает новый
+// идентификатор для $! (см. Job.Pid). Код возврата run становится кодом возврата задания.
+func (t *Table) Start(command string, run func(job *Job) int) *Job {
+	t.mu.Lock()
+	id := 1
+	if len(t.jobs) > 0 {
+		id = t.jobs[len(t.jobs)-1].ID + 1
+	}
+	if t.lastPid == 0 {
+		t.lastPid = firstPid - 1
+	}
+	t.lastPid++
+	job := newJob(id, t.lastPid, command)
+	t.jobs = append(t.jobs, job)
+	// Номер задания освободился и занят новым заданием: %N теперь означает его
+	t.reported = slices.DeleteFunc(t.reported, func(j *Job) bool { return j.ID == id })
+	t.mu.Unlock()
+
+	go func() {
+		job.finish(run(job))
+	}()
+	return job
+}
+
+// List возвращает задания таблицы в порядке запуска.
+func (t *Table) List() []*Job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*Job(nil), t.jobs...)
+}
+
+// Remove удаляет задание из таблицы (и сохраненный код возврата завершившегося
+// задания). Задание продолжает выполняться, но больше не отслеживается shell'ом.
+func (t *Table) Remove(job *Job) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	isJob := func(j *Job) bool { return j == job }
+	t.jobs = slices.DeleteFunc(t.jobs, isJob)
+	t.reported = slices.DeleteFunc(t.reported, isJob)
+}
+
+// Marker возвращает пометку задания в выводе jobs: '+' для текущего,
+// '-' для предыдущего и ' ' для остальных.
+func (t *Table) Marker(job *Job) byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(t.jobs)
+	switch {
+	case n > 0 && t.jobs[n-1] == job:
+		return '+'
+	case n > 1 && t.jobs[n-2] == job:
+		return '-'
+	default:
+		return ' '
+	}
+}
+
+// Find находит задание по спецификации в формате bash:
+// %N (номер), %+ или %% (текущее), %- (предыдущее), %prefix (по началу команды),
+// идентификатор задания из $! или PID процесса задания. Пустая спецификация означает текущее задание.
+func (t *Table) Find(spec string) (*Job, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if spec == "" || spec == "%" || spec == "%+" || spec == "%%" {
+		if len(t.jobs) == 0 {
+			return nil, fmt.Errorf("%s: no current job", displaySpec(spec))
+		}
+		return t.jobs[len(t.jobs)-1], nil
+	}
+
+	if spec == "%-" {
+		if len(t.jobs) < 2 {
+			return nil, fmt.Errorf("%s: no such job", spec)
+		}
+		return t.jobs[len(t.jobs)-2], nil
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "%"); ok {
+		if id, err := strconv.Atoi(rest); err == nil {
+			for _, job := range t.jobs {
+				if job.ID == id {
+					return job, nil
+				}
+			}
+			return nil, fmt.Errorf("%s: no such job", spec)
+		}
+
+		var found *Job
+		for _, job := range t.jobs {
+			if strings.HasPrefix(job.Command, rest) {
+				if found != nil {
+					return nil, fmt.Errorf("%s: ambiguous job spec", spec)
+				}
+				found = job
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("%s: no such job", spec)
+		}
+		return found, nil
+	}
+
+	pid, err := strconv.Atoi(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: not a pid or valid job spec", spec)
+	}
+	for _, job := range t.jobs {
+		if job.hasPid(pid) {
+			return job, nil
+		}
+	}
+	return nil, fmt.Errorf("pid %d is not a child of this shell", pid)
+}
+
+// FindWaitable находит задание для wait: как Find, но номер %N или PID может
+// относиться и к завершившемуся заданию, о котором уже сообщено и которое удалено
+// из таблицы, - тогда wait возвращает его сохраненный код возврата, как в bash.
+func (t *Table) FindWaitable(spec string) (*Job, error) {
+	job, err := t.Find(spec)
+	if err == nil {
+		return job, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, reported := range t.reported {
+		if matchesIDOrPid(reported, spec) {
+			return reported, nil
+		}
+	}
+	return nil, err
+}
+
+// matchesIDOrPid проверяет, что спецификация %N или PID относится к заданию.
+func matchesIDOrPid(job *Job, spec string) bool {
+	if rest, ok := strings.CutPrefix(spec, "%"); ok {
+		id, err := strconv.Atoi(rest)
+		return err == nil && job.ID == id
+	}
+	pid, err := strconv.Atoi(spec)
+	return err == nil && job.hasPid(pid)
+}
+
+// Format возвращает строку описания задания в формате вывода jobs:
+// "[1]+  Running                 sleep 10 &".
+func (t *Table) Format(job *Job) string {
+	command := job.Command
+	if job.State() == Running {
+		command += " &"
+	}
+	return fmt.Sprintf("[%d]%c  %-22s  %s", job.ID, t.Marker(job), job.StateString(), command)
+}
+
+// ReportDone выводит сообщения о завершившихся заданиях и удаляет их из таблицы.
+// Коды возврата удаленных заданий остаются доступны wait (см. RemoveReported).
+// Вызывается перед выводом приглашения, как в bash.
+func (t *Table) ReportDone(w io.Writer) {
+	for _, job := range t.List() {
+		if job.State() != Done {
+			continue
+		}
+		fmt.Fprintln(w, t.Format(job))
+		t.RemoveReported(job)
+	}
+}
+
+// RemoveReported удаляет из таблицы завершившееся задание, о котором сообщено
+// пользователю (перед приглашением или командой jobs). Его код возврата остается
+// доступен wait (см. FindWaitable).
+func (t *Table) RemoveReported(job *Job) {
+	t.Remove(job)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reported = append(t.reported, job)
+	if len(t.reported) > maxReported {
+		t.reported = t.reported[len(t.reported)-maxReported:]
+	}
+}
+
+// displaySpec возвращает спецификацию задания для сообщения об ошибке.
+func displaySpec(spec string) string {
+	if spec == "" {
+		return "current"
+	}
+	return spec
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// startBlocked запускает задание, которое завершается с кодом status после закрытия release.
+func startBlocked(table *Table, command string, status int, release <-chan struct{}) *Job {
+	return table.Start(command, func(*Job) int {
+		<-release
+		return status
+	})
+}
+
+// TestTable_Start проверяет нумерацию заданий и получение кода возврата через Wait.
+func TestTable_Start(t *testing.T) {
+	table := NewTable()
+	release := make(chan struct{})
+
+	first := startBlocked(table, "sleep 1", 0, release)
+	second := startBlocked(table, "false", 3, release)
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("job IDs = %d, %d, expected 1, 2", first.ID, second.ID)
+	}
+	if first.State() != Running {
+		t.Errorf("job state = %v, expected Running", first.State())
+	}
+	if first.Pid() == 0 || second.Pid() != first.Pid()+1 {
+		t.Errorf("job Pid() = %d, %d, expected consecutive identifiers", first.Pid(), second.Pid())
+	}
+
+	close(release)
+
+	if status := second.Wait(); status != 3 {
+		t.Errorf("Wait() = %d, expected 3", status)
+	}
+	if got := second.StateString(); got != "Exit 3" {
+		t.Errorf("StateString() = %q, expected %q", got, "Exit 3")
+	}
+	first.Wait()
+	if got := first.StateString(); got != "Done" {
+		t.Errorf("StateString() = %q, expected %q", got, "Done")
+	}
+}
+
+// TestTable_Find проверяет разбор спецификаций заданий.
+func TestTable_Find(t *testing.T) {
+	table := NewTable()
+	release := make(chan struct{})
+	defer close(release)
+
+	sleep := startBlocked(table, "sleep 10", 0, release)
+	cat := startBlocked(table, "cat file", 0, release)
+
+	tests := []struct {
+		spec     string
+		expected *Job
+		wantErr  bool
+	}{
+		{"", cat, false},
+		{"%%", cat, false},
+		{"%+", cat, false},
+		{"%-", sleep, false},
+		{"%1", sleep, false},
+		{"%2", cat, false},
+		{"%sl", sleep, false},
+		{"%3", nil, true},
+		{"%vim", nil, true},
+		{"12345", nil, true},
+		{"abc", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			job, err := table.Find(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Find(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if job != tt.expected {
+				t.Errorf("Find(%q) = %v, expected %v", tt.spec, job, tt.expected)
+			}
+		})
+	}
+}
+
+// TestTable_ReportDone проверяет уведомления о завершившихся заданиях.
+// Завершившееся задание выводится один раз и удаляется из таблицы,
+// выполняющееся задание остается в таблице.
+func TestTable_ReportDone(t *testing.T) {
+	table := NewTable()
+	release := make(chan struct{})
+	defer close(release)
+
+	done := table.Start("echo hi", func(job *Job) int { return 0 })
+	done.Wait()
+	running := startBlocked(table, "sleep 10", 0, release)
+
+	if got := table.Marker(running); got != '+' {
+		t.Errorf("Marker(running) = %q, expected '+'", got)
+	}
+
+	var out bytes.Buffer
+	table.ReportDone(&out)
+
+	if !strings.Contains(out.String(), "[1]-  Done") || !strings.Contains(out.String(), "echo hi") {
+		t.Errorf("ReportDone() output = %q, expected done notification for job 1", out.String())
+	}
+
+	jobs := table.List()
+	if len(jobs) != 1 || jobs[0] != running {
+		t.Errorf("List() after ReportDone = %v, expected only running job", jobs)
+	}
+
+	out.Reset()
+	table.ReportDone(&out)
+	if out.Len() != 0 {
+		t.Errorf("second ReportDone() output = %q, expected empty", out.String())
+	}
+}
+
+// TestTable_FindWaitable проверяет, что код возврата задания, о завершении которого
+// уже сообщено, доступен wait по номеру, пока номер не занят новым заданием.
+func TestTable_FindWaitable(t *testing.T) {
+	table := NewTable()
+	done := table.Start("exit 3", func(job *Job) int { return 3 })
+	done.Wait()
+	table.ReportDone(&bytes.Buffer{})
+
+	if _, err := table.Find("%1"); err == nil {
+		t.Error("Find(%1) should not find a reported job")
+	}
+	job, err := table.FindWaitable("%1")
+	if err != nil {
+		t.Fatalf("FindWaitable(%%1) error = %v", err)
+	}
+	if job != done || job.Wait() != 3 {
+		t.Errorf("FindWaitable(%%1) = %v with status %d, expected reported job with status 3", job, job.Wait())
+	}
+	if _, err := table.FindWaitable("%2"); err == nil {
+		t.Error("FindWaitable(%2) should fail for unknown job")
+	}
+
+	// Новое задание занимает освободившийся номер
+	next := table.Start("true", func(job *Job) int { return 0 })
+	if job, err := table.FindWaitable("%1"); err != nil || job != next {
+		t.Errorf("FindWaitable(%%1) = %v, %v, expected new job", job, err)
+	}
+
+	// Задание, которого дождались, забывается
+	table.Remove(next)
+	if _, err := table.FindWaitable("%1"); err == nil {
+		t.Error("FindWaitable(%1) should fail after Remove")
+	}
+}
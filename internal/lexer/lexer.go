@@ -1,11 +1,19 @@
 package lexer
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// ErrIncomplete сообщает, что ввод не завершен и для токенизации нужны следующие строки
+// (например, тело here-document еще не дочитано до строки-разделителя).
+var ErrIncomplete = errors.New("incomplete input")
+
 // Lexer выполняет лексический анализ командной строки.
 // Разбивает входную строку на токены, обрабатывая кавычки, пробелы и специальные символы.
 type Lexer struct{}
@@ -19,23 +27,60 @@ func NewLexer() *Lexer {
 // Tokenize выполняет лексический анализ входной строки.
 // Разбирает строку на токены, учитывая кавычки, пробелы и специальные символы.
 // Возвращает массив токенов и ошибку при некорректном вводе (например, незакрытые кавычки).
+// Ошибки имеют тип *PosError с позицией ошибочного места.
 func (l *Lexer) Tokenize(input string) ([]Token, error) {
+	return l.TokenizeAt(input, Position{Line: 1, Column: 1})
+}
+
+// TokenizeAt выполняет лексический анализ, как Tokenize, но считает, что ввод
+// начинается с позиции start: так нумерация строк продолжается в следующих
+// командах скрипта или сеанса.
+func (l *Lexer) TokenizeAt(input string, start Position) ([]Token, error) {
+	tokens, _, err := l.TokenizeAfter([]Token{}, input, start)
+	return tokens, err
+}
+
+// TokenizeAfter продолжает лексический анализ после токенов prev, полученных
+// для предыдущих строк того же ввода: prev задают контекст (начало команды,
+// шаблон ветви case, незакрытое [[), а input начинается с позиции start.
+// Так при построчном вводе разбираются только новые строки, а не весь ввод заново.
+// Возвращает prev с добавленными токенами input; prev должны заканчиваться
+// на границе токенов (без незакрытых кавычек и тел here-document).
+//
+// nesting - изменение числа незакрытых составных команд в input: открывающие слова
+// и скобки в начале команды (if, for, while, until, case, {, [[, () его увеличивают,
+// а закрывающие (fi, done, esac, }, ]], )) уменьшают в любом месте. Поэтому оценка
+// не больше точного значения, и по ней можно не запускать парсер, пока она положительна.
+func (l *Lexer) TokenizeAfter(prev []Token, input string, start Position) (tokens []Token, nesting int, err error) {
 	state := &tokenizeState{
-		tokens:        []Token{},
+		tokens:        prev,
 		current:       strings.Builder{},
 		inSingleQuote: false,
 		inDoubleQuote: false,
+		runes:         []rune(input),
+		start:         start,
+		lineStarts:    []int{0},
+		conditional:   -1,
+	}
+	for i, r := range state.runes {
+		if r == '\n' {
+			state.lineStarts = append(state.lineStarts, i+1)
+		}
 	}
+	state.conditional = state.openConditional()
 
-	runes := []rune(input)
-	for i := 0; i < len(runes); i++ {
-		char := runes[i]
+	for state.pos = 0; state.pos < len(state.runes); state.pos++ {
+		char := state.runes[state.pos]
+		if !state.inSingleQuote && !state.inDoubleQuote && !l.inWord(state) {
+			state.wordStart = state.pos
+		}
 		if err := l.processChar(char, state); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
-	return l.finalizeTokens(state)
+	tokens, err = l.finalizeTokens(state)
+	return tokens, state.nesting, err
 }
 
 // tokenizeState хранит состояние процесса токенизации.
@@ -44,17 +89,124 @@ type tokenizeState struct {
 	current       strings.Builder
 	inSingleQuote bool
 	inDoubleQuote bool
+	runes         []rune     // Входная строка в виде рун
+	pos           int        // Позиция текущего символа в runes
+	heredocs      []int      // Индексы токенов << и <<-, тела которых еще не прочитаны
+	parts         []WordPart // Завершенные части текущего слова (current - его последняя часть)
+	assignment    bool       // Текущее слово - значение присваивания после токена ASSIGN
+	regexParens   int        // Глубина незакрытых скобок в регулярном выражении после =~
+	start         Position   // Позиция начала ввода
+	lineStarts    []int      // Индексы первых символов строк ввода в runes
+	wordStart     int        // Индекс первого символа текущего слова в runes
+	quoteStart    int        // Индекс открывающей кавычки в runes
+	conditional   int        // Индекс токена [[ незакрытого условного выражения или -1
+	nesting       int        // Оценка числа незакрытых составных команд (см. TokenizeAfter)
+}
+
+// position возвращает позицию символа с индексом idx в runes.
+func (state *tokenizeState) position(idx int) Position {
+	line := sort.SearchInts(state.lineStarts, idx+1) - 1
+	pos := Position{
+		Offset: state.start.Offset + idx,
+		Line:   state.start.Line + line,
+		Column: idx - state.lineStarts[line] + 1,
+	}
+	if line == 0 {
+		pos.Column += state.start.Column - 1
+	}
+	return pos
+}
+
+// addToken добавляет токен, начинающийся с символа с индексом start.
+// Отмечает начало и конец условного выражения [[ ]], чтобы не искать [[ среди
+// предыдущих токенов при разборе каждого слова.
+func (state *tokenizeState) addToken(token Token, start int) {
+	token.Pos = state.position(start)
+	state.nesting += state.nestingChange(token)
+	switch {
+	case token.Type == SEMICOLON, token.Type == BACKGROUND, token.Type == PIPE, token.Type == DSEMI:
+		state.conditional = -1
+	case token.IsReserved() && token.Value == "]]":
+		state.conditional = -1
+	case token.IsReserved() && token.Value == "[[" && state.isCommandPrefix(len(state.tokens)):
+		state.conditional = len(state.tokens)
+	}
+	state.tokens = append(state.tokens, token)
+}
+
+// nestingChange возвращает изменение числа незакрытых составных команд
+// после добавления токена (см. TokenizeAfter).
+func (state *tokenizeState) nestingChange(token Token) int {
+	last := len(state.tokens) - 1
+	switch {
+	case token.IsReserved() && token.Value == "{":
+		// Тело функции function name { ... } следует за именем, а не в начале команды
+		if state.isCommandPrefix(last+1) || last >= 1 && state.tokens[last-1].IsReserved() && state.tokens[last-1].Value == "function" {
+			return 1
+		}
+	case token.Type == LPAREN || token.IsReserved() && slices.Contains(openingWords, token.Value):
+		if state.isCommandPrefix(last + 1) {
+			return 1
+		}
+	case token.Type == RPAREN:
+		// Скобки () в определении функции name() и скобка после шаблона ветви case
+		// составную команду не закрывают
+		if last >= 0 && state.tokens[last].Type == LPAREN && !state.isCommandPrefix(last) || isCasePattern(state.tokens, last+1) {
+			return 0
+		}
+		return -1
+	case token.IsReserved() && slices.Contains(closingWords, token.Value):
+		return -1
+	}
+	return 0
+}
+
+// errorAt возвращает ошибку с позицией символа с индексом idx.
+func (state *tokenizeState) errorAt(idx int, err error) error {
+	return &PosError{Pos: state.position(idx), Err: err}
+}
+
+// peek возвращает символ, отстоящий на offset позиций от текущего.
+// Если позиция выходит за пределы строки, возвращает 0.
+func (state *tokenizeState) peek(offset int) rune {
+	idx := state.pos + offset
+	if idx < 0 || idx >= len(state.runes) {
+		return 0
+	}
+	return state.runes[idx]
 }
 
 // processChar обрабатывает один символ в процессе токенизации.
 func (l *Lexer) processChar(char rune, state *tokenizeState) error {
 	switch {
+	case char == '\\' && !state.inSingleQuote:
+		return l.handleBackslash(state)
+	case !state.inSingleQuote && isSubstitutionStart(state.runes, state.pos):
+		return l.handleSubstitution(state)
+	case char == '(' && state.peek(1) == '(' && l.isArithmeticForStart(state):
+		return l.handleArithmeticFor(state)
+	case char == '#' && l.isCommentStart(state):
+		l.handleComment(state)
 	case char == '\'' && !state.inDoubleQuote:
 		return l.handleSingleQuote(state)
 	case char == '"' && !state.inSingleQuote:
 		return l.handleDoubleQuote(state)
+	case (char == '(' || char == ')' || char == '|') && l.isRegexChar(char, state):
+		l.handleRegexChar(char, state)
 	case char == '|' && !state.inSingleQuote && !state.inDoubleQuote:
 		l.handlePipe(state)
+	case char == ';' && !state.inSingleQuote && !state.inDoubleQuote:
+		l.handleSemicolon(state)
+	case (char == '(' || char == ')') && !state.inSingleQuote && !state.inDoubleQuote:
+		l.handleParen(char, state)
+	case char == '&' && state.peek(1) == '&' && !state.inSingleQuote && !state.inDoubleQuote:
+		l.handleAnd(state)
+	case l.isRedirectStart(char, state) && !state.inSingleQuote && !state.inDoubleQuote:
+		l.handleRedirect(char, state)
+	case char == '&' && !state.inSingleQuote && !state.inDoubleQuote:
+		l.handleBackground(state)
+	case char == '\n' && !state.inSingleQuote && !state.inDoubleQuote:
+		return l.handleNewline(state)
 	case unicode.IsSpace(char) && !state.inSingleQuote && !state.inDoubleQuote:
 		l.handleSpace(state)
 	case char == '=' && !state.inSingleQuote && !state.inDoubleQuote:
@@ -65,40 +217,390 @@ func (l *Lexer) processChar(char rune, state *tokenizeState) error {
 	return nil
 }
 
+// handleBackslash обрабатывает обратный слеш вне одинарных кавычек.
+// Вне кавычек \ экранирует следующий символ: пробел, оператор, кавычка или $ после него
+// становятся частью слова. Пара \x сохраняется в слове, а снимает экранирование expander
+// после подстановок, чтобы экранированные $, ~, {, * и другие символы остались буквальными.
+// В двойных кавычках \ специален только перед $, `, ", \ и переводом строки:
+// \" заменяется кавычкой, а перед остальными символами \ остается обычным символом.
+// Пара \ и перевод строки (продолжение строки) удаляется. Обратный слеш в конце ввода
+// означает, что строка продолжается на следующей: возвращается ErrIncomplete.
+func (l *Lexer) handleBackslash(state *tokenizeState) error {
+	if state.pos+1 >= len(state.runes) {
+		if state.inDoubleQuote {
+			state.current.WriteRune('\\')
+			return nil
+		}
+		return state.errorAt(state.pos, fmt.Errorf("%w: line continuation expected", ErrIncomplete))
+	}
+
+	switch next := state.peek(1); {
+	case next == '\n':
+		state.pos++
+	case state.inDoubleQuote && next == '"':
+		state.pos++
+		state.current.WriteRune(next)
+	case state.inDoubleQuote && !strings.ContainsRune("$`\\", next):
+		state.current.WriteRune('\\')
+	default:
+		state.pos++
+		state.current.WriteRune('\\')
+		state.current.WriteRune(next)
+	}
+	return nil
+}
+
+// isCommentStart проверяет, начинается ли с текущего символа # комментарий.
+// Комментарий начинается только в начале слова вне кавычек: в a#b, 'a'#b, x=#b,
+// $# и ${#VAR} символ # обычный.
+func (l *Lexer) isCommentStart(state *tokenizeState) bool {
+	return !state.inSingleQuote && !state.inDoubleQuote && !l.inWord(state)
+}
+
+// inWord проверяет, начато ли текущее слово.
+func (l *Lexer) inWord(state *tokenizeState) bool {
+	return state.current.Len() > 0 || len(state.parts) > 0 || state.assignment
+}
+
+// handleComment пропускает комментарий до конца строки.
+// Перевод строки не пропускается: он может завершать строку с оператором <<.
+func (l *Lexer) handleComment(state *tokenizeState) {
+	for state.pos+1 < len(state.runes) && state.runes[state.pos+1] != '\n' {
+		state.pos++
+	}
+}
+
+// isArithmeticForStart проверяет, что (( начинает заголовок цикла for ((...)):
+// скобки стоят в начале слова сразу после зарезервированного слова for (for ((...)) или for((...))).
+func (l *Lexer) isArithmeticForStart(state *tokenizeState) bool {
+	if state.inSingleQuote || state.inDoubleQuote {
+		return false
+	}
+	if state.current.String() == "for" && len(state.parts) == 0 && !state.assignment {
+		return l.isCommandPrefix(state)
+	}
+	if l.inWord(state) || len(state.tokens) == 0 {
+		return false
+	}
+	last := len(state.tokens) - 1
+	return state.tokens[last].IsReserved() && state.tokens[last].Value == "for" && state.isCommandPrefix(last)
+}
+
+// handleArithmeticFor читает заголовок цикла for ((init; cond; update)) до парных )).
+// Содержимое сохраняется токеном ARITH без разбора: точки с запятой в нем не разделяют команды.
+// Если закрывающих скобок нет, возвращается ErrIncomplete.
+func (l *Lexer) handleArithmeticFor(state *tokenizeState) error {
+	l.flushCurrentWord(state)
+
+	depth := 0
+	for i := state.pos + 2; i < len(state.runes); i++ {
+		switch state.runes[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if i+1 >= len(state.runes) || state.runes[i+1] != ')' {
+				return state.errorAt(i, errors.New("syntax error: unexpected ')' in arithmetic for"))
+			}
+			state.addToken(Token{Type: ARITH, Value: string(state.runes[state.pos+2 : i])}, state.pos)
+			state.pos = i + 1
+			return nil
+		}
+	}
+	return state.errorAt(state.pos, fmt.Errorf("%w: \"))\" expected", ErrIncomplete))
+}
+
+// handleSubstitution добавляет к текущему слову подстановку $(...), ${...} или `...` целиком.
+// Пробелы, операторы и кавычки внутри подстановки не разбивают слово;
+// сама подстановка выполняется expander'ом.
+func (l *Lexer) handleSubstitution(state *tokenizeState) error {
+	rest := string(state.runes[state.pos:])
+	end, err := SubstitutionEnd(rest, 0)
+	if err != nil {
+		return state.errorAt(state.pos, err)
+	}
+
+	text := rest[:end]
+	state.current.WriteString(text)
+	state.pos += utf8.RuneCountInString(text) - 1
+	return nil
+}
+
 // handleSingleQuote обрабатывает одинарные кавычки.
+// Текст в кавычках становится частью текущего слова: foo'bar' - одно слово.
 func (l *Lexer) handleSingleQuote(state *tokenizeState) error {
 	if state.inSingleQuote {
-		// Закрытие одинарных кавычек: сохраняем содержимое как SQUOTE токен
-		state.tokens = append(state.tokens, Token{Type: SQUOTE, Value: state.current.String()})
-		state.current.Reset()
+		// Закрытие одинарных кавычек: содержимое - SQUOTE часть слова
+		l.endPart(state, SQUOTE)
 		state.inSingleQuote = false
 	} else {
-		// Открытие одинарных кавычек: сохраняем накопленное слово, если есть
-		l.flushCurrentWord(state)
+		// Открытие одинарных кавычек: завершаем часть без кавычек, если есть
+		l.endUnquotedPart(state)
 		state.inSingleQuote = true
+		state.quoteStart = state.pos
 	}
 	return nil
 }
 
 // handleDoubleQuote обрабатывает двойные кавычки.
+// Текст в кавычках становится частью текущего слова: foo"bar" - одно слово.
 func (l *Lexer) handleDoubleQuote(state *tokenizeState) error {
 	if state.inDoubleQuote {
-		// Закрытие двойных кавычек: сохраняем содержимое как DQUOTE токен
-		state.tokens = append(state.tokens, Token{Type: DQUOTE, Value: state.current.String()})
-		state.current.Reset()
+		// Закрытие двойных кавычек: содержимое - DQUOTE часть слова
+		l.endPart(state, DQUOTE)
 		state.inDoubleQuote = false
 	} else {
-		// Открытие двойных кавычек: сохраняем накопленное слово, если есть
-		l.flushCurrentWord(state)
+		// Открытие двойных кавычек: завершаем часть без кавычек, если есть
+		l.endUnquotedPart(state)
 		state.inDoubleQuote = true
+		state.quoteStart = state.pos
 	}
 	return nil
 }
 
-// handlePipe обрабатывает оператор пайплайна.
+// endPart завершает часть текущего слова с накопленным текстом, даже пустым (пустые кавычки).
+func (l *Lexer) endPart(state *tokenizeState, partType TokenType) {
+	state.parts = append(state.parts, WordPart{Type: partType, Value: state.current.String()})
+	state.current.Reset()
+}
+
+// endUnquotedPart завершает часть слова без кавычек, если в ней есть текст.
+func (l *Lexer) endUnquotedPart(state *tokenizeState) {
+	if state.current.Len() > 0 {
+		l.endPart(state, WORD)
+	}
+}
+
+// isRegexChar проверяет, что скобка или | вне кавычек входит в регулярное выражение
+// после оператора =~ в [[ ]], как в [[ $x =~ ^(a|b)$ ]], а не является оператором.
+// Закрывающая скобка входит в выражение, только если закрывает скобку из него:
+// в [[ (x =~ a) ]] она завершает группу выражения [[ ]].
+func (l *Lexer) isRegexChar(char rune, state *tokenizeState) bool {
+	if state.inSingleQuote || state.inDoubleQuote || (char == ')' && state.regexParens == 0) {
+		return false
+	}
+	last := len(state.tokens) - 1
+	return last >= 0 && state.tokens[last].Type == WORD && len(state.tokens[last].Parts) == 0 &&
+		state.tokens[last].Value == "=~" && state.inConditional(last)
+}
+
+// handleRegexChar добавляет скобку или | к регулярному выражению.
+func (l *Lexer) handleRegexChar(char rune, state *tokenizeState) {
+	switch char {
+	case '(':
+		state.regexParens++
+	case ')':
+		state.regexParens--
+	}
+	state.current.WriteRune(char)
+}
+
+// handlePipe обрабатывает оператор пайплайна и оператор ||.
 func (l *Lexer) handlePipe(state *tokenizeState) {
 	l.flushCurrentWord(state)
-	state.tokens = append(state.tokens, Token{Type: PIPE, Value: "|"})
+	if state.peek(1) == '|' {
+		state.addToken(Token{Type: OR, Value: "||"}, state.pos)
+		state.pos++
+		return
+	}
+	state.addToken(Token{Type: PIPE, Value: "|"}, state.pos)
+}
+
+// handleSemicolon обрабатывает разделитель команд ; и операторы завершения
+// ветви case: ;;, ;& и ;;&.
+func (l *Lexer) handleSemicolon(state *tokenizeState) {
+	l.flushCurrentWord(state)
+
+	var value string
+	switch {
+	case state.peek(1) == ';' && state.peek(2) == '&':
+		value = ";;&"
+	case state.peek(1) == ';':
+		value = ";;"
+	case state.peek(1) == '&':
+		value = ";&"
+	default:
+		state.addToken(Token{Type: SEMICOLON, Value: ";"}, state.pos)
+		return
+	}
+	state.addToken(Token{Type: DSEMI, Value: value}, state.pos)
+	state.pos += len(value) - 1
+}
+
+// handleParen обрабатывает скобки ( и ) вне кавычек: они, как и операторы, завершают слово.
+func (l *Lexer) handleParen(char rune, state *tokenizeState) {
+	l.flushCurrentWord(state)
+	if char == '(' {
+		state.addToken(Token{Type: LPAREN, Value: "("}, state.pos)
+		return
+	}
+	state.addToken(Token{Type: RPAREN, Value: ")"}, state.pos)
+}
+
+// handleAnd обрабатывает оператор &&.
+func (l *Lexer) handleAnd(state *tokenizeState) {
+	l.flushCurrentWord(state)
+	state.addToken(Token{Type: AND, Value: "&&"}, state.pos)
+	state.pos++
+}
+
+// handleBackground обрабатывает оператор фонового выполнения &.
+func (l *Lexer) handleBackground(state *tokenizeState) {
+	l.flushCurrentWord(state)
+	state.addToken(Token{Type: BACKGROUND, Value: "&"}, state.pos)
+}
+
+// isRedirectStart проверяет, начинается ли с текущего символа оператор перенаправления.
+// Символ & считается началом оператора только в формах &> и &>>.
+func (l *Lexer) isRedirectStart(char rune, state *tokenizeState) bool {
+	switch char {
+	case '>', '<':
+		return true
+	case '&':
+		return state.peek(1) == '>'
+	default:
+		return false
+	}
+}
+
+// handleRedirect обрабатывает операторы перенаправления ввода-вывода.
+// Поддерживаются формы >, >>, >|, <, >&, <&, &>, &>>, а также <<, <<- и <<<.
+// Если накопленное слово состоит только из цифр (например, 2 в 2>file),
+// оно считается номером файлового дескриптора и входит в оператор.
+func (l *Lexer) handleRedirect(char rune, state *tokenizeState) {
+	var op strings.Builder
+	start := state.pos
+
+	if word := state.current.String(); isDigits(word) && len(state.parts) == 0 && !state.assignment {
+		op.WriteString(word)
+		state.current.Reset()
+		start = state.wordStart
+	} else {
+		l.flushCurrentWord(state)
+	}
+
+	op.WriteRune(char)
+	if char == '&' {
+		// &> или &>>
+		state.pos++
+		op.WriteRune('>')
+		if state.peek(1) == '>' {
+			state.pos++
+			op.WriteRune('>')
+		}
+	} else {
+		switch next := state.peek(1); {
+		case char == '>' && (next == '>' || next == '|' || next == '&'):
+			state.pos++
+			op.WriteRune(next)
+		case char == '<' && next == '&':
+			state.pos++
+			op.WriteRune(next)
+		case char == '<' && next == '<':
+			// <<, <<- или <<<
+			state.pos++
+			op.WriteRune(next)
+			if after := state.peek(1); after == '<' || after == '-' {
+				state.pos++
+				op.WriteRune(after)
+			}
+		}
+	}
+
+	value := op.String()
+	if operator := strings.TrimLeft(value, "0123456789"); operator == "<<" || operator == "<<-" {
+		// Тело here-document читается после конца строки с оператором
+		state.heredocs = append(state.heredocs, len(state.tokens))
+	}
+	state.addToken(Token{Type: REDIRECT, Value: value}, start)
+}
+
+// handleNewline обрабатывает перевод строки вне кавычек: он, как и ;, завершает команду
+// и сохраняется токеном NEWLINE. Если в строке были операторы << и <<-, после нее следуют
+// тела here-document: для каждого оператора << и <<- строки читаются до строки-разделителя,
+// а токен разделителя заменяется телом документа: SQUOTE, если разделитель был
+// в кавычках (тело не раскрывается), и DQUOTE иначе (в теле выполняются подстановки).
+// Для <<- из начала строк тела и строки-разделителя удаляются символы табуляции.
+func (l *Lexer) handleNewline(state *tokenizeState) error {
+	l.flushCurrentWord(state)
+	newline := state.pos
+
+	for _, idx := range state.heredocs {
+		operator := state.tokens[idx]
+		if idx+1 >= len(state.tokens) {
+			return &PosError{Pos: operator.Pos, Err: fmt.Errorf("syntax error: missing here-document delimiter after %s", operator.Value)}
+		}
+
+		delimiter := state.tokens[idx+1]
+		word, quoted := delimiter.Value, delimiter.Type != WORD || len(delimiter.Parts) > 0
+		if strings.Contains(word, "\\") {
+			// Экранирование в разделителе (\EOF) также отключает подстановки
+			word, quoted = strings.ReplaceAll(word, "\\", ""), true
+		}
+		stripTabs := strings.HasSuffix(operator.Value, "-")
+
+		body, err := l.readHeredocBody(state, word, stripTabs)
+		if err != nil {
+			return &PosError{Pos: operator.Pos, Err: err}
+		}
+
+		bodyType := DQUOTE
+		if quoted {
+			bodyType = SQUOTE
+		}
+		state.tokens[idx+1] = Token{Type: bodyType, Value: body, Pos: delimiter.Pos}
+	}
+
+	state.heredocs = nil
+	state.addToken(Token{Type: NEWLINE, Value: "\n"}, newline)
+	return nil
+}
+
+// readHeredocBody читает строки после текущей позиции до строки, равной delimiter.
+// После чтения позиция указывает на последний символ строки-разделителя.
+// Если разделитель не найден, возвращает ErrIncomplete.
+func (l *Lexer) readHeredocBody(state *tokenizeState, delimiter string, stripTabs bool) (string, error) {
+	var body strings.Builder
+	start := state.pos + 1
+
+	for start < len(state.runes) {
+		end := start
+		for end < len(state.runes) && state.runes[end] != '\n' {
+			end++
+		}
+
+		line := string(state.runes[start:end])
+		if stripTabs {
+			line = strings.TrimLeft(line, "\t")
+		}
+
+		if line == delimiter {
+			state.pos = end
+			if end == len(state.runes) {
+				state.pos = end - 1
+			}
+			return body.String(), nil
+		}
+
+		body.WriteString(line)
+		body.WriteByte('\n')
+		start = end + 1
+	}
+
+	return "", fmt.Errorf("%w: here-document delimited by %q is not terminated", ErrIncomplete, delimiter)
+}
+
+// isDigits проверяет, что строка состоит только из десятичных цифр.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
 }
 
 // handleSpace обрабатывает пробельные символы.
@@ -107,49 +609,163 @@ func (l *Lexer) handleSpace(state *tokenizeState) {
 }
 
 // handleAssignment обрабатывает оператор присваивания.
+// Слово name=value считается присваиванием, только если name - валидное имя переменной
+// без кавычек и слово стоит в начале команды (перед ним только присваивания
+// и перенаправления). В остальных случаях, как в echo a=b, '=' - часть слова.
+// Значение присваивания всегда образует одно слово, в том числе пустое (x=).
 func (l *Lexer) handleAssignment(char rune, state *tokenizeState) {
-	if state.current.Len() > 0 {
-		word := state.current.String()
-		// Если накопленная строка - валидное имя переменной, создаем ASSIGN токен
-		if l.isValidVariableName(word) {
-			state.tokens = append(state.tokens, Token{Type: ASSIGN, Value: word})
-			state.current.Reset()
-		} else {
-			// Иначе добавляем '=' как часть слова
-			state.current.WriteRune(char)
-		}
-	} else {
-		// Если нет накопленного слова, '=' становится частью нового слова
+	word := state.current.String()
+	if len(state.parts) > 0 || state.assignment || !l.isValidVariableName(word) || !l.isCommandPrefix(state) {
 		state.current.WriteRune(char)
+		return
 	}
+
+	state.addToken(Token{Type: ASSIGN, Value: word}, state.wordStart)
+	state.current.Reset()
+	state.assignment = true
+	state.wordStart = state.pos + 1
 }
 
-// flushCurrentWord сохраняет накопленное слово как WORD токен, если оно не пустое.
-func (l *Lexer) flushCurrentWord(state *tokenizeState) {
-	if state.current.Len() > 0 {
-		state.tokens = append(state.tokens, Token{Type: WORD, Value: state.current.String()})
-		state.current.Reset()
+// isCommandPrefix проверяет, что новое слово находится в начале команды:
+// перед ним в команде только присваивания (с их значениями) и перенаправления (с их целями).
+func (l *Lexer) isCommandPrefix(state *tokenizeState) bool {
+	return state.isCommandPrefix(len(state.tokens))
+}
+
+// isCommandPrefix проверяет, что слово после tokens[:end] находится в начале команды.
+// Команда начинается после операторов, скобок (в том числе после шаблона ветви case),
+// перевода строки и зарезервированных слов вроде then, которые сами стоят в начале команды.
+// Внутри [[ ]] команд нет: после && и скобок там следуют операнды выражения.
+func (state *tokenizeState) isCommandPrefix(end int) bool {
+	if state.inConditional(end) {
+		return false
 	}
+	tokens := state.tokens
+	for i := end - 1; i >= 0; i-- {
+		switch tokens[i].Type {
+		case SEMICOLON, AND, OR, BACKGROUND, RPAREN:
+			return true
+		case PIPE, LPAREN, NEWLINE:
+			// В шаблоне ветви case (a|b=c) после | и ( следует шаблон, а не команда
+			return !isCasePattern(tokens, i+1)
+		case DSEMI:
+			// После ;; следует шаблон ветви case, а не команда
+			return false
+		case ASSIGN, REDIRECT:
+			continue
+		}
+		// Значение присваивания или цель перенаправления входят в префикс команды
+		if i > 0 && (tokens[i-1].Type == ASSIGN || tokens[i-1].Type == REDIRECT) {
+			i--
+			continue
+		}
+		if startsCommand, ok := reservedWords[tokens[i].Value]; ok && tokens[i].IsReserved() && state.isCommandPrefix(i) {
+			return startsCommand
+		}
+		return false
+	}
+	return true
 }
 
-// finalizeTokens завершает токенизацию и проверяет корректность.
-func (l *Lexer) finalizeTokens(state *tokenizeState) ([]Token, error) {
-	// Обработка оставшегося содержимого после завершения цикла
-	if state.current.Len() > 0 {
-		// Если осталось содержимое, но мы все еще в кавычках - ошибка
-		if state.inSingleQuote || state.inDoubleQuote {
-			return nil, fmt.Errorf("unclosed quote")
+// openConditional находит среди токенов предыдущих строк [[ незакрытого условного
+// выражения (см. TokenizeAfter) и возвращает его индекс или -1.
+// Перевод строки внутри [[ ]] - синтаксическая ошибка, поэтому просматривается
+// только последняя строка.
+func (state *tokenizeState) openConditional() int {
+	for i := len(state.tokens) - 1; i >= 0; i-- {
+		token := state.tokens[i]
+		switch {
+		case token.Type == SEMICOLON, token.Type == BACKGROUND, token.Type == PIPE, token.Type == DSEMI, token.Type == NEWLINE:
+			return -1
+		case token.IsReserved() && token.Value == "]]":
+			return -1
+		case token.IsReserved() && token.Value == "[[" && state.isCommandPrefix(i):
+			return i
 		}
-		// Иначе сохраняем как обычное слово
-		state.tokens = append(state.tokens, Token{Type: WORD, Value: state.current.String()})
 	}
+	return -1
+}
 
-	// Проверка незакрытых кавычек
+// inConditional проверяет, что слово после tokens[:end] находится внутри [[ ]]:
+// перед ним в той же команде есть [[ в начале команды, еще не закрытое словом ]].
+func (state *tokenizeState) inConditional(end int) bool {
+	return state.conditional >= 0 && state.conditional < end
+}
+
+// isCasePattern проверяет, что tokens[:end] заканчиваются незавершенным шаблоном ветви case:
+// после case слово in или ;; (и переводов строк) следуют только слова шаблона, | и (.
+func isCasePattern(tokens []Token, end int) bool {
+	for i := end - 1; i >= 0; i-- {
+		switch tokens[i].Type {
+		case DSEMI:
+			return true
+		case WORD, SQUOTE, DQUOTE, PIPE, LPAREN, NEWLINE:
+			// После esac конструкция case закончена, и дальше идут команды
+			if tokens[i].IsReserved() && tokens[i].Value == "esac" {
+				return false
+			}
+			if i >= 2 && tokens[i].IsReserved() && tokens[i].Value == "in" &&
+				tokens[i-2].IsReserved() && tokens[i-2].Value == "case" {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// flushCurrentWord сохраняет накопленное слово как токен, если оно не пустое.
+// Слово из одной части сохраняется токеном её типа (WORD, SQUOTE или DQUOTE),
+// составное слово - токеном WORD с частями. Пустое значение присваивания (x=)
+// сохраняется пустым токеном WORD.
+func (l *Lexer) flushCurrentWord(state *tokenizeState) {
+	l.endUnquotedPart(state)
+
+	switch len(state.parts) {
+	case 0:
+		if state.assignment {
+			state.addToken(Token{Type: WORD, Value: ""}, state.wordStart)
+		}
+	case 1:
+		state.addToken(Token{Type: state.parts[0].Type, Value: state.parts[0].Value}, state.wordStart)
+	default:
+		var value strings.Builder
+		for _, part := range state.parts {
+			value.WriteString(part.Value)
+		}
+		state.addToken(Token{Type: WORD, Value: value.String(), Parts: state.parts}, state.wordStart)
+	}
+
+	state.parts = nil
+	state.assignment = false
+	state.regexParens = 0
+}
+
+// finalizeTokens завершает токенизацию и проверяет корректность.
+// Незакрытые кавычки, как и незавершенный here-document, означают, что ввод
+// продолжается на следующей строке: возвращается ошибка с ErrIncomplete.
+func (l *Lexer) finalizeTokens(state *tokenizeState) ([]Token, error) {
+	// Проверка незакрытых кавычек: строка в кавычках может занимать несколько строк
 	if state.inSingleQuote {
-		return nil, fmt.Errorf("unclosed single quote")
+		return nil, state.errorAt(state.quoteStart, fmt.Errorf("%w: unclosed single quote", ErrIncomplete))
 	}
 	if state.inDoubleQuote {
-		return nil, fmt.Errorf("unclosed double quote")
+		return nil, state.errorAt(state.quoteStart, fmt.Errorf("%w: unclosed double quote", ErrIncomplete))
+	}
+
+	// Сохраняем последнее слово
+	l.flushCurrentWord(state)
+
+	// Завершающие переводы строк не разделяют команды и отбрасываются
+	for len(state.tokens) > 0 && state.tokens[len(state.tokens)-1].Type == NEWLINE {
+		state.tokens = state.tokens[:len(state.tokens)-1]
+	}
+
+	// Оператор << в последней строке: тела here-document еще нет
+	if len(state.heredocs) > 0 {
+		operator := state.tokens[state.heredocs[0]]
+		return nil, &PosError{Pos: operator.Pos, Err: fmt.Errorf("%w: here-document body expected", ErrIncomplete)}
 	}
 
 	return state.tokens, nil
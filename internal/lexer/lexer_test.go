@@ -1,6 +1,8 @@
 package lexer
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -63,6 +65,206 @@ func TestLexer_Tokenize(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "output redirection",
+			input: "echo hello > out.txt",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "hello"},
+				{Type: REDIRECT, Value: ">"},
+				{Type: WORD, Value: "out.txt"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "append and input redirection without spaces",
+			input: "cat <in.txt >>out.txt",
+			expected: []Token{
+				{Type: WORD, Value: "cat"},
+				{Type: REDIRECT, Value: "<"},
+				{Type: WORD, Value: "in.txt"},
+				{Type: REDIRECT, Value: ">>"},
+				{Type: WORD, Value: "out.txt"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "stderr redirection and descriptor duplication",
+			input: "cmd 2>err.txt 2>&1",
+			expected: []Token{
+				{Type: WORD, Value: "cmd"},
+				{Type: REDIRECT, Value: "2>"},
+				{Type: WORD, Value: "err.txt"},
+				{Type: REDIRECT, Value: "2>&"},
+				{Type: WORD, Value: "1"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "redirect stdout and stderr",
+			input: "cmd &>all.txt &>> more.txt",
+			expected: []Token{
+				{Type: WORD, Value: "cmd"},
+				{Type: REDIRECT, Value: "&>"},
+				{Type: WORD, Value: "all.txt"},
+				{Type: REDIRECT, Value: "&>>"},
+				{Type: WORD, Value: "more.txt"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "redirection characters inside quotes",
+			input: `echo "a > b" 'c < d'`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: DQUOTE, Value: "a > b"},
+				{Type: SQUOTE, Value: "c < d"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "digits not followed by redirection stay a word",
+			input: "echo 2 > out",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "2"},
+				{Type: REDIRECT, Value: ">"},
+				{Type: WORD, Value: "out"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "command list operators",
+			input: "make && ./run || echo failed; echo done",
+			expected: []Token{
+				{Type: WORD, Value: "make"},
+				{Type: AND, Value: "&&"},
+				{Type: WORD, Value: "./run"},
+				{Type: OR, Value: "||"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "failed"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "done"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "list operators without spaces",
+			input: "a;b&&c||d|e",
+			expected: []Token{
+				{Type: WORD, Value: "a"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "b"},
+				{Type: AND, Value: "&&"},
+				{Type: WORD, Value: "c"},
+				{Type: OR, Value: "||"},
+				{Type: WORD, Value: "d"},
+				{Type: PIPE, Value: "|"},
+				{Type: WORD, Value: "e"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "background operator",
+			input: "sleep 10 & echo a&&b 2>&1&",
+			expected: []Token{
+				{Type: WORD, Value: "sleep"},
+				{Type: WORD, Value: "10"},
+				{Type: BACKGROUND, Value: "&"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a"},
+				{Type: AND, Value: "&&"},
+				{Type: WORD, Value: "b"},
+				{Type: REDIRECT, Value: "2>&"},
+				{Type: WORD, Value: "1"},
+				{Type: BACKGROUND, Value: "&"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "here-document",
+			input: "cat <<EOF | wc\nhello $USER\n  indented\nEOF",
+			expected: []Token{
+				{Type: WORD, Value: "cat"},
+				{Type: REDIRECT, Value: "<<"},
+				{Type: DQUOTE, Value: "hello $USER\n  indented\n"},
+				{Type: PIPE, Value: "|"},
+				{Type: WORD, Value: "wc"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "here-document with quoted delimiter and tab stripping",
+			input: "cat <<-'END'\n\t$HOME\n\tEND\n",
+			expected: []Token{
+				{Type: WORD, Value: "cat"},
+				{Type: REDIRECT, Value: "<<-"},
+				{Type: SQUOTE, Value: "$HOME\n"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "two here-documents on one line",
+			input: "cmd <<A 3<<B\na\nA\nb\nB",
+			expected: []Token{
+				{Type: WORD, Value: "cmd"},
+				{Type: REDIRECT, Value: "<<"},
+				{Type: DQUOTE, Value: "a\n"},
+				{Type: REDIRECT, Value: "3<<"},
+				{Type: DQUOTE, Value: "b\n"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "here-string",
+			input: `wc <<<"a b"`,
+			expected: []Token{
+				{Type: WORD, Value: "wc"},
+				{Type: REDIRECT, Value: "<<<"},
+				{Type: DQUOTE, Value: "a b"},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "unterminated here-document",
+			input:    "cat <<EOF\nhello",
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name:  "command substitution",
+			input: `echo $(ls -l | wc) "$(echo "a b")"x ` + "`pwd`",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "$(ls -l | wc)"},
+				{Type: WORD, Value: `$(echo "a b")x`, Parts: []WordPart{
+					{Type: DQUOTE, Value: `$(echo "a b")`},
+					{Type: WORD, Value: "x"},
+				}},
+				{Type: WORD, Value: "`pwd`"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "substitution in single quotes",
+			input: "echo '$(pwd)'",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: SQUOTE, Value: "$(pwd)"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "list operators inside quotes",
+			input: `echo "a; b && c" 'd || e'`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: DQUOTE, Value: "a; b && c"},
+				{Type: SQUOTE, Value: "d || e"},
+			},
+			wantErr: false,
+		},
 		{
 			name:     "unclosed single quote",
 			input:    "echo 'hello",
@@ -75,6 +277,370 @@ func TestLexer_Tokenize(t *testing.T) {
 			expected: nil,
 			wantErr:  true,
 		},
+		{
+			name:  "multiline quoted string",
+			input: "echo 'a\nb' \"c\n\" d",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: SQUOTE, Value: "a\nb"},
+				{Type: DQUOTE, Value: "c\n"},
+				{Type: WORD, Value: "d"},
+			},
+		},
+		{
+			name:  "escaped space and operators",
+			input: `echo a\ b \| \; \>out`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: `a\ b`},
+				{Type: WORD, Value: `\|`},
+				{Type: WORD, Value: `\;`},
+				{Type: WORD, Value: `\>out`},
+			},
+		},
+		{
+			name:  "escaped quotes and assignment",
+			input: `echo \'x\' \"y\" A\=b`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: `\'x\'`},
+				{Type: WORD, Value: `\"y\"`},
+				{Type: WORD, Value: `A\=b`},
+			},
+		},
+		{
+			name:  "escapes in double quotes",
+			input: `echo "say \"hi\" \$HOME \\ \n"`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: DQUOTE, Value: `say "hi" \$HOME \\ \n`},
+			},
+		},
+		{
+			name:  "backslash in single quotes",
+			input: `echo 'a\'`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: SQUOTE, Value: `a\`},
+			},
+		},
+		{
+			name:  "line continuation",
+			input: "echo one \\\ntwo th\\\nree \"a\\\nb\"",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "one"},
+				{Type: WORD, Value: "two"},
+				{Type: WORD, Value: "three"},
+				{Type: DQUOTE, Value: "ab"},
+			},
+		},
+		{
+			name:  "composite word",
+			input: `echo foo"bar"'baz' ""x`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "foobarbaz", Parts: []WordPart{
+					{Type: WORD, Value: "foo"},
+					{Type: DQUOTE, Value: "bar"},
+					{Type: SQUOTE, Value: "baz"},
+				}},
+				{Type: WORD, Value: "x", Parts: []WordPart{{Type: DQUOTE, Value: ""}, {Type: WORD, Value: "x"}}},
+			},
+		},
+		{
+			name:  "assignment only in command prefix",
+			input: `A=1 >out B="x y"z echo a=b C=2`,
+			expected: []Token{
+				{Type: ASSIGN, Value: "A"},
+				{Type: WORD, Value: "1"},
+				{Type: REDIRECT, Value: ">"},
+				{Type: WORD, Value: "out"},
+				{Type: ASSIGN, Value: "B"},
+				{Type: WORD, Value: "x yz", Parts: []WordPart{{Type: DQUOTE, Value: "x y"}, {Type: WORD, Value: "z"}}},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a=b"},
+				{Type: WORD, Value: "C=2"},
+			},
+		},
+		{
+			name:  "assignment after separator",
+			input: `echo a; B=2 && C=3 | D=4`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: ASSIGN, Value: "B"},
+				{Type: WORD, Value: "2"},
+				{Type: AND, Value: "&&"},
+				{Type: ASSIGN, Value: "C"},
+				{Type: WORD, Value: "3"},
+				{Type: PIPE, Value: "|"},
+				{Type: ASSIGN, Value: "D"},
+				{Type: WORD, Value: "4"},
+			},
+		},
+		{
+			name:  "empty and nested assignment values",
+			input: `A= B=x=y C=2>out "D"=1`,
+			expected: []Token{
+				{Type: ASSIGN, Value: "A"},
+				{Type: WORD, Value: ""},
+				{Type: ASSIGN, Value: "B"},
+				{Type: WORD, Value: "x=y"},
+				{Type: ASSIGN, Value: "C"},
+				{Type: WORD, Value: "2"},
+				{Type: REDIRECT, Value: ">"},
+				{Type: WORD, Value: "out"},
+				{Type: WORD, Value: "D=1", Parts: []WordPart{{Type: DQUOTE, Value: "D"}, {Type: WORD, Value: "=1"}}},
+			},
+		},
+		{
+			name:  "trailing comment",
+			input: "echo a # comment | wc",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a"},
+			},
+		},
+		{
+			name:     "comment only",
+			input:    "  # comment",
+			expected: []Token{},
+		},
+		{
+			name:  "hash inside words and quotes",
+			input: `echo a#b "#c" '#d' \#e $# ${#X}`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a#b"},
+				{Type: DQUOTE, Value: "#c"},
+				{Type: SQUOTE, Value: "#d"},
+				{Type: WORD, Value: `\#e`},
+				{Type: WORD, Value: "$#"},
+				{Type: WORD, Value: "${#X}"},
+			},
+		},
+		{
+			name:  "hash after closing quote",
+			input: `echo 'a'#b`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a#b", Parts: []WordPart{{Type: SQUOTE, Value: "a"}, {Type: WORD, Value: "#b"}}},
+			},
+		},
+		{
+			name:  "comment after operator and before newline",
+			input: "echo a;# comment\necho b",
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: NEWLINE, Value: "\n"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "b"},
+			},
+		},
+		{
+			name:  "comment on here-document line",
+			input: "cat <<EOF # comment\n# body\nEOF",
+			expected: []Token{
+				{Type: WORD, Value: "cat"},
+				{Type: REDIRECT, Value: "<<"},
+				{Type: DQUOTE, Value: "# body\n"},
+			},
+		},
+		{
+			name:  "newline separates commands",
+			input: "\necho a\n\necho b\n\n",
+			expected: []Token{
+				{Type: NEWLINE, Value: "\n"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a"},
+				{Type: NEWLINE, Value: "\n"},
+				{Type: NEWLINE, Value: "\n"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "b"},
+			},
+		},
+		{
+			name:  "command after here-document",
+			input: "cat <<EOF\nbody\nEOF\necho a",
+			expected: []Token{
+				{Type: WORD, Value: "cat"},
+				{Type: REDIRECT, Value: "<<"},
+				{Type: DQUOTE, Value: "body\n"},
+				{Type: NEWLINE, Value: "\n"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a"},
+			},
+		},
+		{
+			name:  "assignment after reserved word",
+			input: "if x=1 true; then y=2; fi; echo then z=3",
+			expected: []Token{
+				{Type: WORD, Value: "if"},
+				{Type: ASSIGN, Value: "x"},
+				{Type: WORD, Value: "1"},
+				{Type: WORD, Value: "true"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "then"},
+				{Type: ASSIGN, Value: "y"},
+				{Type: WORD, Value: "2"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "fi"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "then"},
+				{Type: WORD, Value: "z=3"},
+			},
+		},
+		{
+			name:  "function definition",
+			input: "f() { x=1; echo {; }",
+			expected: []Token{
+				{Type: WORD, Value: "f"},
+				{Type: LPAREN, Value: "("},
+				{Type: RPAREN, Value: ")"},
+				{Type: WORD, Value: "{"},
+				{Type: ASSIGN, Value: "x"},
+				{Type: WORD, Value: "1"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "{"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "}"},
+			},
+		},
+		{
+			name:  "conditional expression",
+			input: "[[ a && b=c ]] && x=1",
+			expected: []Token{
+				{Type: WORD, Value: "[["},
+				{Type: WORD, Value: "a"},
+				{Type: AND, Value: "&&"},
+				{Type: WORD, Value: "b=c"},
+				{Type: WORD, Value: "]]"},
+				{Type: AND, Value: "&&"},
+				{Type: ASSIGN, Value: "x"},
+				{Type: WORD, Value: "1"},
+			},
+		},
+		{
+			name:  "regex after =~",
+			input: "[[ (x =~ ^(a|b)$) ]] | (echo a|b)",
+			expected: []Token{
+				{Type: WORD, Value: "[["},
+				{Type: LPAREN, Value: "("},
+				{Type: WORD, Value: "x"},
+				{Type: WORD, Value: "=~"},
+				{Type: WORD, Value: "^(a|b)$"},
+				{Type: RPAREN, Value: ")"},
+				{Type: WORD, Value: "]]"},
+				{Type: PIPE, Value: "|"},
+				{Type: LPAREN, Value: "("},
+				{Type: WORD, Value: "echo"},
+				{Type: WORD, Value: "a"},
+				{Type: PIPE, Value: "|"},
+				{Type: WORD, Value: "b"},
+				{Type: RPAREN, Value: ")"},
+			},
+		},
+		{
+			name:  "arithmetic for",
+			input: "for ((i = 0; i < (n); i++)); do x=$i; done; echo ((a))",
+			expected: []Token{
+				{Type: WORD, Value: "for"},
+				{Type: ARITH, Value: "i = 0; i < (n); i++"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "do"},
+				{Type: ASSIGN, Value: "x"},
+				{Type: WORD, Value: "$i"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "done"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "echo"},
+				{Type: LPAREN, Value: "("},
+				{Type: LPAREN, Value: "("},
+				{Type: WORD, Value: "a"},
+				{Type: RPAREN, Value: ")"},
+				{Type: RPAREN, Value: ")"},
+			},
+		},
+		{
+			name:  "arithmetic for without space",
+			input: "for((;;))",
+			expected: []Token{
+				{Type: WORD, Value: "for"},
+				{Type: ARITH, Value: ";;"},
+			},
+		},
+		{
+			name:  "case terminators and patterns",
+			input: "case $x in (a|b=c) y=1;; d) ;& e);;& esac",
+			expected: []Token{
+				{Type: WORD, Value: "case"},
+				{Type: WORD, Value: "$x"},
+				{Type: WORD, Value: "in"},
+				{Type: LPAREN, Value: "("},
+				{Type: WORD, Value: "a"},
+				{Type: PIPE, Value: "|"},
+				{Type: WORD, Value: "b=c"},
+				{Type: RPAREN, Value: ")"},
+				{Type: ASSIGN, Value: "y"},
+				{Type: WORD, Value: "1"},
+				{Type: DSEMI, Value: ";;"},
+				{Type: WORD, Value: "d"},
+				{Type: RPAREN, Value: ")"},
+				{Type: DSEMI, Value: ";&"},
+				{Type: WORD, Value: "e"},
+				{Type: RPAREN, Value: ")"},
+				{Type: DSEMI, Value: ";;&"},
+				{Type: WORD, Value: "esac"},
+			},
+		},
+		{
+			name:  "assignment after esac",
+			input: "case $x in a) ;; esac\ny=1",
+			expected: []Token{
+				{Type: WORD, Value: "case"},
+				{Type: WORD, Value: "$x"},
+				{Type: WORD, Value: "in"},
+				{Type: WORD, Value: "a"},
+				{Type: RPAREN, Value: ")"},
+				{Type: DSEMI, Value: ";;"},
+				{Type: WORD, Value: "esac"},
+				{Type: NEWLINE, Value: "\n"},
+				{Type: ASSIGN, Value: "y"},
+				{Type: WORD, Value: "1"},
+			},
+		},
+		{
+			name:  "quoted and escaped parentheses",
+			input: `echo "(a)" \(b\)`,
+			expected: []Token{
+				{Type: WORD, Value: "echo"},
+				{Type: DQUOTE, Value: "(a)"},
+				{Type: WORD, Value: `\(b\)`},
+			},
+		},
+		{
+			name:  "for loop words",
+			input: "for x in a=b c; do y=1; done",
+			expected: []Token{
+				{Type: WORD, Value: "for"},
+				{Type: WORD, Value: "x"},
+				{Type: WORD, Value: "in"},
+				{Type: WORD, Value: "a=b"},
+				{Type: WORD, Value: "c"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "do"},
+				{Type: ASSIGN, Value: "y"},
+				{Type: WORD, Value: "1"},
+				{Type: SEMICOLON, Value: ";"},
+				{Type: WORD, Value: "done"},
+			},
+		},
 	}
 
 	lexer := NewLexer()
@@ -101,12 +667,180 @@ func TestLexer_Tokenize(t *testing.T) {
 					if token.Value != tt.expected[i].Value {
 						t.Errorf("Token %d: value = %v, expected %v", i, token.Value, tt.expected[i].Value)
 					}
+					if !reflect.DeepEqual(token.Parts, tt.expected[i].Parts) {
+						t.Errorf("Token %d: parts = %v, expected %v", i, token.Parts, tt.expected[i].Parts)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestLexer_TokenizeIncomplete проверяет, что незавершенный here-document,
+// обратный слеш в конце строки и незакрытый заголовок for ((...)) сообщаются ошибкой ErrIncomplete,
+// по которой REPL продолжает чтение строк.
+func TestLexer_TokenizeIncomplete(t *testing.T) {
+	lexer := NewLexer()
+
+	for _, input := range []string{"cat <<EOF", "cat <<EOF\nbody", "cat <<-EOF\nbody\n  EOF", "echo a \\", "echo a\\\nb\\", "for ((i = 0;",
+		"echo 'a", "echo \"a\nb", "echo $(echo a"} {
+		if _, err := lexer.Tokenize(input); !errors.Is(err, ErrIncomplete) {
+			t.Errorf("Lexer.Tokenize(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+
+	if _, err := lexer.Tokenize("cat <<\nbody"); err == nil || errors.Is(err, ErrIncomplete) {
+		t.Errorf("expected syntax error for missing delimiter, got %v", err)
+	}
+}
+
+// TestLexer_TokenPositions проверяет позиции токенов: строка и столбец первого символа
+// слова, оператора, присваивания и его значения, а также продолжение нумерации в TokenizeAt.
+func TestLexer_TokenPositions(t *testing.T) {
+	lexer := NewLexer()
+
+	tokens, err := lexer.Tokenize("x=1 echo 'a b'>out\n  cat 2>&1 | wc")
+	if err != nil {
+		t.Fatalf("Lexer.Tokenize() error = %v", err)
+	}
+	expected := []Position{
+		{Offset: 0, Line: 1, Column: 1},   // x
+		{Offset: 2, Line: 1, Column: 3},   // 1
+		{Offset: 4, Line: 1, Column: 5},   // echo
+		{Offset: 9, Line: 1, Column: 10},  // 'a b'
+		{Offset: 14, Line: 1, Column: 15}, // >
+		{Offset: 15, Line: 1, Column: 16}, // out
+		{Offset: 18, Line: 1, Column: 19}, // перевод строки
+		{Offset: 21, Line: 2, Column: 3},  // cat
+		{Offset: 25, Line: 2, Column: 7},  // 2>&
+		{Offset: 28, Line: 2, Column: 10}, // 1
+		{Offset: 30, Line: 2, Column: 12}, // |
+		{Offset: 32, Line: 2, Column: 14}, // wc
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Lexer.Tokenize() returned %d tokens, expected %d: %v", len(tokens), len(expected), tokens)
+	}
+	for i, token := range tokens {
+		if token.Pos != expected[i] {
+			t.Errorf("Token %d (%v): pos = %+v, expected %+v", i, token, token.Pos, expected[i])
+		}
+	}
+
+	tokens, err = lexer.TokenizeAt("echo a\nls", Position{Offset: 100, Line: 10, Column: 1})
+	if err != nil {
+		t.Fatalf("Lexer.TokenizeAt() error = %v", err)
+	}
+	if last := tokens[len(tokens)-1].Pos; last != (Position{Offset: 107, Line: 11, Column: 1}) {
+		t.Errorf("Lexer.TokenizeAt() last token pos = %+v, expected 11:1", last)
+	}
+}
+
+// TestLexer_TokenizeAfter проверяет продолжение токенизации после токенов предыдущих строк:
+// они задают контекст следующей строки, а nesting оценивает число незакрытых составных команд.
+func TestLexer_TokenizeAfter(t *testing.T) {
+	tests := []struct {
+		name        string
+		prev        string
+		input       string
+		wantType    TokenType // Тип первого токена после перевода строки
+		wantNesting int
+	}{
+		{"assignment", "if true; then", "\nx=1", ASSIGN, 0},
+		{"case pattern", "case $x in", "\na=b) echo;;", WORD, 0},
+		{"compound", "while true; do", "\nif [[ -n $x ]]; then", WORD, 1},
+		{"closing words", "f() {", "\ndone; fi; }", WORD, -3},
+		{"function parens", "", "f() { echo; }", WORD, 0},
+		{"function keyword", "", "function f {", WORD, 1},
+		{"subshell", "", "( echo a", LPAREN, 1},
+		{"reserved word argument", "", "echo if for {", WORD, 0},
+	}
+
+	lexer := NewLexer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, err := lexer.Tokenize(tt.prev)
+			if err != nil {
+				t.Fatalf("Lexer.Tokenize(%q) error = %v", tt.prev, err)
+			}
+			start := Position{Offset: len(tt.prev), Line: 1, Column: len(tt.prev) + 1}
+			tokens, nesting, err := lexer.TokenizeAfter(prev, tt.input, start)
+			if err != nil {
+				t.Fatalf("Lexer.TokenizeAfter(%q) error = %v", tt.input, err)
+			}
+
+			first := tokens[len(prev)]
+			if first.Type == NEWLINE {
+				first = tokens[len(prev)+1]
+				if first.Pos.Line != 2 || first.Pos.Column != 1 {
+					t.Errorf("first token pos = %s, expected 2:1", first.Pos)
 				}
 			}
+			if first.Type != tt.wantType {
+				t.Errorf("first token = %v, expected type %v", first, tt.wantType)
+			}
+			if nesting != tt.wantNesting {
+				t.Errorf("Lexer.TokenizeAfter() nesting = %d, expected %d", nesting, tt.wantNesting)
+			}
 		})
 	}
 }
 
+// TestLexer_ErrorPositions проверяет, что ошибки лексера имеют тип *PosError
+// с позицией ошибочного места: открывающей кавычки, оператора << или подстановки.
+func TestLexer_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`echo "abc`, "1:6"},
+		{"echo a\necho 'b", "2:6"},
+		{"cat <<EOF\nbody", "1:5"},
+		{"echo $(ls", "1:6"},
+		{"echo a \\", "1:8"},
+		{"cat <<\nbody", "1:5"},
+	}
+
+	lexer := NewLexer()
+	for _, tt := range tests {
+		_, err := lexer.Tokenize(tt.input)
+		var posErr *PosError
+		if !errors.As(err, &posErr) {
+			t.Errorf("Lexer.Tokenize(%q) error = %v, expected *PosError", tt.input, err)
+			continue
+		}
+		if got := posErr.Pos.String(); got != tt.expected {
+			t.Errorf("Lexer.Tokenize(%q) error position = %s, expected %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// TestToken_IsReserved проверяет распознавание зарезервированных слов:
+// слово в кавычках или с экранированием остается обычным словом.
+func TestToken_IsReserved(t *testing.T) {
+	tests := []struct {
+		token    Token
+		expected bool
+	}{
+		{Token{Type: WORD, Value: "if"}, true},
+		{Token{Type: WORD, Value: "fi"}, true},
+		{Token{Type: WORD, Value: "{"}, true},
+		{Token{Type: WORD, Value: "function"}, true},
+		{Token{Type: WORD, Value: "[["}, true},
+		{Token{Type: WORD, Value: "["}, false},
+		{Token{Type: WORD, Value: "echo"}, false},
+		{Token{Type: WORD, Value: "{a,b}"}, false},
+		{Token{Type: DQUOTE, Value: "if"}, false},
+		{Token{Type: WORD, Value: `\if`}, false},
+		{Token{Type: WORD, Value: "if", Parts: []WordPart{{Type: WORD, Value: "i"}, {Type: SQUOTE, Value: "f"}}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.token.IsReserved(); got != tt.expected {
+			t.Errorf("%v.IsReserved() = %v, expected %v", tt.token, got, tt.expected)
+		}
+	}
+}
+
 // TestLexer_isValidVariableName тестирует валидацию имен переменных.
 // Проверяет корректность определения валидных и невалидных имен переменных
 // согласно правилам: начинаться с буквы или подчеркивания, содержать только буквы, цифры и подчеркивания.
@@ -0,0 +1,43 @@
+package lexer
+
+import "strconv"
+
+// Position описывает позицию в исходном тексте команды или скрипта.
+// Нулевое значение означает, что позиция неизвестна.
+type Position struct {
+	Offset int // Смещение от начала ввода в символах, начиная с 0
+	Line   int // Номер строки, начиная с 1
+	Column int // Номер символа в строке, начиная с 1
+}
+
+// IsValid проверяет, что позиция известна.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String возвращает позицию в формате "line:column".
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// PosError представляет ошибку лексического или синтаксического анализа
+// или подстановки вместе с позицией в исходном тексте, к которой она относится.
+// Сообщение ошибки не включает позицию: shell выводит ее отдельно вместе
+// с исходной строкой и указателем ^ под ошибочным местом.
+type PosError struct {
+	Pos Position // Позиция ошибки
+	Err error    // Исходная ошибка
+}
+
+// Error возвращает сообщение исходной ошибки.
+func (e *PosError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap возвращает исходную ошибку, чтобы errors.Is находил, например, ErrIncomplete.
+func (e *PosError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,135 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SubstitutionEnd находит конец подстановки, начинающейся в s с позиции start:
+// $(...), ${...} или `...`. Возвращает индекс байта сразу после закрывающего символа.
+// Вложенные подстановки, кавычки и экранирование внутри подстановки учитываются,
+// поэтому скобки и кавычки внутри строк не завершают подстановку.
+// Если подстановка не закрыта, возвращает ошибку ErrIncomplete.
+func SubstitutionEnd(s string, start int) (int, error) {
+	switch {
+	case strings.HasPrefix(s[start:], "$("):
+		return scanUntil(s, start+2, ')', '(')
+	case strings.HasPrefix(s[start:], "${"):
+		return scanUntil(s, start+2, '}', '{')
+	case strings.HasPrefix(s[start:], "`"):
+		return scanBackquote(s, start+1)
+	default:
+		return 0, fmt.Errorf("no substitution at position %d", start)
+	}
+}
+
+// isSubstitutionStart проверяет, начинается ли с позиции pos подстановка $(, ${ или `.
+func isSubstitutionStart(runes []rune, pos int) bool {
+	if runes[pos] == '`' {
+		return true
+	}
+	return runes[pos] == '$' && pos+1 < len(runes) && (runes[pos+1] == '(' || runes[pos+1] == '{')
+}
+
+// scanUntil ищет символ closing, закрывающий подстановку, начиная с позиции pos.
+// Символ opening увеличивает вложенность (например, скобки внутри $(...)).
+// Непарная скобка ), завершающая шаблон ветви case внутри $(...), подстановку
+// не закрывает (см. closesCasePattern).
+func scanUntil(s string, pos int, closing, opening byte) (int, error) {
+	start := pos
+	depth := 0
+	for pos < len(s) {
+		switch c := s[pos]; {
+		case c == '\\':
+			pos += 2
+			continue
+		case c == '\'':
+			end := strings.IndexByte(s[pos+1:], '\'')
+			if end == -1 {
+				return 0, unterminated(closing)
+			}
+			pos += end + 2
+			continue
+		case c == '"':
+			end, err := scanDoubleQuoted(s, pos+1)
+			if err != nil {
+				return 0, unterminated(closing)
+			}
+			pos = end
+			continue
+		case c == '`' || (c == '$' && pos+1 < len(s) && (s[pos+1] == '(' || s[pos+1] == '{')):
+			end, err := SubstitutionEnd(s, pos)
+			if err != nil {
+				return 0, err
+			}
+			pos = end
+			continue
+		case c == opening:
+			depth++
+		case c == closing:
+			if depth > 0 {
+				depth--
+			} else if closing != ')' || !closesCasePattern(s[start:pos]) {
+				return pos + 1, nil
+			}
+		}
+		pos++
+	}
+	return 0, unterminated(closing)
+}
+
+// closesCasePattern проверяет, что команды body внутри $(...) заканчиваются незавершенным
+// шаблоном ветви case, как в $(case a in a) ...; esac), и следующая за ними скобка )
+// завершает шаблон. Команды разбираются лексером: только он знает, где в них case.
+func closesCasePattern(body string) bool {
+	if !strings.Contains(body, "case") {
+		return false
+	}
+	tokens, err := NewLexer().Tokenize(body)
+	return err == nil && isCasePattern(tokens, len(tokens))
+}
+
+// scanDoubleQuoted ищет закрывающую двойную кавычку, начиная с позиции pos,
+// пропуская экранированные символы и вложенные подстановки.
+// Возвращает индекс сразу после закрывающей кавычки.
+func scanDoubleQuoted(s string, pos int) (int, error) {
+	for pos < len(s) {
+		switch c := s[pos]; {
+		case c == '\\':
+			pos += 2
+			continue
+		case c == '"':
+			return pos + 1, nil
+		case c == '`' || (c == '$' && pos+1 < len(s) && (s[pos+1] == '(' || s[pos+1] == '{')):
+			end, err := SubstitutionEnd(s, pos)
+			if err != nil {
+				return 0, err
+			}
+			pos = end
+			continue
+		}
+		pos++
+	}
+	return 0, fmt.Errorf("%w: unclosed double quote", ErrIncomplete)
+}
+
+// scanBackquote ищет закрывающую обратную кавычку, начиная с позиции pos.
+// Экранированная обратная кавычка (\`) не завершает подстановку.
+func scanBackquote(s string, pos int) (int, error) {
+	for pos < len(s) {
+		switch s[pos] {
+		case '\\':
+			pos += 2
+			continue
+		case '`':
+			return pos + 1, nil
+		}
+		pos++
+	}
+	return 0, unterminated('`')
+}
+
+// unterminated возвращает ошибку незакрытой подстановки.
+func unterminated(closing byte) error {
+	return fmt.Errorf("%w: unterminated substitution, expected %q", ErrIncomplete, closing)
+}
@@ -0,0 +1,48 @@
+package lexer
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSubstitutionEnd проверяет поиск конца подстановок $(...), ${...} и `...`
+// с учетом вложенности, кавычек и экранирования.
+func TestSubstitutionEnd(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		start    int
+		expected int
+	}{
+		{"command", "$(echo hi) rest", 0, 10},
+		{"nested", "$(a $(b) c)x", 0, 11},
+		{"paren in single quotes", "$(echo ')')x", 0, 11},
+		{"paren in double quotes", `$(echo ")")x`, 0, 11},
+		{"escaped paren", `$(echo \))x`, 0, 10},
+		{"arithmetic", "$((1 + (2)))x", 0, 12},
+		{"braced variable", "a${VAR}b", 1, 7},
+		{"backquote", "`pwd` x", 0, 5},
+		{"escaped backquote", "`echo \\`pwd\\``x", 0, 14},
+		{"case pattern", "$(case a in a) echo;; esac)x", 0, 27},
+		{"case pattern with parens", "$(case a in (a|b) echo;; *) ;; esac)x", 0, 36},
+		{"word case", "$(echo case)x", 0, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, err := SubstitutionEnd(tt.input, tt.start)
+			if err != nil {
+				t.Fatalf("SubstitutionEnd(%q) error = %v", tt.input, err)
+			}
+			if end != tt.expected {
+				t.Errorf("SubstitutionEnd(%q) = %d, expected %d", tt.input, end, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"$(echo", "${VAR", "`pwd", `$(echo ")`} {
+		if _, err := SubstitutionEnd(input, 0); !errors.Is(err, ErrIncomplete) {
+			t.Errorf("SubstitutionEnd(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+}
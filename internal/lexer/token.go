@@ -5,18 +5,81 @@ package lexer
 type TokenType int
 
 const (
-	WORD   TokenType = iota // Обычное слово или команда
-	PIPE                    // Оператор пайплайна (|)
-	SQUOTE                  // Одинарные кавычки (')
-	DQUOTE                  // Двойные кавычки (")
-	ASSIGN                  // Присваивание переменной (=)
+	WORD       TokenType = iota // Обычное слово или команда
+	PIPE                        // Оператор пайплайна (|)
+	SQUOTE                      // Одинарные кавычки (')
+	DQUOTE                      // Двойные кавычки (")
+	ASSIGN                      // Присваивание переменной (=)
+	REDIRECT                    // Оператор перенаправления (>, >>, <, 2>, >&, &>, ...)
+	SEMICOLON                   // Разделитель команд (;)
+	AND                         // Логическое И между пайплайнами (&&)
+	OR                          // Логическое ИЛИ между пайплайнами (||)
+	BACKGROUND                  // Фоновое выполнение (&)
+	NEWLINE                     // Перевод строки, разделяющий команды
+	ARITH                       // Заголовок арифметического цикла for ((...)) без внешних скобок
+	LPAREN                      // Открывающая скобка (
+	RPAREN                      // Закрывающая скобка ), завершающая шаблон ветви case
+	DSEMI                       // Завершение ветви case (;;, ;& или ;;&)
+)
+
+// reservedWords - зарезервированные слова shell'а. Значение true означает,
+// что после слова начинается новая команда (как после then в if true; then x=1 ...).
+var reservedWords = map[string]bool{
+	"if":       true,
+	"then":     true,
+	"elif":     true,
+	"else":     true,
+	"fi":       false,
+	"for":      false,
+	"in":       false,
+	"while":    true,
+	"until":    true,
+	"do":       true,
+	"done":     false,
+	"case":     false,
+	"esac":     false,
+	"{":        true,
+	"}":        false,
+	"[[":       false,
+	"]]":       false,
+	"function": false,
+}
+
+// Слова, открывающие и закрывающие составные команды (кроме { и }, см. nestingChange).
+var (
+	openingWords = []string{"if", "for", "while", "until", "case", "[["}
+	closingWords = []string{"fi", "done", "esac", "}", "]]"}
 )
 
 // Token представляет лексический токен - минимальную единицу разбора.
-// Содержит тип токена и его строковое значение.
+// Содержит тип токена, его строковое значение и позицию в исходном тексте.
+// Слово из одной части представляется токеном WORD, SQUOTE или DQUOTE,
+// а составное слово (foo"bar"'baz') - токеном WORD со списком частей Parts;
+// Value составного слова - объединение значений частей.
 type Token struct {
-	Type  TokenType // Тип токена (WORD, PIPE, SQUOTE, DQUOTE, ASSIGN)
-	Value string    // Строковое значение токена
+	Type  TokenType  // Тип токена (WORD, PIPE, SQUOTE, DQUOTE, ASSIGN, REDIRECT, ...)
+	Value string     // Строковое значение токена
+	Parts []WordPart // Части составного слова; пусто, если слово состоит из одной части
+	Pos   Position   // Позиция первого символа токена (для тела here-document - позиция разделителя)
+}
+
+// WordPart представляет часть составного слова: текст без кавычек (WORD),
+// в одинарных (SQUOTE) или в двойных кавычках (DQUOTE).
+type WordPart struct {
+	Type  TokenType // WORD, SQUOTE или DQUOTE
+	Value string    // Текст части без кавычек
+}
+
+// String возвращает часть слова в исходной записи с кавычками.
+func (p WordPart) String() string {
+	switch p.Type {
+	case SQUOTE:
+		return "'" + p.Value + "'"
+	case DQUOTE:
+		return `"` + p.Value + `"`
+	default:
+		return p.Value
+	}
 }
 
 // String возвращает строковое представление токена для отладки.
@@ -24,6 +87,13 @@ type Token struct {
 func (t Token) String() string {
 	switch t.Type {
 	case WORD:
+		if len(t.Parts) > 0 {
+			var word string
+			for _, part := range t.Parts {
+				word += part.String()
+			}
+			return "WORD(" + word + ")"
+		}
 		return "WORD(" + t.Value + ")"
 	case PIPE:
 		return "PIPE"
@@ -33,7 +103,35 @@ func (t Token) String() string {
 		return "DQUOTE(" + t.Value + ")"
 	case ASSIGN:
 		return "ASSIGN(" + t.Value + ")"
+	case REDIRECT:
+		return "REDIRECT(" + t.Value + ")"
+	case SEMICOLON:
+		return "SEMICOLON"
+	case AND:
+		return "AND"
+	case OR:
+		return "OR"
+	case BACKGROUND:
+		return "BACKGROUND"
+	case NEWLINE:
+		return "NEWLINE"
+	case ARITH:
+		return "ARITH(" + t.Value + ")"
+	case LPAREN:
+		return "LPAREN"
+	case RPAREN:
+		return "RPAREN"
+	case DSEMI:
+		return "DSEMI(" + t.Value + ")"
 	default:
 		return "UNKNOWN"
 	}
 }
+
+// IsReserved проверяет, является ли токен зарезервированным словом (if, then, fi, ...).
+// Зарезервированное слово записывается без кавычек и экранирования: "if" и \if - обычные слова.
+// Парсер распознает такие слова только в начале команды, в echo if слово if - аргумент.
+func (t Token) IsReserved() bool {
+	_, ok := reservedWords[t.Value]
+	return ok && t.Type == WORD && len(t.Parts) == 0
+}
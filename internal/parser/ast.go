@@ -1,10 +1,17 @@
 package parser
 
+import (
+	"strconv"
+
+	"gocli/internal/lexer"
+)
+
 // Node представляет узел абстрактного синтаксического дерева (AST).
 // Все узлы AST должны реализовывать этот интерфейс.
 type Node interface {
-	String() string // Возвращает строковое представление узла
-	Type() NodeType // Возвращает тип узла для классификации
+	String() string      // Возвращает строковое представление узла
+	Type() NodeType      // Возвращает тип узла для классификации
+	Pos() lexer.Position // Возвращает позицию начала узла в исходном тексте
 }
 
 // NodeType определяет тип узла в AST.
@@ -12,18 +19,37 @@ type Node interface {
 type NodeType int
 
 const (
-	CommandNode    NodeType = iota // Узел команды
-	PipelineNode                   // Узел пайплайна
-	AssignmentNode                 // Узел присваивания переменной
-	ArgumentNode                   // Узел аргумента
+	CommandNode     NodeType = iota // Узел команды
+	PipelineNode                    // Узел пайплайна
+	AssignmentNode                  // Узел присваивания переменной
+	ArgumentNode                    // Узел аргумента
+	RedirectNode                    // Узел перенаправления ввода-вывода
+	AndOrNode                       // Узел цепочки пайплайнов, соединенных && и ||
+	ListNode                        // Узел списка команд, разделенных ;
+	IfNode                          // Узел условной конструкции if
+	ForNode                         // Узел цикла for по списку слов
+	ArithForNode                    // Узел арифметического цикла for ((...))
+	WhileNode                       // Узел цикла while или until
+	CaseNode                        // Узел конструкции выбора case
+	GroupNode                       // Узел группы команд { ...; }
+	SubshellNode                    // Узел подоболочки ( ... )
+	FunctionNode                    // Узел определения функции
+	ConditionalNode                 // Узел условного выражения [[ ... ]]
+	ProgramNode                     // Корневой узел программы: все команды скрипта или ввода
 )
 
 // Command представляет команду в AST.
-// Содержит имя команды, аргументы и присваивания переменных окружения.
+// Содержит имя команды, аргументы, присваивания переменных окружения
+// и перенаправления ввода-вывода. Составная команда (if, циклы, case, группы) с перенаправлениями
+// или в составе пайплайна хранится в Compound; Name и Args у нее пусты.
 type Command struct {
-	Name        string        // Имя команды (например, "echo", "cat")
-	Args        []*Argument   // Аргументы команды
-	Assignments []*Assignment // Присваивания переменных окружения
+	Compound    Node           // Составная команда; nil для простой команды
+	Name        string         // Имя команды (например, "echo", "cat")
+	NameArg     *Argument      // Слово имени команды с кавычками; раскрывается вместе с аргументами
+	Args        []*Argument    // Аргументы команды
+	Assignments []*Assignment  // Присваивания переменных окружения
+	Redirects   []*Redirect    // Перенаправления ввода-вывода в порядке появления
+	Position    lexer.Position // Позиция первого слова, присваивания или перенаправления команды
 }
 
 // Type возвращает тип узла Command.
@@ -31,20 +57,32 @@ func (c *Command) Type() NodeType {
 	return CommandNode
 }
 
+// Pos возвращает позицию узла Command в исходном тексте.
+func (c *Command) Pos() lexer.Position {
+	return c.Position
+}
+
 // String возвращает строковое представление команды.
 // Формат: "command arg1 arg2 ..."
 func (c *Command) String() string {
 	result := c.Name
+	if c.Compound != nil {
+		result = c.Compound.String()
+	}
 	for _, arg := range c.Args {
 		result += " " + arg.String()
 	}
+	for _, redirect := range c.Redirects {
+		result += " " + redirect.String()
+	}
 	return result
 }
 
 // Pipeline представляет пайплайн команд в AST.
 // Содержит последовательность команд, соединенных оператором |.
 type Pipeline struct {
-	Commands []*Command // Список команд в пайплайне
+	Commands []*Command     // Список команд в пайплайне
+	Position lexer.Position // Позиция первой команды пайплайна
 }
 
 // Type возвращает тип узла Pipeline.
@@ -52,6 +90,11 @@ func (p *Pipeline) Type() NodeType {
 	return PipelineNode
 }
 
+// Pos возвращает позицию узла Pipeline в исходном тексте.
+func (p *Pipeline) Pos() lexer.Position {
+	return p.Position
+}
+
 // String возвращает строковое представление пайплайна.
 // Формат: "cmd1 | cmd2 | cmd3"
 func (p *Pipeline) String() string {
@@ -66,11 +109,511 @@ func (p *Pipeline) String() string {
 	return result
 }
 
+// AndOrOperator определяет оператор, соединяющий пайплайны в цепочке AndOr.
+type AndOrOperator int
+
+const (
+	AndOperator AndOrOperator = iota // Выполнить следующий пайплайн при успехе (&&)
+	OrOperator                       // Выполнить следующий пайплайн при неудаче (||)
+)
+
+// String возвращает текстовое представление оператора.
+func (op AndOrOperator) String() string {
+	if op == AndOperator {
+		return "&&"
+	}
+	return "||"
+}
+
+// AndOr представляет цепочку пайплайнов, соединенных операторами && и ||.
+// Операторы имеют одинаковый приоритет и вычисляются слева направо:
+// Operators[i] соединяет Pipelines[i] и Pipelines[i+1].
+type AndOr struct {
+	Pipelines  []Node          // Пайплайны цепочки (*Command или *Pipeline)
+	Operators  []AndOrOperator // Операторы между пайплайнами
+	Background bool            // Цепочка завершается оператором & и выполняется в фоне
+	Position   lexer.Position  // Позиция первого пайплайна цепочки
+}
+
+// Type возвращает тип узла AndOr.
+func (a *AndOr) Type() NodeType {
+	return AndOrNode
+}
+
+// Pos возвращает позицию узла AndOr в исходном тексте.
+func (a *AndOr) Pos() lexer.Position {
+	return a.Position
+}
+
+// String возвращает строковое представление цепочки.
+// Формат: "cmd1 && cmd2 || cmd3". Оператор & выводится списком (см. List.String).
+func (a *AndOr) String() string {
+	if len(a.Pipelines) == 0 {
+		return ""
+	}
+
+	result := a.Pipelines[0].String()
+	for i, op := range a.Operators {
+		result += " " + op.String() + " " + a.Pipelines[i+1].String()
+	}
+	return result
+}
+
+// List представляет список команд, разделенных операторами ; и &.
+// Элементы списка выполняются последовательно; элементы с оператором &
+// запускаются в фоне, и shell не ждет их завершения.
+type List struct {
+	Items    []*AndOr       // Элементы списка
+	Position lexer.Position // Позиция первого элемента списка
+}
+
+// Type возвращает тип узла List.
+func (l *List) Type() NodeType {
+	return ListNode
+}
+
+// Pos возвращает позицию узла List в исходном тексте.
+func (l *List) Pos() lexer.Position {
+	return l.Position
+}
+
+// String возвращает строковое представление списка.
+// Формат: "cmd1; cmd2 && cmd3", фоновые элементы: "cmd1 & cmd2"
+func (l *List) String() string {
+	result := ""
+	for i, item := range l.Items {
+		if i > 0 {
+			if l.Items[i-1].Background {
+				result += " "
+			} else {
+				result += "; "
+			}
+		}
+		result += item.String()
+		if item.Background {
+			result += " &"
+		}
+	}
+	return result
+}
+
+// Program представляет корень AST целого скрипта или многострочного ввода:
+// список команд, разделенных переводами строк и ;. Программа может быть пустой
+// (пустая строка или только комментарии).
+type Program struct {
+	Body     *List          // Команды программы
+	Position lexer.Position // Позиция первой команды программы
+}
+
+// Type возвращает тип узла Program.
+func (p *Program) Type() NodeType {
+	return ProgramNode
+}
+
+// Pos возвращает позицию узла Program в исходном тексте.
+func (p *Program) Pos() lexer.Position {
+	return p.Position
+}
+
+// String возвращает строковое представление программы.
+// Формат: "cmd1; cmd2"
+func (p *Program) String() string {
+	return p.Body.String()
+}
+
+// IsEmpty проверяет, что в программе нет команд.
+func (p *Program) IsEmpty() bool {
+	return len(p.Body.Items) == 0
+}
+
+// IfClause представляет ветвь if или elif: тело выполняется,
+// если список команд условия завершился с нулевым статусом.
+type IfClause struct {
+	Condition *List // Условие - произвольный список команд
+	Body      *List // Тело ветви
+}
+
+// If представляет условную конструкцию if ... then ... elif ... else ... fi.
+// Ветви проверяются по порядку: выполняется тело первой ветви, условие которой
+// завершилось успешно, а если таких нет - ветвь Else.
+type If struct {
+	Clauses  []*IfClause    // Ветви if и elif
+	Else     *List          // Ветвь else; nil, если ее нет
+	Position lexer.Position // Позиция слова if
+}
+
+// Type возвращает тип узла If.
+func (i *If) Type() NodeType {
+	return IfNode
+}
+
+// Pos возвращает позицию узла If в исходном тексте.
+func (i *If) Pos() lexer.Position {
+	return i.Position
+}
+
+// String возвращает строковое представление условной конструкции.
+// Формат: "if cond; then body; elif cond; then body; else body; fi"
+func (i *If) String() string {
+	result := ""
+	for n, clause := range i.Clauses {
+		if n == 0 {
+			result += "if "
+		} else {
+			result += "elif "
+		}
+		result += compoundListString(clause.Condition) + "then " + compoundListString(clause.Body)
+	}
+	if i.Else != nil {
+		result += "else " + compoundListString(i.Else)
+	}
+	return result + "fi"
+}
+
+// For представляет цикл for name in words; do body; done.
+// Тело выполняется для каждого слова списка после подстановок; переменная Variable
+// получает значение очередного слова. Без in цикл перебирает позиционные параметры.
+type For struct {
+	Variable string         // Имя переменной цикла
+	Words    []*Argument    // Слова после in; nil, если in не указан
+	Body     *List          // Тело цикла
+	Position lexer.Position // Позиция слова for
+}
+
+// Type возвращает тип узла For.
+func (f *For) Type() NodeType {
+	return ForNode
+}
+
+// Pos возвращает позицию узла For в исходном тексте.
+func (f *For) Pos() lexer.Position {
+	return f.Position
+}
+
+// String возвращает строковое представление цикла.
+// Формат: "for x in a b; do body; done", без in: "for x; do body; done"
+func (f *For) String() string {
+	result := "for " + f.Variable
+	if f.Words != nil {
+		result += " in"
+		for _, word := range f.Words {
+			result += " " + word.String()
+		}
+	}
+	return result + "; do " + compoundListString(f.Body) + "done"
+}
+
+// ArithFor представляет арифметический цикл for ((init; cond; update)); do body; done.
+// Init вычисляется один раз, тело выполняется, пока значение Cond не равно нулю,
+// после каждой итерации вычисляется Update. Пустое условие считается истинным.
+type ArithFor struct {
+	Init     string         // Выражение инициализации
+	Cond     string         // Условие продолжения цикла
+	Update   string         // Выражение, вычисляемое после каждой итерации
+	Body     *List          // Тело цикла
+	Position lexer.Position // Позиция слова for
+}
+
+// Type возвращает тип узла ArithFor.
+func (f *ArithFor) Type() NodeType {
+	return ArithForNode
+}
+
+// Pos возвращает позицию узла ArithFor в исходном тексте.
+func (f *ArithFor) Pos() lexer.Position {
+	return f.Position
+}
+
+// String возвращает строковое представление цикла.
+// Формат: "for ((i = 0; i < n; i++)); do body; done"
+func (f *ArithFor) String() string {
+	return "for ((" + f.Init + "; " + f.Cond + "; " + f.Update + ")); do " + compoundListString(f.Body) + "done"
+}
+
+// While представляет циклы while cond; do body; done и until cond; do body; done.
+// Тело while выполняется, пока список условия завершается с кодом 0,
+// тело until - пока условие завершается с ненулевым кодом.
+type While struct {
+	Condition *List          // Условие - произвольный список команд
+	Body      *List          // Тело цикла
+	Until     bool           // Цикл until: условие проверяется на неуспех
+	Position  lexer.Position // Позиция слова while или until
+}
+
+// Type возвращает тип узла While.
+func (w *While) Type() NodeType {
+	return WhileNode
+}
+
+// Pos возвращает позицию узла While в исходном тексте.
+func (w *While) Pos() lexer.Position {
+	return w.Position
+}
+
+// String возвращает строковое представление цикла.
+// Формат: "while cond; do body; done" или "until cond; do body; done"
+func (w *While) String() string {
+	keyword := "while "
+	if w.Until {
+		keyword = "until "
+	}
+	return keyword + compoundListString(w.Condition) + "do " + compoundListString(w.Body) + "done"
+}
+
+// CaseTerminator определяет оператор, завершающий ветвь case.
+type CaseTerminator int
+
+const (
+	CaseBreak       CaseTerminator = iota // ;; - завершить выполнение case
+	CaseFallThrough                       // ;& - выполнить тело следующей ветви без проверки шаблонов
+	CaseContinue                          // ;;& - продолжить проверку шаблонов следующих ветвей
+)
+
+// String возвращает текстовое представление оператора.
+func (t CaseTerminator) String() string {
+	switch t {
+	case CaseFallThrough:
+		return ";&"
+	case CaseContinue:
+		return ";;&"
+	default:
+		return ";;"
+	}
+}
+
+// CaseItem представляет ветвь case: шаблоны, разделенные |, и тело ветви.
+type CaseItem struct {
+	Patterns   []*Argument    // Шаблоны имен (glob), с которыми сравнивается слово
+	Body       *List          // Тело ветви; может быть пустым
+	Terminator CaseTerminator // Оператор, завершающий ветвь
+}
+
+// Case представляет конструкцию выбора case word in pattern) body;; ... esac.
+// Слово сравнивается с шаблонами ветвей по порядку; выполняется тело первой
+// подходящей ветви, дальнейшее поведение определяет оператор ее завершения.
+type Case struct {
+	Word     *Argument      // Сравниваемое слово
+	Items    []*CaseItem    // Ветви в порядке записи
+	Position lexer.Position // Позиция слова case
+}
+
+// Type возвращает тип узла Case.
+func (c *Case) Type() NodeType {
+	return CaseNode
+}
+
+// Pos возвращает позицию узла Case в исходном тексте.
+func (c *Case) Pos() lexer.Position {
+	return c.Position
+}
+
+// String возвращает строковое представление конструкции.
+// Формат: "case $1 in start|stop) body;; *) body;; esac"
+func (c *Case) String() string {
+	result := "case " + c.Word.String() + " in "
+	for _, item := range c.Items {
+		for i, pattern := range item.Patterns {
+			if i > 0 {
+				result += "|"
+			}
+			result += pattern.String()
+		}
+		result += ") "
+		if len(item.Body.Items) > 0 {
+			result += item.Body.String()
+		}
+		result += item.Terminator.String() + " "
+	}
+	return result + "esac"
+}
+
+// Group представляет группу команд { list; }, выполняемую в текущем shell'е.
+type Group struct {
+	Body     *List          // Команды группы
+	Position lexer.Position // Позиция открывающей {
+}
+
+// Type возвращает тип узла Group.
+func (g *Group) Type() NodeType {
+	return GroupNode
+}
+
+// Pos возвращает позицию узла Group в исходном тексте.
+func (g *Group) Pos() lexer.Position {
+	return g.Position
+}
+
+// String возвращает строковое представление группы.
+// Формат: "{ cmd1; cmd2; }"
+func (g *Group) String() string {
+	return "{ " + compoundListString(g.Body) + "}"
+}
+
+// Subshell представляет список команд ( list ), выполняемый в подоболочке:
+// изменения переменных и рабочего каталога внутри нее не видны снаружи.
+type Subshell struct {
+	Body     *List          // Команды подоболочки
+	Position lexer.Position // Позиция открывающей (
+}
+
+// Type возвращает тип узла Subshell.
+func (s *Subshell) Type() NodeType {
+	return SubshellNode
+}
+
+// Pos возвращает позицию узла Subshell в исходном тексте.
+func (s *Subshell) Pos() lexer.Position {
+	return s.Position
+}
+
+// String возвращает строковое представление подоболочки.
+// Формат: "(cmd1; cmd2)"
+func (s *Subshell) String() string {
+	return "(" + s.Body.String() + ")"
+}
+
+// Function представляет определение функции name() body или function name body.
+// Определение лишь сохраняет функцию; тело выполняется при вызове функции по имени,
+// а аргументы вызова становятся позиционными параметрами тела.
+type Function struct {
+	Name     string         // Имя функции
+	Body     Node           // Тело - составная команда (обычно группа { ...; } или подоболочка), возможно с перенаправлениями
+	Position lexer.Position // Позиция слова function или имени функции
+}
+
+// Type возвращает тип узла Function.
+func (f *Function) Type() NodeType {
+	return FunctionNode
+}
+
+// Pos возвращает позицию узла Function в исходном тексте.
+func (f *Function) Pos() lexer.Position {
+	return f.Position
+}
+
+// String возвращает строковое представление определения функции.
+// Формат: "name () { body; }"
+func (f *Function) String() string {
+	return f.Name + " () " + f.Body.String()
+}
+
+// Conditional представляет условное выражение [[ expr ]]. В отличие от команды test,
+// операнды не разбиваются на поля и не раскрываются как шаблоны имен файлов,
+// а операторы &&, ||, <, > и скобки входят в выражение.
+type Conditional struct {
+	Expr     CondExpr       // Выражение
+	Position lexer.Position // Позиция открывающего [[
+}
+
+// Type возвращает тип узла Conditional.
+func (c *Conditional) Type() NodeType {
+	return ConditionalNode
+}
+
+// Pos возвращает позицию узла Conditional в исходном тексте.
+func (c *Conditional) Pos() lexer.Position {
+	return c.Position
+}
+
+// String возвращает строковое представление условного выражения.
+// Формат: "[[ expr ]]"
+func (c *Conditional) String() string {
+	return "[[ " + c.Expr.String() + " ]]"
+}
+
+// CondExpr представляет узел выражения внутри [[ ]].
+type CondExpr interface {
+	String() string // Возвращает строковое представление выражения
+}
+
+// CondWord - операнд без оператора: истинен, если его значение не пусто.
+type CondWord struct {
+	Word *Argument // Операнд
+}
+
+// String возвращает операнд.
+func (w *CondWord) String() string {
+	return w.Word.String()
+}
+
+// CondUnary - унарная проверка файла или строки (-f file, -z string, ...).
+type CondUnary struct {
+	Op      string    // Оператор (например, "-f")
+	Operand *Argument // Операнд
+}
+
+// String возвращает строковое представление проверки.
+// Формат: "-f file"
+func (u *CondUnary) String() string {
+	return u.Op + " " + u.Operand.String()
+}
+
+// CondBinary - бинарная проверка: сравнение с шаблоном (==, !=), с регулярным
+// выражением (=~), сравнение строк (<, >), целых чисел (-eq, ...) или файлов (-nt, ...).
+type CondBinary struct {
+	Op    string    // Оператор (например, "==")
+	Left  *Argument // Левый операнд
+	Right *Argument // Правый операнд: шаблон для == и !=, регулярное выражение для =~
+}
+
+// String возвращает строковое представление проверки.
+// Формат: "left == right"
+func (b *CondBinary) String() string {
+	return b.Left.String() + " " + b.Op + " " + b.Right.String()
+}
+
+// CondNot - отрицание выражения (! expr).
+type CondNot struct {
+	Expr CondExpr // Отрицаемое выражение
+}
+
+// String возвращает строковое представление отрицания.
+// Формат: "! expr"
+func (n *CondNot) String() string {
+	return "! " + n.Expr.String()
+}
+
+// CondAndOr - выражения, соединенные оператором && или ||. && имеет больший приоритет,
+// а правое выражение вычисляется, только если от него зависит результат.
+type CondAndOr struct {
+	Op    AndOrOperator // Оператор
+	Left  CondExpr      // Левое выражение
+	Right CondExpr      // Правое выражение
+}
+
+// String возвращает строковое представление выражения.
+// Формат: "left && right"
+func (a *CondAndOr) String() string {
+	return a.Left.String() + " " + a.Op.String() + " " + a.Right.String()
+}
+
+// CondGroup - выражение в скобках ( expr ).
+type CondGroup struct {
+	Expr CondExpr // Выражение в скобках
+}
+
+// String возвращает строковое представление выражения в скобках.
+// Формат: "( expr )"
+func (g *CondGroup) String() string {
+	return "( " + g.Expr.String() + " )"
+}
+
+// compoundListString возвращает список команд внутри составной команды
+// с завершающим разделителем перед следующим зарезервированным словом.
+func compoundListString(l *List) string {
+	result := l.String()
+	if len(l.Items) > 0 && l.Items[len(l.Items)-1].Background {
+		return result + " "
+	}
+	return result + "; "
+}
+
 // Assignment представляет присваивание переменной окружения в AST.
 // Содержит имя переменной и её значение.
 type Assignment struct {
-	Name  string    // Имя переменной
-	Value *Argument // Значение переменной
+	Name     string         // Имя переменной
+	Value    *Argument      // Значение переменной
+	Position lexer.Position // Позиция имени переменной
 }
 
 // Type возвращает тип узла Assignment.
@@ -78,6 +621,11 @@ func (a *Assignment) Type() NodeType {
 	return AssignmentNode
 }
 
+// Pos возвращает позицию узла Assignment в исходном тексте.
+func (a *Assignment) Pos() lexer.Position {
+	return a.Position
+}
+
 // String возвращает строковое представление присваивания.
 // Формат: "NAME=value"
 func (a *Assignment) String() string {
@@ -95,10 +643,30 @@ const (
 
 // Argument представляет аргумент команды в AST.
 // Содержит значение аргумента и информацию о типе кавычек.
+// Составное слово (foo"bar"'baz') хранит последовательность частей в Parts,
+// которые expander раскрывает по правилам их кавычек и объединяет в одно слово;
+// Value составного слова - объединение значений частей.
 type Argument struct {
-	Value     string    // Значение аргумента
-	Quoted    bool      // Флаг: был ли аргумент в кавычках (для обратной совместимости)
-	QuoteType QuoteType // Тип кавычек: NoQuote, SingleQuote или DoubleQuote
+	Value     string         // Значение аргумента
+	Quoted    bool           // Флаг: был ли аргумент в кавычках (для обратной совместимости)
+	QuoteType QuoteType      // Тип кавычек: NoQuote, SingleQuote или DoubleQuote
+	Parts     []WordPart     // Части составного слова; пусто, если слово состоит из одной части
+	Position  lexer.Position // Позиция первого символа слова
+}
+
+// WordPart представляет часть составного слова с собственным типом кавычек.
+type WordPart struct {
+	Value     string    // Текст части без кавычек
+	QuoteType QuoteType // Тип кавычек части
+}
+
+// WordParts возвращает части слова. Слово из одной части возвращается
+// как единственная часть со значением и типом кавычек аргумента.
+func (a *Argument) WordParts() []WordPart {
+	if len(a.Parts) > 0 {
+		return a.Parts
+	}
+	return []WordPart{{Value: a.Value, QuoteType: a.QuoteType}}
 }
 
 // Type возвращает тип узла Argument.
@@ -106,11 +674,114 @@ func (a *Argument) Type() NodeType {
 	return ArgumentNode
 }
 
+// Pos возвращает позицию узла Argument в исходном тексте.
+func (a *Argument) Pos() lexer.Position {
+	return a.Position
+}
+
 // String возвращает строковое представление аргумента.
-// Если аргумент был в кавычках, возвращает его в двойных кавычках.
+// Если аргумент был в кавычках, возвращает его в двойных кавычках;
+// части составного слова выводятся каждая со своими кавычками.
 func (a *Argument) String() string {
+	if len(a.Parts) > 0 {
+		result := ""
+		for _, part := range a.Parts {
+			switch part.QuoteType {
+			case SingleQuote:
+				result += "'" + part.Value + "'"
+			case DoubleQuote:
+				result += `"` + part.Value + `"`
+			default:
+				result += part.Value
+			}
+		}
+		return result
+	}
 	if a.Quoted {
 		return `"` + a.Value + `"`
 	}
 	return a.Value
 }
+
+// RedirectOp определяет вид перенаправления ввода-вывода.
+type RedirectOp int
+
+const (
+	RedirectOutput     RedirectOp = iota // Вывод в файл с усечением (>)
+	RedirectAppend                       // Вывод в файл с дозаписью (>>)
+	RedirectInput                        // Ввод из файла (<)
+	RedirectDupOutput                    // Дублирование дескриптора вывода (>&)
+	RedirectDupInput                     // Дублирование дескриптора ввода (<&)
+	RedirectAll                          // stdout и stderr в файл с усечением (&>)
+	RedirectAppendAll                    // stdout и stderr в файл с дозаписью (&>>)
+	RedirectHeredoc                      // Here-document: ввод из тела документа (<<, <<-)
+	RedirectHereString                   // Here-string: ввод из строки (<<<)
+)
+
+// String возвращает текстовое представление оператора перенаправления.
+func (op RedirectOp) String() string {
+	switch op {
+	case RedirectOutput:
+		return ">"
+	case RedirectAppend:
+		return ">>"
+	case RedirectInput:
+		return "<"
+	case RedirectDupOutput:
+		return ">&"
+	case RedirectDupInput:
+		return "<&"
+	case RedirectAll:
+		return "&>"
+	case RedirectAppendAll:
+		return "&>>"
+	case RedirectHeredoc:
+		return "<<"
+	case RedirectHereString:
+		return "<<<"
+	default:
+		return "?"
+	}
+}
+
+// DefaultFd возвращает дескриптор, к которому относится оператор,
+// если номер дескриптора не указан явно (0 для ввода, 1 для вывода).
+func (op RedirectOp) DefaultFd() int {
+	switch op {
+	case RedirectInput, RedirectDupInput, RedirectHeredoc, RedirectHereString:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// Redirect представляет перенаправление ввода-вывода в AST.
+// Например, 2>>log.txt: Fd = 2, Op = RedirectAppend, Target = "log.txt".
+// Для дублирования (2>&1) Target содержит номер дескриптора-источника,
+// для here-document - тело документа, для here-string - строку ввода.
+type Redirect struct {
+	Fd       int            // Номер перенаправляемого файлового дескриптора
+	Op       RedirectOp     // Вид перенаправления
+	Target   *Argument      // Имя файла, номер дескриптора или текст ввода
+	Position lexer.Position // Позиция оператора перенаправления
+}
+
+// Type возвращает тип узла Redirect.
+func (r *Redirect) Type() NodeType {
+	return RedirectNode
+}
+
+// Pos возвращает позицию узла Redirect в исходном тексте.
+func (r *Redirect) Pos() lexer.Position {
+	return r.Position
+}
+
+// String возвращает строковое представление перенаправления.
+// Формат: "2>file", ">>file", "2>&1"
+func (r *Redirect) String() string {
+	result := ""
+	if r.Op != RedirectAll && r.Op != RedirectAppendAll && r.Fd != r.Op.DefaultFd() {
+		result = strconv.Itoa(r.Fd)
+	}
+	return result + r.Op.String() + r.Target.String()
+}
@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gocli/internal/lexer"
+)
+
+// IncrementalParser разбирает ввод, поступающий по строкам (в REPL или из файла скрипта).
+// Строки накапливаются, пока ввод не станет полным: незавершенные составные команды,
+// кавычки, here-document и продолжение строки обратным слешем требуют следующих строк.
+// Нумерация строк сквозная для всего разобранного ввода, а последние строки сохраняются,
+// чтобы по позиции ошибки можно было показать исходную строку (см. SourceLine).
+//
+// Накопленный ввод не разбирается заново целиком при каждой строке: лексер разбирает
+// только новые строки, продолжая токены предыдущих, а парсер запускается снова,
+// только если новые строки могут закрыть незавершенную составную команду.
+type IncrementalParser struct {
+	lexer      *lexer.Lexer    // Лексер для токенизации накопленного ввода
+	parser     *Parser         // Парсер для построения программы
+	pending    strings.Builder // Накопленные строки незавершенного ввода
+	tokens     []lexer.Token   // Токены накопленных строк, уже разобранных лексером
+	chunk      string          // Строки, которые лексер еще не может разобрать (кавычки, here-document)
+	chunkStart lexer.Position  // Позиция начала chunk
+	end        lexer.Position  // Позиция конца последней накопленной строки
+	nesting    int             // Оценка числа незакрытых составных команд в tokens
+	incomplete error           // Ошибка парсера о незакрытой составной команде или nil
+	start      lexer.Position  // Позиция начала следующего ввода
+	lines      []string        // Последние строки разобранного ввода; lines[i] - строка с номером firstLine+i
+	firstLine  int             // Номер первой сохраненной строки
+}
+
+// maxSourceLines - число сохраняемых строк разобранного ввода. Более старые строки
+// отбрасываются, чтобы долгая сессия или длинный скрипт не хранили весь ввод;
+// строки последнего ввода сохраняются всегда.
+const maxSourceLines = 1000
+
+// NewIncrementalParser создает новый экземпляр инкрементального парсера.
+func NewIncrementalParser() *IncrementalParser {
+	return &IncrementalParser{
+		lexer:     lexer.NewLexer(),
+		parser:    NewParser(),
+		start:     lexer.Position{Line: 1, Column: 1},
+		firstLine: 1,
+	}
+}
+
+// Feed добавляет строку ввода (без перевода строки) и разбирает накопленный ввод.
+// Если ввод полон, возвращает программу из всех накопленных команд и начинает накопление
+// заново. Если ввод не завершен, возвращает ошибку, оборачивающую lexer.ErrIncomplete:
+// строка сохраняется, и следующий вызов Feed продолжит ввод. При синтаксической ошибке
+// накопленный ввод отбрасывается.
+func (ip *IncrementalParser) Feed(line string) (*Program, error) {
+	lineStart := ip.start
+	if ip.pending.Len() > 0 {
+		ip.pending.WriteByte('\n')
+		lineStart = lexer.Position{Offset: ip.end.Offset + 1, Line: ip.end.Line + 1, Column: 1}
+	}
+	ip.pending.WriteString(line)
+
+	// Новый фрагмент для лексера начинается с перевода строки после уже разобранных токенов
+	switch {
+	case ip.chunk != "":
+		ip.chunk += "\n" + line
+	case len(ip.tokens) > 0:
+		ip.chunk, ip.chunkStart = "\n"+line, ip.end
+	default:
+		ip.chunk, ip.chunkStart = line, lineStart
+	}
+	length := utf8.RuneCountInString(line)
+	ip.end = lexer.Position{Offset: lineStart.Offset + length, Line: lineStart.Line, Column: length + 1}
+
+	tokens, nesting, err := ip.lexer.TokenizeAfter(ip.tokens, ip.chunk, ip.chunkStart)
+	if err != nil {
+		return ip.finish(nil, fmt.Errorf("lexical analysis failed: %w", err))
+	}
+	ip.tokens, ip.chunk = tokens, ""
+	ip.nesting += nesting
+
+	// Пока лексер видит незакрытые составные команды, парсер сообщит то же самое
+	if ip.incomplete != nil && ip.nesting > 0 {
+		return nil, ip.incomplete
+	}
+
+	program, err := ip.parser.ParseProgram(tokens)
+	if err != nil {
+		err = fmt.Errorf("parsing failed: %w", err)
+	}
+	return ip.finish(program, err)
+}
+
+// finish завершает обработку строки в Feed: незавершенный ввод сохраняется
+// для следующих строк, а полный (или ошибочный) ввод отбрасывается, и его строки
+// получают номера.
+func (ip *IncrementalParser) finish(program *Program, err error) (*Program, error) {
+	if errors.Is(err, lexer.ErrIncomplete) {
+		// Незавершенность лексера (кавычки, here-document) не связана с составными командами
+		if ip.chunk == "" {
+			ip.incomplete = err
+		}
+		return nil, err
+	}
+	input := ip.pending.String()
+	ip.Reset()
+	ip.advance(input)
+	return program, err
+}
+
+// Parse разбирает полный ввод из одной или нескольких строк, не затрагивая
+// накопленные строки. Незавершенный ввод дает ошибку с lexer.ErrIncomplete.
+// Строки ввода получают следующие номера, как и при вызовах Feed.
+func (ip *IncrementalParser) Parse(input string) (*Program, error) {
+	program, err := ip.parse(input)
+	ip.advance(input)
+	return program, err
+}
+
+// SourceLine возвращает разобранную строку ввода с номером n (начиная с 1).
+// Для отброшенных старых строк (см. maxSourceLines) возвращает false.
+func (ip *IncrementalParser) SourceLine(n int) (string, bool) {
+	i := n - ip.firstLine
+	if i < 0 || i >= len(ip.lines) {
+		return "", false
+	}
+	return ip.lines[i], true
+}
+
+// advance сохраняет строки разобранного ввода, отбрасывая самые старые сверх
+// maxSourceLines, и сдвигает позицию следующего ввода.
+func (ip *IncrementalParser) advance(input string) {
+	lines := strings.Split(input, "\n")
+	ip.lines = append(ip.lines, lines...)
+	ip.start.Line += len(lines)
+	ip.start.Offset += utf8.RuneCountInString(input) + 1
+
+	excess := min(len(ip.lines)-maxSourceLines, len(ip.lines)-len(lines))
+	if excess > 0 {
+		n := copy(ip.lines, ip.lines[excess:])
+		clear(ip.lines[n:])
+		ip.lines = ip.lines[:n]
+		ip.firstLine += excess
+	}
+}
+
+// parse разбирает ввод, начинающийся с позиции следующего ввода.
+func (ip *IncrementalParser) parse(input string) (*Program, error) {
+	tokens, err := ip.lexer.TokenizeAt(input, ip.start)
+	if err != nil {
+		return nil, fmt.Errorf("lexical analysis failed: %w", err)
+	}
+
+	program, err := ip.parser.ParseProgram(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("parsing failed: %w", err)
+	}
+	return program, nil
+}
+
+// Incomplete проверяет, ожидается ли продолжение ввода
+// (например, чтобы REPL вывел приглашение PS2 вместо PS1).
+func (ip *IncrementalParser) Incomplete() bool {
+	return ip.pending.Len() > 0
+}
+
+// Reset отбрасывает накопленный незавершенный ввод.
+func (ip *IncrementalParser) Reset() {
+	ip.pending.Reset()
+	ip.tokens = nil
+	ip.chunk = ""
+	ip.nesting = 0
+	ip.incomplete = nil
+}
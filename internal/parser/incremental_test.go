@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gocli/internal/lexer"
+)
+
+// TestIncrementalParser_Feed проверяет построчный разбор: незавершенный ввод
+// накапливается до полной программы, после чего накопление начинается заново.
+func TestIncrementalParser_Feed(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected string
+	}{
+		{"single line", []string{"echo a; echo b"}, "echo a; echo b"},
+		{"empty line", []string{""}, ""},
+		{"if", []string{"if true", "then", "  echo a", "fi"}, "if true; then echo a; fi"},
+		{"quoted string", []string{"echo 'a", "", "b'"}, "echo \"a\n\nb\""},
+		{"line continuation", []string{`echo a \`, "b"}, "echo a b"},
+		{"heredoc", []string{"cat <<EOF", "text", "EOF"}, "cat <<\"text\n\""},
+		{"function", []string{"f() {", "  echo a", "}"}, "f () { echo a; }"},
+		{"and", []string{"true &&", "", "  echo chained"}, "true && echo chained"},
+		{"or", []string{"false ||", "  echo fallback"}, "false || echo fallback"},
+		{"pipe", []string{"echo a |", "  wc"}, "echo a | wc"},
+		{"and inside if", []string{"if true; then", "  true &&", "  echo a", "fi"}, "if true; then true && echo a; fi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := NewIncrementalParser()
+			last := len(tt.lines) - 1
+			for _, line := range tt.lines[:last] {
+				if _, err := ip.Feed(line); !errors.Is(err, lexer.ErrIncomplete) {
+					t.Fatalf("IncrementalParser.Feed(%q) error = %v, expected ErrIncomplete", line, err)
+				}
+				if !ip.Incomplete() {
+					t.Fatalf("IncrementalParser.Incomplete() = false after %q", line)
+				}
+			}
+
+			program, err := ip.Feed(tt.lines[last])
+			if err != nil {
+				t.Fatalf("IncrementalParser.Feed(%q) error = %v", tt.lines[last], err)
+			}
+			if got := program.String(); got != tt.expected {
+				t.Errorf("IncrementalParser.Feed() = %q, expected %q", got, tt.expected)
+			}
+			if ip.Incomplete() {
+				t.Error("IncrementalParser.Incomplete() = true after complete input")
+			}
+		})
+	}
+}
+
+// TestIncrementalParser_FeedMatchesParse проверяет, что построчный разбор дает ту же
+// программу, что и разбор всего ввода сразу: лексер продолжает токены предыдущих строк,
+// а парсер запускается, только когда составные команды могут быть закрыты.
+func TestIncrementalParser_FeedMatchesParse(t *testing.T) {
+	programs := []string{
+		"main() {\n  if [[ $x == y ]]; then\n    echo a\n  fi\n  x=2\n}",
+		"f() {\n  case $x in\n    a=b) y=1;;\n    (c|d)\n      echo c;;\n  esac\n  y=2\n}",
+		"function f {\n  for i in 1 2; do\n    echo \"$i\n\"\n  done\n}",
+		"while true; do\n  cat <<EOF\nbody\nEOF\n  break\ndone",
+		"f() (\n  cd /\n)",
+		"if true\n# comment\n\nthen echo a; fi",
+	}
+
+	for _, input := range programs {
+		expected, err := NewIncrementalParser().Parse(input)
+		if err != nil {
+			t.Fatalf("IncrementalParser.Parse(%q) error = %v", input, err)
+		}
+
+		ip := NewIncrementalParser()
+		var program *Program
+		for _, line := range strings.Split(input, "\n") {
+			program, err = ip.Feed(line)
+			if err != nil && !errors.Is(err, lexer.ErrIncomplete) {
+				t.Fatalf("IncrementalParser.Feed(%q) error = %v", line, err)
+			}
+		}
+		if program == nil {
+			t.Fatalf("input %q is incomplete after the last line", input)
+		}
+		if program.String() != expected.String() {
+			t.Errorf("Feed() = %q, expected %q", program.String(), expected.String())
+		}
+		if program.Pos() != expected.Pos() {
+			t.Errorf("Feed() pos = %s, expected %s", program.Pos(), expected.Pos())
+		}
+	}
+}
+
+// TestIncrementalParser_Errors проверяет, что синтаксическая ошибка отбрасывает
+// накопленный ввод, как и Reset.
+func TestIncrementalParser_Errors(t *testing.T) {
+	ip := NewIncrementalParser()
+
+	if _, err := ip.Feed("if true; then"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Fatalf("IncrementalParser.Feed() error = %v, expected ErrIncomplete", err)
+	}
+	if _, err := ip.Feed("done"); err == nil || errors.Is(err, lexer.ErrIncomplete) {
+		t.Fatalf("IncrementalParser.Feed(%q) error = %v, expected syntax error", "done", err)
+	}
+	if ip.Incomplete() {
+		t.Error("IncrementalParser.Incomplete() = true after syntax error")
+	}
+
+	if _, err := ip.Feed("echo 'a"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Fatalf("IncrementalParser.Feed() error = %v, expected ErrIncomplete", err)
+	}
+	ip.Reset()
+	program, err := ip.Feed("echo b")
+	if err != nil {
+		t.Fatalf("IncrementalParser.Feed() error = %v", err)
+	}
+	if got := program.String(); got != "echo b" {
+		t.Errorf("IncrementalParser.Feed() after Reset = %q, expected %q", got, "echo b")
+	}
+
+	if _, err := ip.Parse("echo 'a"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Errorf("IncrementalParser.Parse() error = %v, expected ErrIncomplete", err)
+	}
+	if ip.Incomplete() {
+		t.Error("IncrementalParser.Parse() must not accumulate input")
+	}
+}
+
+// TestIncrementalParser_Positions проверяет сквозную нумерацию строк последовательного
+// ввода и сохранение разобранных строк для вывода диагностики.
+func TestIncrementalParser_Positions(t *testing.T) {
+	ip := NewIncrementalParser()
+
+	if _, err := ip.Feed("echo a"); err != nil {
+		t.Fatalf("IncrementalParser.Feed() error = %v", err)
+	}
+	if _, err := ip.Feed("if true"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Fatalf("IncrementalParser.Feed() error = %v, expected ErrIncomplete", err)
+	}
+	program, err := ip.Feed("then echo b; fi")
+	if err != nil {
+		t.Fatalf("IncrementalParser.Feed() error = %v", err)
+	}
+	if got := program.Pos().String(); got != "2:1" {
+		t.Errorf("Program.Pos() = %s, expected 2:1", got)
+	}
+
+	_, err = ip.Parse("echo c\necho d |")
+	var posErr *lexer.PosError
+	if !errors.As(err, &posErr) || posErr.Pos.String() != "5:8" {
+		t.Fatalf("IncrementalParser.Parse() error = %v, expected error at 5:8", err)
+	}
+
+	for n, expected := range []string{"echo a", "if true", "then echo b; fi", "echo c", "echo d |"} {
+		if line, ok := ip.SourceLine(n + 1); !ok || line != expected {
+			t.Errorf("IncrementalParser.SourceLine(%d) = %q, %v, expected %q", n+1, line, ok, expected)
+		}
+	}
+	if _, ok := ip.SourceLine(6); ok {
+		t.Error("IncrementalParser.SourceLine(6) should not exist")
+	}
+}
+
+// TestIncrementalParser_SourceLineLimit проверяет, что сохраняются только последние
+// maxSourceLines строк (и все строки последнего ввода), а нумерация строк остается сквозной.
+func TestIncrementalParser_SourceLineLimit(t *testing.T) {
+	ip := NewIncrementalParser()
+	for i := range maxSourceLines + 10 {
+		if _, err := ip.Feed(fmt.Sprintf("echo %d", i+1)); err != nil {
+			t.Fatalf("IncrementalParser.Feed() error = %v", err)
+		}
+	}
+
+	if _, ok := ip.SourceLine(10); ok {
+		t.Error("IncrementalParser.SourceLine(10) should be dropped")
+	}
+	if line, ok := ip.SourceLine(11); !ok || line != "echo 11" {
+		t.Errorf("IncrementalParser.SourceLine(11) = %q, %v, expected %q", line, ok, "echo 11")
+	}
+
+	_, err := ip.Feed("fi")
+	var posErr *lexer.PosError
+	if !errors.As(err, &posErr) || posErr.Pos.Line != maxSourceLines+11 {
+		t.Fatalf("IncrementalParser.Feed() error = %v, expected error at line %d", err, maxSourceLines+11)
+	}
+
+	// Длинный ввод сохраняется целиком, даже если он длиннее maxSourceLines
+	input := strings.Repeat("echo a\n", maxSourceLines) + "echo last"
+	if _, err := ip.Parse(input); err != nil {
+		t.Fatalf("IncrementalParser.Parse() error = %v", err)
+	}
+	first := maxSourceLines + 12
+	if line, ok := ip.SourceLine(first); !ok || line != "echo a" {
+		t.Errorf("IncrementalParser.SourceLine(%d) = %q, %v, expected %q", first, line, ok, "echo a")
+	}
+	if line, ok := ip.SourceLine(first + maxSourceLines); !ok || line != "echo last" {
+		t.Errorf("IncrementalParser.SourceLine(%d) = %q, %v, expected %q", first+maxSourceLines, line, ok, "echo last")
+	}
+	if _, ok := ip.SourceLine(first - 1); ok {
+		t.Errorf("IncrementalParser.SourceLine(%d) should be dropped", first-1)
+	}
+}
@@ -2,6 +2,10 @@ package parser
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"gocli/internal/lexer"
 )
@@ -17,55 +21,870 @@ func NewParser() *Parser {
 }
 
 // Parse выполняет синтаксический анализ последовательности токенов.
-// Строит AST, представляющий команды и пайплайны.
+// Строит AST, представляющий команды, пайплайны, списки команд и составные команды.
+// Одиночная команда или пайплайн без операторов ;, &, && и || возвращаются как есть,
+// иначе возвращается узел List. Если составная команда не завершена (нет fi),
+// возвращается ошибка, оборачивающая lexer.ErrIncomplete: REPL продолжает чтение строк.
 // Возвращает корневой узел AST и ошибку при некорректном синтаксисе.
 func (p *Parser) Parse(tokens []lexer.Token) (Node, error) {
 	if len(tokens) == 0 {
 		return nil, fmt.Errorf("empty command")
 	}
 
-	commands, err := p.parsePipeline(tokens)
+	program, err := p.ParseProgram(tokens)
 	if err != nil {
 		return nil, err
 	}
+	list := program.Body
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
 
-	if len(commands) == 1 {
-		return commands[0], nil
+	if len(list.Items) == 1 && len(list.Items[0].Pipelines) == 1 && !list.Items[0].Background {
+		return list.Items[0].Pipelines[0], nil
 	}
 
-	return &Pipeline{Commands: commands}, nil
+	return list, nil
 }
 
-func (p *Parser) parsePipeline(tokens []lexer.Token) ([]*Command, error) {
-	var commands []*Command
-	var currentTokens []lexer.Token
+// ParseProgram разбирает токены целого скрипта или многострочного ввода в узел Program.
+// В отличие от Parse, пустая последовательность токенов допустима и дает пустую программу,
+// а единственная команда не извлекается из списка. Незавершенный ввод, как и в Parse,
+// дает ошибку, оборачивающую lexer.ErrIncomplete.
+func (p *Parser) ParseProgram(tokens []lexer.Token) (*Program, error) {
+	state := &parseState{tokens: tokens}
+	list, err := p.parseList(state)
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := state.peek(); ok {
+		return nil, unexpectedToken(token)
+	}
+	return &Program{Body: list, Position: list.Position}, nil
+}
 
-	for _, token := range tokens {
-		if token.Type == lexer.PIPE {
-			if len(currentTokens) == 0 {
-				return nil, fmt.Errorf("empty command before pipe")
-			}
+// parseState хранит состояние синтаксического анализа: токены и позицию текущего токена.
+type parseState struct {
+	tokens []lexer.Token
+	pos    int
+}
 
-			command, err := p.parseCommand(currentTokens)
-			if err != nil {
+// peek возвращает текущий токен; false, если токены закончились.
+func (state *parseState) peek() (lexer.Token, bool) {
+	if state.pos >= len(state.tokens) {
+		return lexer.Token{}, false
+	}
+	return state.tokens[state.pos], true
+}
+
+// skipNewlines пропускает переводы строк, которые между командами не несут смысла.
+func (state *parseState) skipNewlines() {
+	for state.pos < len(state.tokens) && state.tokens[state.pos].Type == lexer.NEWLINE {
+		state.pos++
+	}
+}
+
+// atReserved проверяет, что текущий токен - одно из зарезервированных слов words.
+func (state *parseState) atReserved(words ...string) bool {
+	token, ok := state.peek()
+	return ok && token.IsReserved() && slices.Contains(words, token.Value)
+}
+
+// lastPos возвращает позицию последнего токена: к ней относятся ошибки
+// незавершенного ввода, обнаруженные в конце токенов.
+func (state *parseState) lastPos() lexer.Position {
+	if len(state.tokens) == 0 {
+		return lexer.Position{}
+	}
+	return state.tokens[len(state.tokens)-1].Pos
+}
+
+// syntaxError возвращает ошибку разбора *lexer.PosError с позицией pos.
+func syntaxError(pos lexer.Position, format string, args ...any) error {
+	return &lexer.PosError{Pos: pos, Err: fmt.Errorf(format, args...)}
+}
+
+// unexpectedToken возвращает синтаксическую ошибку для неожиданного токена.
+func unexpectedToken(token lexer.Token) error {
+	return syntaxError(token.Pos, "syntax error near unexpected token %q", token.Value)
+}
+
+// parseList разбирает элементы списка, разделенные ;, & и переводами строк.
+// Элемент, завершенный оператором &, помечается как фоновый.
+// Завершающий ; или & допускается (например, "echo a;" или "sleep 1 &").
+// Разбор останавливается в конце токенов, на зарезервированном слове из terminators
+// в начале команды (then, fi, ...), на завершении ветви case (;;), на ")" или на токене,
+// который не может продолжить список.
+func (p *Parser) parseList(state *parseState, terminators ...string) (*List, error) {
+	list := &List{}
+
+	for {
+		state.skipNewlines()
+		token, ok := state.peek()
+		if !ok || state.atReserved(terminators...) || token.Type == lexer.DSEMI || token.Type == lexer.RPAREN {
+			return list, nil
+		}
+		if token.Type == lexer.SEMICOLON || token.Type == lexer.BACKGROUND {
+			return nil, unexpectedToken(token)
+		}
+
+		item, err := p.parseAndOr(state)
+		if err != nil {
+			return nil, err
+		}
+		if len(list.Items) == 0 {
+			list.Position = item.Position
+		}
+		list.Items = append(list.Items, item)
+
+		token, ok = state.peek()
+		if !ok {
+			return list, nil
+		}
+		switch token.Type {
+		case lexer.SEMICOLON, lexer.NEWLINE:
+			state.pos++
+		case lexer.BACKGROUND:
+			item.Background = true
+			state.pos++
+		default:
+			return list, nil
+		}
+	}
+}
+
+// parseAndOr разбирает цепочку пайплайнов, соединенных операторами && и ||.
+// После оператора допускаются переводы строк, а оператор в конце ввода означает,
+// что команда продолжается на следующей строке.
+func (p *Parser) parseAndOr(state *parseState) (*AndOr, error) {
+	pipeline, err := p.parsePipeline(state)
+	if err != nil {
+		return nil, err
+	}
+	andOr := &AndOr{Pipelines: []Node{pipeline}, Position: pipeline.Pos()}
+
+	for {
+		token, ok := state.peek()
+		if !ok {
+			return andOr, nil
+		}
+
+		var op AndOrOperator
+		switch token.Type {
+		case lexer.AND:
+			op = AndOperator
+		case lexer.OR:
+			op = OrOperator
+		default:
+			return andOr, nil
+		}
+
+		state.pos++
+		state.skipNewlines()
+		if _, ok := state.peek(); !ok {
+			return nil, syntaxError(token.Pos, "%w: missing command after %q", lexer.ErrIncomplete, token.Value)
+		}
+
+		pipeline, err := p.parsePipeline(state)
+		if err != nil {
+			return nil, err
+		}
+		andOr.Pipelines = append(andOr.Pipelines, pipeline)
+		andOr.Operators = append(andOr.Operators, op)
+	}
+}
+
+// parsePipeline разбирает пайплайн и возвращает его как узел AST.
+// Пайплайн из одной команды возвращается как *Command или составная команда;
+// составные команды внутри пайплайна оборачиваются в Command.Compound.
+// После оператора | допускаются переводы строк; | в конце ввода означает,
+// что пайплайн продолжается на следующей строке.
+func (p *Parser) parsePipeline(state *parseState) (Node, error) {
+	if token, ok := state.peek(); ok && token.Type == lexer.PIPE {
+		return nil, syntaxError(token.Pos, "empty command before pipe")
+	}
+
+	first, err := p.parseCommandNode(state)
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := state.peek(); !ok || token.Type != lexer.PIPE {
+		return first, nil
+	}
+
+	commands := []*Command{asCommand(first)}
+	for {
+		token, ok := state.peek()
+		if !ok || token.Type != lexer.PIPE {
+			return &Pipeline{Commands: commands, Position: commands[0].Position}, nil
+		}
+		state.pos++
+		state.skipNewlines()
+
+		next, ok := state.peek()
+		if !ok {
+			return nil, syntaxError(token.Pos, "%w: empty command after pipe", lexer.ErrIncomplete)
+		}
+		if next.Type == lexer.PIPE {
+			return nil, syntaxError(next.Pos, "empty command before pipe")
+		}
+
+		command, err := p.parseCommandNode(state)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, asCommand(command))
+	}
+}
+
+// asCommand возвращает узел команды пайплайна: составная команда оборачивается в Command.
+func asCommand(node Node) *Command {
+	if command, ok := node.(*Command); ok {
+		return command
+	}
+	return &Command{Compound: node, Position: node.Pos()}
+}
+
+// parseCommandNode разбирает одну команду: составную, если она начинается
+// с зарезервированного слова или "(", определение функции (слово, за которым следует "(")
+// или простую команду - до ближайшего оператора.
+func (p *Parser) parseCommandNode(state *parseState) (Node, error) {
+	token, _ := state.peek()
+	if token.Type == lexer.LPAREN {
+		return p.parseCompound(state, p.parseSubshell)
+	}
+	if token.IsReserved() {
+		switch token.Value {
+		case "if":
+			return p.parseCompound(state, p.parseIf)
+		case "for":
+			return p.parseCompound(state, p.parseFor)
+		case "while", "until":
+			return p.parseCompound(state, p.parseWhile)
+		case "case":
+			return p.parseCompound(state, p.parseCase)
+		case "{":
+			return p.parseCompound(state, p.parseGroup)
+		case "[[":
+			return p.parseCompound(state, p.parseConditional)
+		case "function":
+			return p.parseFunction(state)
+		default:
+			return nil, unexpectedToken(token)
+		}
+	}
+	if next := state.pos + 1; token.Type == lexer.WORD && next < len(state.tokens) && state.tokens[next].Type == lexer.LPAREN {
+		return p.parseFunction(state)
+	}
+
+	start := state.pos
+	for ; state.pos < len(state.tokens); state.pos++ {
+		if isOperator(state.tokens[state.pos]) {
+			break
+		}
+	}
+	if start == state.pos {
+		return nil, unexpectedToken(token)
+	}
+
+	return p.parseCommand(state.tokens[start:state.pos])
+}
+
+// isOperator проверяет, завершает ли токен простую команду.
+func isOperator(token lexer.Token) bool {
+	switch token.Type {
+	case lexer.PIPE, lexer.SEMICOLON, lexer.AND, lexer.OR, lexer.BACKGROUND, lexer.NEWLINE,
+		lexer.LPAREN, lexer.RPAREN, lexer.DSEMI:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseCompound разбирает составную команду функцией parse и следующие за ней
+// перенаправления. Составная команда с перенаправлениями оборачивается в Command.
+func (p *Parser) parseCompound(state *parseState, parse func(*parseState) (Node, error)) (Node, error) {
+	node, err := parse(state)
+	if err != nil {
+		return nil, err
+	}
+
+	var redirects []*Redirect
+	for {
+		token, ok := state.peek()
+		if !ok || token.Type != lexer.REDIRECT {
+			break
+		}
+		redirect, skip, err := p.parseRedirect(state.tokens, state.pos)
+		if err != nil {
+			return nil, err
+		}
+		redirects = append(redirects, redirect)
+		state.pos += skip + 1
+	}
+
+	if len(redirects) == 0 {
+		return node, nil
+	}
+	return &Command{Compound: node, Args: []*Argument{}, Assignments: []*Assignment{}, Redirects: redirects, Position: node.Pos()}, nil
+}
+
+// parseIf разбирает условную конструкцию if ... then ... [elif ... then ...] [else ...] fi.
+// Текущий токен - зарезервированное слово if.
+func (p *Parser) parseIf(state *parseState) (Node, error) {
+	node := &If{Position: state.tokens[state.pos].Pos}
+	state.pos++
+
+	for {
+		condition, err := p.parseCompoundList(state, "then")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectReserved(state, "then"); err != nil {
+			return nil, err
+		}
+		body, err := p.parseCompoundList(state, "elif", "else", "fi")
+		if err != nil {
+			return nil, err
+		}
+		node.Clauses = append(node.Clauses, &IfClause{Condition: condition, Body: body})
+
+		word, err := p.expectReserved(state, "elif", "else", "fi")
+		if err != nil {
+			return nil, err
+		}
+		switch word {
+		case "fi":
+			return node, nil
+		case "else":
+			if node.Else, err = p.parseCompoundList(state, "fi"); err != nil {
+				return nil, err
+			}
+			if _, err := p.expectReserved(state, "fi"); err != nil {
 				return nil, err
 			}
-			commands = append(commands, command)
-			currentTokens = nil
-		} else {
-			currentTokens = append(currentTokens, token)
+			return node, nil
+		}
+	}
+}
+
+// parseFor разбирает цикл for name [in words]; do ... done
+// или арифметический цикл for ((init; cond; update)); do ... done.
+// Текущий токен - зарезервированное слово for.
+func (p *Parser) parseFor(state *parseState) (Node, error) {
+	start := state.tokens[state.pos].Pos
+	state.pos++
+	token, ok := state.peek()
+	if !ok {
+		return nil, syntaxError(state.lastPos(), "%w: loop variable expected", lexer.ErrIncomplete)
+	}
+	if token.Type == lexer.ARITH {
+		return p.parseArithFor(state, start)
+	}
+	if token.Type != lexer.WORD || len(token.Parts) > 0 || !isName(token.Value) {
+		return nil, syntaxError(token.Pos, "syntax error: %q is not a valid identifier", token.Value)
+	}
+	state.pos++
+	node := &For{Variable: token.Value, Position: start}
+
+	state.skipNewlines()
+	if state.atReserved("in") {
+		state.pos++
+		node.Words = []*Argument{}
+		for {
+			token, ok := state.peek()
+			if !ok {
+				return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, "do")
+			}
+			if token.Type == lexer.SEMICOLON || token.Type == lexer.NEWLINE {
+				state.pos++
+				break
+			}
+			if !isWordToken(token) {
+				return nil, unexpectedToken(token)
+			}
+			node.Words = append(node.Words, p.createArgument(token))
+			state.pos++
 		}
+	} else if token, ok := state.peek(); ok && token.Type == lexer.SEMICOLON {
+		state.pos++
+	}
+
+	body, err := p.parseDoGroup(state)
+	if err != nil {
+		return nil, err
+	}
+	node.Body = body
+	return node, nil
+}
+
+// parseArithFor разбирает арифметический цикл; текущий токен - заголовок ARITH,
+// start - позиция слова for. Заголовок должен состоять из трех выражений,
+// разделенных точкой с запятой.
+func (p *Parser) parseArithFor(state *parseState, start lexer.Position) (Node, error) {
+	header := strings.Split(state.tokens[state.pos].Value, ";")
+	if len(header) != 3 {
+		return nil, syntaxError(state.tokens[state.pos].Pos, "syntax error: arithmetic for requires three expressions")
+	}
+	state.pos++
+	if token, ok := state.peek(); ok && token.Type == lexer.SEMICOLON {
+		state.pos++
+	}
+
+	body, err := p.parseDoGroup(state)
+	if err != nil {
+		return nil, err
 	}
+	return &ArithFor{
+		Init:     strings.TrimSpace(header[0]),
+		Cond:     strings.TrimSpace(header[1]),
+		Update:   strings.TrimSpace(header[2]),
+		Body:     body,
+		Position: start,
+	}, nil
+}
 
-	if len(currentTokens) > 0 {
-		command, err := p.parseCommand(currentTokens)
+// parseWhile разбирает цикл while ... do ... done или until ... do ... done.
+// Текущий токен - зарезервированное слово while или until.
+func (p *Parser) parseWhile(state *parseState) (Node, error) {
+	keyword := state.tokens[state.pos]
+	until := keyword.Value == "until"
+	state.pos++
+
+	condition, err := p.parseCompoundList(state, "do")
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.parseDoGroup(state)
+	if err != nil {
+		return nil, err
+	}
+	return &While{Condition: condition, Body: body, Until: until, Position: keyword.Pos}, nil
+}
+
+// parseCase разбирает конструкцию case word in [(]pattern[|pattern...]) list ;; ... esac.
+// Ветви и шаблоны могут располагаться на отдельных строках; оператор завершения
+// последней ветви перед esac необязателен. Текущий токен - зарезервированное слово case.
+func (p *Parser) parseCase(state *parseState) (Node, error) {
+	start := state.tokens[state.pos].Pos
+	state.pos++
+	token, ok := state.peek()
+	if !ok {
+		return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, "in")
+	}
+	if !isWordToken(token) {
+		return nil, unexpectedToken(token)
+	}
+	state.pos++
+	node := &Case{Word: p.createArgument(token), Position: start}
+
+	state.skipNewlines()
+	if _, err := p.expectReserved(state, "in"); err != nil {
+		return nil, err
+	}
+
+	for {
+		state.skipNewlines()
+		if _, ok := state.peek(); !ok {
+			return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, "esac")
+		}
+		if state.atReserved("esac") {
+			state.pos++
+			return node, nil
+		}
+
+		item, err := p.parseCaseItem(state)
 		if err != nil {
 			return nil, err
 		}
-		commands = append(commands, command)
+		node.Items = append(node.Items, item)
+	}
+}
+
+// parseCaseItem разбирает ветвь case: шаблоны до ), тело и оператор завершения.
+func (p *Parser) parseCaseItem(state *parseState) (*CaseItem, error) {
+	if token, _ := state.peek(); token.Type == lexer.LPAREN {
+		state.pos++
+	}
+
+	item := &CaseItem{}
+	for {
+		token, ok := state.peek()
+		if !ok {
+			return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, ")")
+		}
+		if !isWordToken(token) {
+			return nil, unexpectedToken(token)
+		}
+		item.Patterns = append(item.Patterns, p.createArgument(token))
+		state.pos++
+
+		token, ok = state.peek()
+		if !ok {
+			return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, ")")
+		}
+		state.pos++
+		if token.Type == lexer.RPAREN {
+			break
+		}
+		if token.Type != lexer.PIPE {
+			return nil, unexpectedToken(token)
+		}
 	}
 
-	return commands, nil
+	body, err := p.parseList(state, "esac")
+	if err != nil {
+		return nil, err
+	}
+	item.Body = body
+
+	token, ok := state.peek()
+	switch {
+	case !ok:
+		return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, "esac")
+	case token.Type == lexer.DSEMI:
+		state.pos++
+		switch token.Value {
+		case ";&":
+			item.Terminator = CaseFallThrough
+		case ";;&":
+			item.Terminator = CaseContinue
+		}
+	case !state.atReserved("esac"):
+		return nil, unexpectedToken(token)
+	}
+	return item, nil
+}
+
+// parseGroup разбирает группу команд { list; }.
+// Текущий токен - зарезервированное слово {.
+func (p *Parser) parseGroup(state *parseState) (Node, error) {
+	start := state.tokens[state.pos].Pos
+	state.pos++
+	body, err := p.parseCompoundList(state, "}")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expectReserved(state, "}"); err != nil {
+		return nil, err
+	}
+	return &Group{Body: body, Position: start}, nil
+}
+
+// parseSubshell разбирает подоболочку ( list ). Текущий токен - "(".
+// Список может занимать несколько строк.
+func (p *Parser) parseSubshell(state *parseState) (Node, error) {
+	start := state.tokens[state.pos].Pos
+	state.pos++
+	body, err := p.parseList(state)
+	if err != nil {
+		return nil, err
+	}
+
+	token, ok := state.peek()
+	if !ok {
+		return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, ")")
+	}
+	if token.Type != lexer.RPAREN || len(body.Items) == 0 {
+		return nil, unexpectedToken(token)
+	}
+	state.pos++
+	return &Subshell{Body: body, Position: start}, nil
+}
+
+// conditionalBinaryOperators - бинарные операторы выражения [[ ]], записываемые словами;
+// операторы < и > лексер возвращает токенами перенаправления.
+var conditionalBinaryOperators = map[string]bool{
+	"=": true, "==": true, "!=": true, "=~": true,
+	"-eq": true, "-ne": true, "-lt": true, "-le": true, "-gt": true, "-ge": true,
+	"-nt": true, "-ot": true, "-ef": true,
+}
+
+// parseConditional разбирает условное выражение [[ expr ]]. Текущий токен - [[.
+// Приоритеты операторов: ! выше &&, && выше ||; после && и || допускаются переводы строк.
+func (p *Parser) parseConditional(state *parseState) (Node, error) {
+	start := state.tokens[state.pos].Pos
+	state.pos++
+	expr, err := p.parseCondOr(state)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expectReserved(state, "]]"); err != nil {
+		return nil, err
+	}
+	return &Conditional{Expr: expr, Position: start}, nil
+}
+
+// parseCondOr разбирает выражения [[ ]], соединенные ||.
+func (p *Parser) parseCondOr(state *parseState) (CondExpr, error) {
+	left, err := p.parseCondAnd(state)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := state.peek()
+		if !ok || token.Type != lexer.OR {
+			return left, nil
+		}
+		state.pos++
+		state.skipNewlines()
+		right, err := p.parseCondAnd(state)
+		if err != nil {
+			return nil, err
+		}
+		left = &CondAndOr{Op: OrOperator, Left: left, Right: right}
+	}
+}
+
+// parseCondAnd разбирает выражения [[ ]], соединенные &&.
+func (p *Parser) parseCondAnd(state *parseState) (CondExpr, error) {
+	left, err := p.parseCondNot(state)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := state.peek()
+		if !ok || token.Type != lexer.AND {
+			return left, nil
+		}
+		state.pos++
+		state.skipNewlines()
+		right, err := p.parseCondNot(state)
+		if err != nil {
+			return nil, err
+		}
+		left = &CondAndOr{Op: AndOperator, Left: left, Right: right}
+	}
+}
+
+// parseCondNot разбирает отрицание ! expr.
+func (p *Parser) parseCondNot(state *parseState) (CondExpr, error) {
+	if token, ok := state.peek(); ok && isCondWord(token, "!") {
+		state.pos++
+		expr, err := p.parseCondNot(state)
+		if err != nil {
+			return nil, err
+		}
+		return &CondNot{Expr: expr}, nil
+	}
+	return p.parseCondPrimary(state)
+}
+
+// parseCondPrimary разбирает простое выражение [[ ]]: выражение в скобках,
+// бинарную проверку left op right, унарную проверку -op operand или одиночный операнд.
+func (p *Parser) parseCondPrimary(state *parseState) (CondExpr, error) {
+	token, ok := state.peek()
+	if !ok {
+		return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, "]]")
+	}
+
+	if token.Type == lexer.LPAREN {
+		state.pos++
+		expr, err := p.parseCondOr(state)
+		if err != nil {
+			return nil, err
+		}
+		token, ok := state.peek()
+		if !ok {
+			return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, ")")
+		}
+		if token.Type != lexer.RPAREN {
+			return nil, unexpectedToken(token)
+		}
+		state.pos++
+		return &CondGroup{Expr: expr}, nil
+	}
+
+	if !isCondOperand(token) {
+		return nil, unexpectedToken(token)
+	}
+	state.pos++
+
+	if op, ok := p.condBinaryOperator(state); ok {
+		state.pos++
+		right, ok := state.peek()
+		if !ok {
+			return nil, syntaxError(state.lastPos(), "%w: operand expected after %s", lexer.ErrIncomplete, op)
+		}
+		if !isCondOperand(right) {
+			return nil, unexpectedToken(right)
+		}
+		state.pos++
+		return &CondBinary{Op: op, Left: p.createArgument(token), Right: p.createArgument(right)}, nil
+	}
+
+	if isCondUnaryOperator(token) {
+		if operand, ok := state.peek(); ok && isCondOperand(operand) {
+			state.pos++
+			return &CondUnary{Op: token.Value, Operand: p.createArgument(operand)}, nil
+		}
+	}
+	return &CondWord{Word: p.createArgument(token)}, nil
+}
+
+// condBinaryOperator возвращает бинарный оператор [[ ]] в текущей позиции, если он там есть.
+func (p *Parser) condBinaryOperator(state *parseState) (string, bool) {
+	token, ok := state.peek()
+	switch {
+	case !ok:
+		return "", false
+	case token.Type == lexer.REDIRECT:
+		return token.Value, token.Value == "<" || token.Value == ">"
+	case token.Type == lexer.WORD && len(token.Parts) == 0:
+		return token.Value, conditionalBinaryOperators[token.Value]
+	default:
+		return "", false
+	}
+}
+
+// isCondOperand проверяет, что токен может быть операндом [[ ]]: слово, кроме закрывающего ]].
+func isCondOperand(token lexer.Token) bool {
+	return isWordToken(token) && !(token.IsReserved() && token.Value == "]]")
+}
+
+// isCondWord проверяет, что токен - слово value без кавычек (оператор вроде !).
+func isCondWord(token lexer.Token, value string) bool {
+	return token.Type == lexer.WORD && len(token.Parts) == 0 && token.Value == value
+}
+
+// isCondUnaryOperator проверяет, что токен похож на унарный оператор [[ ]]: -буква без кавычек.
+// Допустимость оператора проверяется при вычислении выражения.
+func isCondUnaryOperator(token lexer.Token) bool {
+	return token.Type == lexer.WORD && len(token.Parts) == 0 && len(token.Value) == 2 &&
+		token.Value[0] == '-' && unicode.IsLetter(rune(token.Value[1]))
+}
+
+// parseFunction разбирает определение функции name() body или function name [()] body.
+// Тело - составная команда (обычно группа { ...; }), которая может начинаться
+// на следующей строке; перенаправления после тела применяются при каждом вызове.
+func (p *Parser) parseFunction(state *parseState) (Node, error) {
+	start := state.tokens[state.pos].Pos
+	if state.atReserved("function") {
+		state.pos++
+	}
+	token, ok := state.peek()
+	if !ok {
+		return nil, syntaxError(state.lastPos(), "%w: function name expected", lexer.ErrIncomplete)
+	}
+	if !isFunctionName(token) {
+		return nil, syntaxError(token.Pos, "syntax error: %q is not a valid function name", token.Value)
+	}
+	state.pos++
+	node := &Function{Name: token.Value, Position: start}
+
+	if token, ok := state.peek(); ok && token.Type == lexer.LPAREN {
+		state.pos++
+		token, ok := state.peek()
+		if !ok {
+			return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, ")")
+		}
+		if token.Type != lexer.RPAREN {
+			return nil, unexpectedToken(token)
+		}
+		state.pos++
+	}
+
+	state.skipNewlines()
+	token, ok = state.peek()
+	if !ok {
+		return nil, syntaxError(state.lastPos(), "%w: function body expected", lexer.ErrIncomplete)
+	}
+	if !isCompoundStart(token) {
+		return nil, unexpectedToken(token)
+	}
+	body, err := p.parseCommandNode(state)
+	if err != nil {
+		return nil, err
+	}
+	node.Body = body
+	return node, nil
+}
+
+// isFunctionName проверяет, что токен может быть именем функции:
+// слово без кавычек и подстановок, не являющееся зарезервированным.
+func isFunctionName(token lexer.Token) bool {
+	return token.Type == lexer.WORD && len(token.Parts) == 0 && !token.IsReserved() &&
+		token.Value != "" && !strings.ContainsAny(token.Value, "$`\\=")
+}
+
+// isCompoundStart проверяет, что с токена начинается составная команда.
+func isCompoundStart(token lexer.Token) bool {
+	if token.Type == lexer.LPAREN {
+		return true
+	}
+	if !token.IsReserved() {
+		return false
+	}
+	switch token.Value {
+	case "{", "[[", "if", "for", "while", "until", "case":
+		return true
+	default:
+		return false
+	}
+}
+
+// isWordToken проверяет, что токен - слово (без кавычек или в кавычках).
+func isWordToken(token lexer.Token) bool {
+	return token.Type == lexer.WORD || token.Type == lexer.SQUOTE || token.Type == lexer.DQUOTE
+}
+
+// parseDoGroup разбирает тело цикла do ... done.
+func (p *Parser) parseDoGroup(state *parseState) (*List, error) {
+	state.skipNewlines()
+	if _, err := p.expectReserved(state, "do"); err != nil {
+		return nil, err
+	}
+	body, err := p.parseCompoundList(state, "done")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expectReserved(state, "done"); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// isName проверяет, что строка - корректное имя переменной:
+// буквы, цифры и подчеркивания, начиная с буквы или подчеркивания.
+func isName(s string) bool {
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// parseCompoundList разбирает непустой список команд внутри составной команды
+// до одного из зарезервированных слов terminators.
+func (p *Parser) parseCompoundList(state *parseState, terminators ...string) (*List, error) {
+	list, err := p.parseList(state, terminators...)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		token, ok := state.peek()
+		if !ok {
+			return nil, syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, terminators[len(terminators)-1])
+		}
+		return nil, unexpectedToken(token)
+	}
+	return list, nil
+}
+
+// expectReserved проверяет, что текущий токен - одно из зарезервированных слов words,
+// и переходит к следующему токену. Если токены закончились, возвращает ошибку
+// с lexer.ErrIncomplete: конструкция может продолжиться на следующей строке.
+func (p *Parser) expectReserved(state *parseState, words ...string) (string, error) {
+	token, ok := state.peek()
+	if !ok {
+		return "", syntaxError(state.lastPos(), "%w: %q expected", lexer.ErrIncomplete, words[len(words)-1])
+	}
+	if !state.atReserved(words...) {
+		return "", unexpectedToken(token)
+	}
+	state.pos++
+	return token.Value, nil
 }
 
 func (p *Parser) parseCommand(tokens []lexer.Token) (*Command, error) {
@@ -76,19 +895,29 @@ func (p *Parser) parseCommand(tokens []lexer.Token) (*Command, error) {
 	command := &Command{
 		Args:        []*Argument{},
 		Assignments: []*Assignment{},
+		Redirects:   []*Redirect{},
+		Position:    tokens[0].Pos,
 	}
 
 	for i := 0; i < len(tokens); i++ {
 		token := tokens[i]
 
-		if token.Type == lexer.ASSIGN {
+		switch token.Type {
+		case lexer.ASSIGN:
 			assignment, skip, err := p.parseAssignment(tokens, i)
 			if err != nil {
 				return nil, err
 			}
 			command.Assignments = append(command.Assignments, assignment)
 			i += skip
-		} else {
+		case lexer.REDIRECT:
+			redirect, skip, err := p.parseRedirect(tokens, i)
+			if err != nil {
+				return nil, err
+			}
+			command.Redirects = append(command.Redirects, redirect)
+			i += skip
+		default:
 			if err := p.addTokenToCommand(command, token); err != nil {
 				return nil, err
 			}
@@ -96,9 +925,10 @@ func (p *Parser) parseCommand(tokens []lexer.Token) (*Command, error) {
 	}
 
 	// Команда может состоять только из assignments (например, x=5)
+	// или перенаправлений (например, > file),
 	// или должна иметь имя команды (например, x=5 echo hello)
-	if command.Name == "" && len(command.Assignments) == 0 {
-		return nil, fmt.Errorf("command name or assignment is required")
+	if command.NameArg == nil && len(command.Assignments) == 0 && len(command.Redirects) == 0 {
+		return nil, syntaxError(command.Position, "command name or assignment is required")
 	}
 
 	return command, nil
@@ -108,25 +938,101 @@ func (p *Parser) parseCommand(tokens []lexer.Token) (*Command, error) {
 // Возвращает созданное присваивание, количество пропущенных токенов и ошибку.
 func (p *Parser) parseAssignment(tokens []lexer.Token, i int) (*Assignment, int, error) {
 	if i+1 >= len(tokens) {
-		return nil, 0, fmt.Errorf("assignment without value")
+		return nil, 0, syntaxError(tokens[i].Pos, "assignment without value")
 	}
 
 	nextToken := tokens[i+1]
 	if nextToken.Type != lexer.WORD && nextToken.Type != lexer.SQUOTE && nextToken.Type != lexer.DQUOTE {
-		return nil, 0, fmt.Errorf("invalid assignment value")
+		return nil, 0, syntaxError(nextToken.Pos, "invalid assignment value")
 	}
 
 	assignment := &Assignment{
-		Name:  tokens[i].Value,
-		Value: p.createArgument(nextToken),
+		Name:     tokens[i].Value,
+		Value:    p.createArgument(nextToken),
+		Position: tokens[i].Pos,
 	}
 	return assignment, 1, nil
 }
 
+// parseRedirect обрабатывает перенаправление ввода-вывода из токенов.
+// За оператором перенаправления обязательно должно следовать слово (имя файла
+// или номер дескриптора). Возвращает созданное перенаправление,
+// количество пропущенных токенов и ошибку.
+func (p *Parser) parseRedirect(tokens []lexer.Token, i int) (*Redirect, int, error) {
+	fd, op, err := p.parseRedirectOperator(tokens[i].Value)
+	if err != nil {
+		return nil, 0, &lexer.PosError{Pos: tokens[i].Pos, Err: err}
+	}
+
+	if i+1 >= len(tokens) {
+		return nil, 0, syntaxError(tokens[i].Pos, "missing redirection target after %s", tokens[i].Value)
+	}
+
+	nextToken := tokens[i+1]
+	if nextToken.Type != lexer.WORD && nextToken.Type != lexer.SQUOTE && nextToken.Type != lexer.DQUOTE {
+		return nil, 0, syntaxError(nextToken.Pos, "invalid redirection target after %s", tokens[i].Value)
+	}
+
+	redirect := &Redirect{
+		Fd:       fd,
+		Op:       op,
+		Target:   p.createArgument(nextToken),
+		Position: tokens[i].Pos,
+	}
+	return redirect, 1, nil
+}
+
+// parseRedirectOperator разбирает текст оператора перенаправления (например, "2>>")
+// на номер файлового дескриптора и вид перенаправления.
+func (p *Parser) parseRedirectOperator(value string) (int, RedirectOp, error) {
+	opStart := strings.IndexAny(value, "<>&")
+	if opStart == -1 {
+		return 0, 0, fmt.Errorf("invalid redirection operator %q", value)
+	}
+
+	var op RedirectOp
+	switch value[opStart:] {
+	case ">", ">|":
+		op = RedirectOutput
+	case ">>":
+		op = RedirectAppend
+	case "<":
+		op = RedirectInput
+	case ">&":
+		op = RedirectDupOutput
+	case "<&":
+		op = RedirectDupInput
+	case "&>":
+		op = RedirectAll
+	case "&>>":
+		op = RedirectAppendAll
+	case "<<", "<<-":
+		// Табуляции для <<- удаляются лексером при чтении тела документа
+		op = RedirectHeredoc
+	case "<<<":
+		op = RedirectHereString
+	default:
+		return 0, 0, fmt.Errorf("invalid redirection operator %q", value)
+	}
+
+	fd := op.DefaultFd()
+	if opStart > 0 {
+		n, err := strconv.Atoi(value[:opStart])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid file descriptor in %q", value)
+		}
+		fd = n
+	}
+
+	return fd, op, nil
+}
+
 // addTokenToCommand добавляет токен к команде (как имя команды или аргумент).
+// Первое слово становится именем команды; NameArg сохраняет его кавычки для expander'а.
 func (p *Parser) addTokenToCommand(command *Command, token lexer.Token) error {
-	if command.Name == "" {
+	if command.NameArg == nil {
 		command.Name = token.Value
+		command.NameArg = p.createArgument(token)
 	} else {
 		arg := p.createArgument(token)
 		command.Args = append(command.Args, arg)
@@ -134,24 +1040,32 @@ func (p *Parser) addTokenToCommand(command *Command, token lexer.Token) error {
 	return nil
 }
 
+// createArgument создает аргумент из токена слова, сохраняя части составного слова.
 func (p *Parser) createArgument(token lexer.Token) *Argument {
-	var quoteType QuoteType
-	quoted := false
+	quoteType := tokenQuoteType(token.Type)
 
-	switch token.Type {
-	case lexer.SQUOTE:
-		quoted = true
-		quoteType = SingleQuote
-	case lexer.DQUOTE:
-		quoted = true
-		quoteType = DoubleQuote
-	default:
-		quoteType = NoQuote
+	var parts []WordPart
+	for _, part := range token.Parts {
+		parts = append(parts, WordPart{Value: part.Value, QuoteType: tokenQuoteType(part.Type)})
 	}
 
 	return &Argument{
 		Value:     token.Value,
-		Quoted:    quoted,
+		Quoted:    quoteType != NoQuote,
 		QuoteType: quoteType,
+		Parts:     parts,
+		Position:  token.Pos,
+	}
+}
+
+// tokenQuoteType возвращает тип кавычек, соответствующий типу токена или части слова.
+func tokenQuoteType(tokenType lexer.TokenType) QuoteType {
+	switch tokenType {
+	case lexer.SQUOTE:
+		return SingleQuote
+	case lexer.DQUOTE:
+		return DoubleQuote
+	default:
+		return NoQuote
 	}
 }
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
 	"gocli/internal/lexer"
@@ -161,6 +163,221 @@ func TestParser_Parse(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "command with output redirection",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.WORD, Value: "hello"},
+				{Type: lexer.REDIRECT, Value: ">"},
+				{Type: lexer.WORD, Value: "out.txt"},
+			},
+			expected: &Command{
+				Name:      "echo",
+				Args:      []*Argument{{Value: "hello", Quoted: false}},
+				Redirects: []*Redirect{{Fd: 1, Op: RedirectOutput, Target: &Argument{Value: "out.txt"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "redirection before command name",
+			tokens: []lexer.Token{
+				{Type: lexer.REDIRECT, Value: "<"},
+				{Type: lexer.WORD, Value: "in.txt"},
+				{Type: lexer.WORD, Value: "cat"},
+			},
+			expected: &Command{
+				Name:      "cat",
+				Args:      []*Argument{},
+				Redirects: []*Redirect{{Fd: 0, Op: RedirectInput, Target: &Argument{Value: "in.txt"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "stderr append and duplication",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "cmd"},
+				{Type: lexer.REDIRECT, Value: "2>>"},
+				{Type: lexer.WORD, Value: "err.log"},
+				{Type: lexer.REDIRECT, Value: ">&"},
+				{Type: lexer.WORD, Value: "2"},
+			},
+			expected: &Command{
+				Name: "cmd",
+				Args: []*Argument{},
+				Redirects: []*Redirect{
+					{Fd: 2, Op: RedirectAppend, Target: &Argument{Value: "err.log"}},
+					{Fd: 1, Op: RedirectDupOutput, Target: &Argument{Value: "2"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "redirection only",
+			tokens: []lexer.Token{
+				{Type: lexer.REDIRECT, Value: "&>"},
+				{Type: lexer.DQUOTE, Value: "all.log"},
+			},
+			expected: &Command{
+				Redirects: []*Redirect{{Fd: 1, Op: RedirectAll, Target: &Argument{Value: "all.log", Quoted: true}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "here-document and here-string",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "cat"},
+				{Type: lexer.REDIRECT, Value: "<<-"},
+				{Type: lexer.SQUOTE, Value: "body\n"},
+				{Type: lexer.REDIRECT, Value: "<<<"},
+				{Type: lexer.WORD, Value: "word"},
+			},
+			expected: &Command{
+				Name: "cat",
+				Args: []*Argument{},
+				Redirects: []*Redirect{
+					{Fd: 0, Op: RedirectHeredoc, Target: &Argument{Value: "body\n", Quoted: true}},
+					{Fd: 0, Op: RedirectHereString, Target: &Argument{Value: "word"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "redirection without target",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.REDIRECT, Value: ">"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "redirection followed by pipe",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.REDIRECT, Value: ">"},
+				{Type: lexer.PIPE, Value: "|"},
+				{Type: lexer.WORD, Value: "wc"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "list with semicolon",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.WORD, Value: "a"},
+				{Type: lexer.SEMICOLON, Value: ";"},
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.WORD, Value: "b"},
+				{Type: lexer.SEMICOLON, Value: ";"},
+			},
+			expected: &List{Items: []*AndOr{
+				{Pipelines: []Node{&Command{Name: "echo", Args: []*Argument{{Value: "a"}}}}},
+				{Pipelines: []Node{&Command{Name: "echo", Args: []*Argument{{Value: "b"}}}}},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "and-or chain with pipeline",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "make"},
+				{Type: lexer.AND, Value: "&&"},
+				{Type: lexer.WORD, Value: "cat"},
+				{Type: lexer.WORD, Value: "log"},
+				{Type: lexer.PIPE, Value: "|"},
+				{Type: lexer.WORD, Value: "wc"},
+				{Type: lexer.OR, Value: "||"},
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.WORD, Value: "failed"},
+			},
+			expected: &List{Items: []*AndOr{
+				{
+					Pipelines: []Node{
+						&Command{Name: "make", Args: []*Argument{}},
+						&Pipeline{Commands: []*Command{
+							{Name: "cat", Args: []*Argument{{Value: "log"}}},
+							{Name: "wc", Args: []*Argument{}},
+						}},
+						&Command{Name: "echo", Args: []*Argument{{Value: "failed"}}},
+					},
+					Operators: []AndOrOperator{AndOperator, OrOperator},
+				},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "leading semicolon",
+			tokens: []lexer.Token{
+				{Type: lexer.SEMICOLON, Value: ";"},
+				{Type: lexer.WORD, Value: "echo"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "missing command after and",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.AND, Value: "&&"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "or right after semicolon",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.SEMICOLON, Value: ";"},
+				{Type: lexer.OR, Value: "||"},
+				{Type: lexer.WORD, Value: "echo"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "background command",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "sleep"},
+				{Type: lexer.WORD, Value: "10"},
+				{Type: lexer.BACKGROUND, Value: "&"},
+			},
+			expected: &List{Items: []*AndOr{
+				{Pipelines: []Node{&Command{Name: "sleep", Args: []*Argument{{Value: "10"}}}}, Background: true},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "background item followed by command",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "sleep"},
+				{Type: lexer.WORD, Value: "10"},
+				{Type: lexer.BACKGROUND, Value: "&"},
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.WORD, Value: "started"},
+			},
+			expected: &List{Items: []*AndOr{
+				{Pipelines: []Node{&Command{Name: "sleep", Args: []*Argument{{Value: "10"}}}}, Background: true},
+				{Pipelines: []Node{&Command{Name: "echo", Args: []*Argument{{Value: "started"}}}}},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "lone ampersand",
+			tokens: []lexer.Token{
+				{Type: lexer.BACKGROUND, Value: "&"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name: "empty command after pipe",
+			tokens: []lexer.Token{
+				{Type: lexer.WORD, Value: "echo"},
+				{Type: lexer.PIPE, Value: "|"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
 	}
 
 	parser := NewParser()
@@ -200,11 +417,47 @@ func compareNodes(a, b Node) bool {
 		if bPipeline, ok := b.(*Pipeline); ok {
 			return comparePipelines(aCmd, bPipeline)
 		}
+	case *List:
+		if bList, ok := b.(*List); ok {
+			return compareLists(aCmd, bList)
+		}
 	}
 
 	return false
 }
 
+func compareLists(a, b *List) bool {
+	if len(a.Items) != len(b.Items) {
+		return false
+	}
+	for i, item := range a.Items {
+		if !compareAndOrs(item, b.Items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func compareAndOrs(a, b *AndOr) bool {
+	if len(a.Pipelines) != len(b.Pipelines) || len(a.Operators) != len(b.Operators) {
+		return false
+	}
+	if a.Background != b.Background {
+		return false
+	}
+	for i, op := range a.Operators {
+		if op != b.Operators[i] {
+			return false
+		}
+	}
+	for i, pipeline := range a.Pipelines {
+		if !compareNodes(pipeline, b.Pipelines[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func compareCommands(a, b *Command) bool {
 	if a.Name != b.Name {
 		return false
@@ -225,6 +478,14 @@ func compareCommands(a, b *Command) bool {
 			return false
 		}
 	}
+	if len(a.Redirects) != len(b.Redirects) {
+		return false
+	}
+	for i, redirect := range a.Redirects {
+		if !compareRedirects(redirect, b.Redirects[i]) {
+			return false
+		}
+	}
 	return true
 }
 
@@ -236,6 +497,10 @@ func compareAssignments(a, b *Assignment) bool {
 	return a.Name == b.Name && compareArguments(a.Value, b.Value)
 }
 
+func compareRedirects(a, b *Redirect) bool {
+	return a.Fd == b.Fd && a.Op == b.Op && compareArguments(a.Target, b.Target)
+}
+
 func comparePipelines(a, b *Pipeline) bool {
 	if len(a.Commands) != len(b.Commands) {
 		return false
@@ -247,3 +512,576 @@ func comparePipelines(a, b *Pipeline) bool {
 	}
 	return true
 }
+
+// TestParser_ParseCompositeWords проверяет, что части составного слова
+// сохраняются в аргументе, а имя команды - в NameArg вместе с кавычками.
+func TestParser_ParseCompositeWords(t *testing.T) {
+	parts := []lexer.WordPart{
+		{Type: lexer.WORD, Value: "foo"},
+		{Type: lexer.DQUOTE, Value: "bar"},
+		{Type: lexer.SQUOTE, Value: "baz"},
+	}
+	tokens := []lexer.Token{
+		{Type: lexer.DQUOTE, Value: "echo"},
+		{Type: lexer.WORD, Value: "foobarbaz", Parts: parts},
+	}
+
+	node, err := NewParser().Parse(tokens)
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	cmd, ok := node.(*Command)
+	if !ok {
+		t.Fatalf("Parser.Parse() = %T, expected *Command", node)
+	}
+
+	if cmd.Name != "echo" || cmd.NameArg == nil || cmd.NameArg.QuoteType != DoubleQuote {
+		t.Errorf("command name = %q (%+v), expected double-quoted echo", cmd.Name, cmd.NameArg)
+	}
+
+	expected := []WordPart{
+		{Value: "foo", QuoteType: NoQuote},
+		{Value: "bar", QuoteType: DoubleQuote},
+		{Value: "baz", QuoteType: SingleQuote},
+	}
+	if len(cmd.Args) != 1 || !reflect.DeepEqual(cmd.Args[0].WordParts(), expected) {
+		t.Fatalf("arguments = %+v, expected one argument with parts %+v", cmd.Args, expected)
+	}
+	if got := cmd.Args[0].String(); got != `foo"bar"'baz'` {
+		t.Errorf("Argument.String() = %q, expected %q", got, `foo"bar"'baz'`)
+	}
+}
+
+// parseInput разбирает строку с помощью лексера и парсера.
+func parseInput(t *testing.T, input string) (Node, error) {
+	t.Helper()
+	tokens, err := lexer.NewLexer().Tokenize(input)
+	if err != nil {
+		t.Fatalf("Lexer.Tokenize(%q) error = %v", input, err)
+	}
+	return NewParser().Parse(tokens)
+}
+
+// TestParser_ParseIf проверяет разбор условной конструкции if с ветвями elif и else,
+// многострочную запись, вложенные конструкции и перенаправления составной команды.
+func TestParser_ParseIf(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"if then", "if true; then echo yes; fi", "if true; then echo yes; fi"},
+		{"else", "if false; then echo a; else echo b; fi", "if false; then echo a; else echo b; fi"},
+		{
+			"elif",
+			"if a; then b; elif c && d; then e; f; else g; fi",
+			"if a; then b; elif c && d; then e; f; else g; fi",
+		},
+		{"multiline", "if true\nthen\n  echo a\n\n  echo b\nfi", "if true; then echo a; echo b; fi"},
+		{"condition list", "if false; true; then echo a; fi", "if false; true; then echo a; fi"},
+		{"nested", "if a; then if b; then c; fi; fi", "if a; then if b; then c; fi; fi"},
+		{"reserved word as argument", "if echo fi; then echo then; fi", "if echo fi; then echo then; fi"},
+		{"redirect", "if true; then echo a; fi > out.txt", "if true; then echo a; fi >out.txt"},
+		{"pipeline", "if true; then echo a; fi | wc -l", "if true; then echo a; fi | wc -l"},
+		{"list", "echo a; if true; then echo b; fi && echo c", "echo a; if true; then echo b; fi && echo c"},
+		{"background in condition", "if sleep 1 & then echo a; fi", "if sleep 1 & then echo a; fi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseInput(t, tt.input)
+			if err != nil {
+				t.Fatalf("Parser.Parse(%q) error = %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.expected {
+				t.Errorf("Parser.Parse(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	node, err := parseInput(t, "if a; then b; elif c; then d; else e; fi")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	ifNode, ok := node.(*If)
+	if !ok {
+		t.Fatalf("Parser.Parse() = %T, expected *If", node)
+	}
+	if len(ifNode.Clauses) != 2 || ifNode.Else == nil {
+		t.Errorf("If = %+v, expected two clauses and else", ifNode)
+	}
+
+	node, err = parseInput(t, "if true; then echo a; fi 2>/dev/null")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	cmd, ok := node.(*Command)
+	if !ok || cmd.Compound == nil || len(cmd.Redirects) != 1 {
+		t.Errorf("Parser.Parse() = %#v, expected compound command with redirect", node)
+	}
+}
+
+// TestParser_ParseIfErrors проверяет ошибки разбора if: незавершенная конструкция
+// сообщается ошибкой lexer.ErrIncomplete, остальные - синтаксическими ошибками.
+func TestParser_ParseIfErrors(t *testing.T) {
+	for _, input := range []string{"if true", "if true; then", "if true; then echo a", "if a; then b; else c", "if a; then b; elif"} {
+		if _, err := parseInput(t, input); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+
+	for _, input := range []string{"fi", "then echo a", "if; then a; fi", "if a; then fi", "if a; then b; fi c", "if a; then b; else fi", "echo a; else"} {
+		_, err := parseInput(t, input)
+		if err == nil || errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected syntax error", input, err)
+		}
+	}
+}
+
+// TestParser_ParseLoops проверяет разбор циклов for (по словам, по позиционным параметрам
+// и арифметического), while и until, в том числе многострочных и вложенных.
+func TestParser_ParseLoops(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"for in", "for x in a 'b c' $y; do echo $x; done", "for x in a \"b c\" $y; do echo $x; done"},
+		{"for without in", "for x; do echo $x; done", "for x; do echo $x; done"},
+		{"for without in and semicolon", "for x do echo $x; done", "for x; do echo $x; done"},
+		{"for empty in", "for x in; do echo $x; done", "for x in; do echo $x; done"},
+		{"for multiline", "for x in a b\ndo\n  echo $x\ndone", "for x in a b; do echo $x; done"},
+		{"arithmetic for", "for ((i=0; i<3; i++)); do echo $i; done", "for ((i=0; i<3; i++)); do echo $i; done"},
+		{"arithmetic for empty", "for ((;;))\ndo break; done", "for ((; ; )); do break; done"},
+		{"while", "while a && b; do c; done", "while a && b; do c; done"},
+		{"until", "until a\ndo\n  b\ndone", "until a; do b; done"},
+		{"nested", "for x in a; do while b; do break 2; done; done", "for x in a; do while b; do break 2; done; done"},
+		{"redirect and pipeline", "while a; do b; done < in.txt | wc", "while a; do b; done <in.txt | wc"},
+		{"reserved words as arguments", "echo for do done in", "echo for do done in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseInput(t, tt.input)
+			if err != nil {
+				t.Fatalf("Parser.Parse(%q) error = %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.expected {
+				t.Errorf("Parser.Parse(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	node, err := parseInput(t, "for x in a b; do echo; done")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	forNode, ok := node.(*For)
+	if !ok || forNode.Variable != "x" || len(forNode.Words) != 2 {
+		t.Errorf("Parser.Parse() = %#v, expected for loop over two words", node)
+	}
+
+	node, err = parseInput(t, "for x; do echo; done")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if forNode, ok := node.(*For); !ok || forNode.Words != nil {
+		t.Errorf("Parser.Parse() = %#v, expected for loop without in", node)
+	}
+}
+
+// TestParser_ParseLoopErrors проверяет ошибки разбора циклов.
+func TestParser_ParseLoopErrors(t *testing.T) {
+	for _, input := range []string{"for", "for x in a b", "for x in a; do", "while true; do echo", "until a", "for ((;;)); do echo"} {
+		if _, err := parseInput(t, input); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+
+	for _, input := range []string{"done", "for 1x in a; do b; done", "for x in a; b; done", "while a; do done", "for ((i)); do a; done", "for x in a | b; do c; done"} {
+		_, err := parseInput(t, input)
+		if err == nil || errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected syntax error", input, err)
+		}
+	}
+}
+
+// TestParser_ParseCase проверяет разбор конструкции case: альтернативы шаблонов,
+// операторы завершения ветвей, пустые тела и многострочную запись.
+func TestParser_ParseCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"alternatives",
+			`case "$1" in start|stop) echo $1;; *) echo usage;; esac`,
+			`case "$1" in start|stop) echo $1;; *) echo usage;; esac`,
+		},
+		{"terminators", "case x in a) b;& c) d;;& e) f; esac", "case x in a) b;& c) d;;& e) f;; esac"},
+		{"empty body and parenthesis", "case x in (a) ;; (b|c) d;; esac", "case x in a) ;; b|c) d;; esac"},
+		{"no items", "case x in esac", "case x in esac"},
+		{
+			"multiline",
+			"case $x in\n  a | b )\n    echo one\n    echo two\n    ;;\n  'c d') echo three ;;\nesac",
+			`case $x in a|b) echo one; echo two;; "c d") echo three;; esac`,
+		},
+		{"reserved words as patterns", "case x in in|do|esac) echo;; esac", "case x in in|do|esac) echo;; esac"},
+		{"redirect", "case x in *) echo a;; esac > out.txt", "case x in *) echo a;; esac >out.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseInput(t, tt.input)
+			if err != nil {
+				t.Fatalf("Parser.Parse(%q) error = %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.expected {
+				t.Errorf("Parser.Parse(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	node, err := parseInput(t, "case x in a|b=c) y=1;& d) ;; esac")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	caseNode, ok := node.(*Case)
+	if !ok || len(caseNode.Items) != 2 {
+		t.Fatalf("Parser.Parse() = %#v, expected case with two items", node)
+	}
+	first := caseNode.Items[0]
+	if len(first.Patterns) != 2 || first.Patterns[1].Value != "b=c" || first.Terminator != CaseFallThrough {
+		t.Errorf("first item = %+v, expected patterns a and b=c with ;&", first)
+	}
+	if assignments := first.Body.Items[0].Pipelines[0].(*Command).Assignments; len(assignments) != 1 {
+		t.Errorf("first item body = %v, expected assignment", first.Body)
+	}
+	if len(caseNode.Items[1].Body.Items) != 0 {
+		t.Errorf("second item body = %v, expected empty", caseNode.Items[1].Body)
+	}
+}
+
+// TestParser_ParseCaseErrors проверяет ошибки разбора case: незавершенная конструкция
+// дочитывается со следующих строк, остальные ошибки синтаксические.
+func TestParser_ParseCaseErrors(t *testing.T) {
+	for _, input := range []string{"case", "case x", "case x in", "case x in a", "case x in a|", "case x in a) echo", "case x in a) echo;;"} {
+		if _, err := parseInput(t, input); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+
+	for _, input := range []string{"esac", "case x y) a;; esac", "case x in a b) c;; esac", "case x in a) b c) d;; esac", "echo a;; echo b", "echo (a)", "case x in |a) b;; esac"} {
+		_, err := parseInput(t, input)
+		if err == nil || errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected syntax error", input, err)
+		}
+	}
+}
+
+// TestParser_ParseFunction проверяет разбор групп команд { ...; } и определений функций
+// в формах name() и function name, многострочную запись и перенаправления тела.
+func TestParser_ParseFunction(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"group", "{ echo a; echo b; }", "{ echo a; echo b; }"},
+		{"group redirect", "{ echo a; } > out.txt", "{ echo a; } >out.txt"},
+		{"group pipeline", "{ echo a; echo b; } | wc", "{ echo a; echo b; } | wc"},
+		{"brace as argument", "{ echo { }; }", "{ echo { }; }"},
+		{"function", "greet() { echo hello $1; }", "greet () { echo hello $1; }"},
+		{"function keyword", "function greet { echo hello; }", "greet () { echo hello; }"},
+		{"function keyword with parens", "function greet() { echo hello; }", "greet () { echo hello; }"},
+		{"space before parens", "greet () { echo hello; }", "greet () { echo hello; }"},
+		{"multiline", "greet()\n{\n  echo a\n  echo b\n}", "greet () { echo a; echo b; }"},
+		{"compound body", "check() if a; then b; fi", "check () if a; then b; fi"},
+		{"body redirect", "log() { echo $1; } >> log.txt", "log () { echo $1; } >>log.txt"},
+		{"list", "f() { echo a; }; f && echo b", "f () { echo a; }; f && echo b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseInput(t, tt.input)
+			if err != nil {
+				t.Fatalf("Parser.Parse(%q) error = %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.expected {
+				t.Errorf("Parser.Parse(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	node, err := parseInput(t, "greet() { echo hello; }")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	fn, ok := node.(*Function)
+	if !ok {
+		t.Fatalf("Parser.Parse() = %T, expected *Function", node)
+	}
+	if _, ok := fn.Body.(*Group); fn.Name != "greet" || !ok {
+		t.Errorf("Function = %+v, expected greet with group body", fn)
+	}
+}
+
+// TestParser_ParseFunctionErrors проверяет ошибки разбора групп и определений функций.
+func TestParser_ParseFunctionErrors(t *testing.T) {
+	for _, input := range []string{"{", "{ echo a;", "{ echo a }", "f()", "f() {", "f() { echo a", "function", "function f", "f("} {
+		if _, err := parseInput(t, input); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+
+	for _, input := range []string{"}", "{ }", "echo a; }", "f() echo a", "f(x) { echo a; }", "function $f { echo a; }", "function if { echo a; }", "'f'() { echo a; }"} {
+		_, err := parseInput(t, input)
+		if err == nil || errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected syntax error", input, err)
+		}
+	}
+}
+
+// TestParser_ParseSubshell проверяет разбор подоболочек ( ... ): вложенность,
+// многострочную запись, перенаправления и использование в пайплайнах и функциях.
+func TestParser_ParseSubshell(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"subshell", "(cd build && make)", "(cd build && make)"},
+		{"trailing separator", "(echo a; echo b;)", "(echo a; echo b)"},
+		{"multiline", "(\n  echo a\n  echo b\n)", "(echo a; echo b)"},
+		{"nested", "( (echo a) )", "((echo a))"},
+		{"pipeline", "(cd build && make) | tee log", "(cd build && make) | tee log"},
+		{"pipeline stage", "echo a | (read x; echo $x)", "echo a | (read x; echo $x)"},
+		{"redirect", "(echo a) > out.txt", "(echo a) >out.txt"},
+		{"list", "(echo a) && echo b; (echo c) &", "(echo a) && echo b; (echo c) &"},
+		{"function body", "f() (cd /tmp)", "f () (cd /tmp)"},
+		{"group in subshell", "({ echo a; })", "({ echo a; })"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseInput(t, tt.input)
+			if err != nil {
+				t.Fatalf("Parser.Parse(%q) error = %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.expected {
+				t.Errorf("Parser.Parse(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	node, err := parseInput(t, "(echo a)")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if _, ok := node.(*Subshell); !ok {
+		t.Errorf("Parser.Parse() = %T, expected *Subshell", node)
+	}
+}
+
+// TestParser_ParseSubshellErrors проверяет ошибки разбора подоболочек.
+func TestParser_ParseSubshellErrors(t *testing.T) {
+	for _, input := range []string{"(", "(echo a", "(echo a;", "(echo a\n"} {
+		if _, err := parseInput(t, input); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+
+	for _, input := range []string{")", "()", "( )", "echo a)", "(echo a) b", "(echo a))", "(;)"} {
+		_, err := parseInput(t, input)
+		if err == nil || errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected syntax error", input, err)
+		}
+	}
+}
+
+// TestParser_ParseConditional тестирует разбор условного выражения [[ ]]:
+// приоритеты !, && и ||, скобки, унарные и бинарные операторы.
+func TestParser_ParseConditional(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"word", "[[ $x ]]", "[[ $x ]]"},
+		{"unary", "[[ -f file ]]", "[[ -f file ]]"},
+		{"pattern", `[[ $x == a* ]]`, "[[ $x == a* ]]"},
+		{"regex", "[[ $x =~ ^(a|b)+$ ]]", "[[ $x =~ ^(a|b)+$ ]]"},
+		{"string comparison", "[[ a < b ]]", "[[ a < b ]]"},
+		{"not", "[[ ! -d dir ]]", "[[ ! -d dir ]]"},
+		{"and or", "[[ a && b || c ]]", "[[ a && b || c ]]"},
+		{"group", "[[ ( a || b ) && ! c ]]", "[[ ( a || b ) && ! c ]]"},
+		{"operator as word", "[[ -f ]]", "[[ -f ]]"},
+		{"multiline", "[[ a &&\n b ]]", "[[ a && b ]]"},
+		{"list", "[[ a ]] && echo yes; [[ b ]]", "[[ a ]] && echo yes; [[ b ]]"},
+		{"in if", "if [[ a ]]; then echo yes; fi", "if [[ a ]]; then echo yes; fi"},
+		{"function body", "f() [[ $1 ]]", "f () [[ $1 ]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseInput(t, tt.input)
+			if err != nil {
+				t.Fatalf("Parser.Parse(%q) error = %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.expected {
+				t.Errorf("Parser.Parse(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	node, err := parseInput(t, "[[ ! a && b || c ]]")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	conditional, ok := node.(*Conditional)
+	if !ok {
+		t.Fatalf("Parser.Parse() = %T, expected *Conditional", node)
+	}
+	or, ok := conditional.Expr.(*CondAndOr)
+	if !ok || or.Op != OrOperator {
+		t.Fatalf("root expression = %#v, expected ||", conditional.Expr)
+	}
+	and, ok := or.Left.(*CondAndOr)
+	if !ok || and.Op != AndOperator {
+		t.Fatalf("left expression = %#v, expected &&", or.Left)
+	}
+	if _, ok := and.Left.(*CondNot); !ok {
+		t.Errorf("left operand of && = %#v, expected negation", and.Left)
+	}
+}
+
+// TestParser_ParseConditionalErrors тестирует ошибки разбора [[ ]]:
+// незавершенное выражение требует продолжения ввода, остальные - синтаксические ошибки.
+func TestParser_ParseConditionalErrors(t *testing.T) {
+	for _, input := range []string{"[[", "[[ a", "[[ a &&", "[[ a ==", "[[ ( a"} {
+		if _, err := parseInput(t, input); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected ErrIncomplete", input, err)
+		}
+	}
+
+	for _, input := range []string{"[[ ]]", "[[ a b ]]", "[[ a == ]]", "[[ && a ]]", "[[ ( a ]]", "[[ a ]] b", "]]"} {
+		_, err := parseInput(t, input)
+		if err == nil || errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected syntax error", input, err)
+		}
+	}
+}
+
+// TestParser_ParseProgram тестирует разбор программы из нескольких строк:
+// команды на отдельных строках объединяются в один список, пустой ввод дает пустую программу.
+func TestParser_ParseProgram(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		items    int
+	}{
+		{"empty", "", "", 0},
+		{"comment only", "# comment", "", 0},
+		{"single command", "echo a", "echo a", 1},
+		{"lines", "echo a\necho b\n\necho c\n", "echo a; echo b; echo c", 3},
+		{"leading blank lines", "\n\n  echo a", "echo a", 1},
+		{"compound", "if true\nthen\n  echo a\nfi\necho b", "if true; then echo a; fi; echo b", 2},
+		{"background", "sleep 1 &\necho a", "sleep 1 & echo a", 2},
+	}
+
+	l := lexer.NewLexer()
+	p := NewParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := l.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer.Tokenize(%q) error = %v", tt.input, err)
+			}
+			program, err := p.ParseProgram(tokens)
+			if err != nil {
+				t.Fatalf("Parser.ParseProgram(%q) error = %v", tt.input, err)
+			}
+			if got := program.String(); got != tt.expected {
+				t.Errorf("Parser.ParseProgram(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+			if len(program.Body.Items) != tt.items {
+				t.Errorf("Parser.ParseProgram(%q) items = %d, expected %d", tt.input, len(program.Body.Items), tt.items)
+			}
+			if program.IsEmpty() != (tt.items == 0) {
+				t.Errorf("Program.IsEmpty() = %v for %q", program.IsEmpty(), tt.input)
+			}
+		})
+	}
+}
+
+// TestParser_NodePositions проверяет позиции узлов AST: команды, ее слов,
+// присваиваний и перенаправлений, составных команд и элементов списка.
+func TestParser_NodePositions(t *testing.T) {
+	node, err := parseInput(t, "x=1 echo a >out | wc\nif true; then\n  { ls; }\nfi")
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	list, ok := node.(*List)
+	if !ok || len(list.Items) != 2 {
+		t.Fatalf("Parser.Parse() = %T %v, expected list of two items", node, node)
+	}
+
+	pipeline := list.Items[0].Pipelines[0].(*Pipeline)
+	command := pipeline.Commands[0]
+	ifNode := list.Items[1].Pipelines[0].(*If)
+	group := ifNode.Clauses[0].Body.Items[0].Pipelines[0]
+
+	tests := []struct {
+		name     string
+		node     Node
+		expected string
+	}{
+		{"list", list, "1:1"},
+		{"pipeline", pipeline, "1:1"},
+		{"command", command, "1:1"},
+		{"assignment", command.Assignments[0], "1:1"},
+		{"assignment value", command.Assignments[0].Value, "1:3"},
+		{"command name", command.NameArg, "1:5"},
+		{"argument", command.Args[0], "1:10"},
+		{"redirect", command.Redirects[0], "1:12"},
+		{"second command", pipeline.Commands[1], "1:19"},
+		{"if", ifNode, "2:1"},
+		{"group", group, "3:3"},
+	}
+	for _, tt := range tests {
+		if got := tt.node.Pos().String(); got != tt.expected {
+			t.Errorf("%s: Pos() = %s, expected %s", tt.name, got, tt.expected)
+		}
+	}
+}
+
+// TestParser_ErrorPositions проверяет, что синтаксические ошибки имеют тип *lexer.PosError
+// с позицией неожиданного токена.
+func TestParser_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"echo a | | wc", "1:10"},
+		{"| wc", "1:1"},
+		{"echo a\nfi", "2:1"},
+		{"echo a &&\n;", "2:1"},
+		{"for 1x in a; do echo; done", "1:5"},
+		{"if true; then\n  echo a\ndone", "3:1"},
+		{"if true; then", "1:10"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseInput(t, tt.input)
+		var posErr *lexer.PosError
+		if !errors.As(err, &posErr) {
+			t.Errorf("Parser.Parse(%q) error = %v, expected *lexer.PosError", tt.input, err)
+			continue
+		}
+		if got := posErr.Pos.String(); got != tt.expected {
+			t.Errorf("Parser.Parse(%q) error position = %s, expected %s", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -2,9 +2,15 @@ package shell
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"gocli/internal/environment"
 	"gocli/internal/executor"
@@ -13,80 +19,250 @@ import (
 	"gocli/internal/parser"
 )
 
+// defaultName - значение $0 в интерактивном режиме.
+const defaultName = "gocli"
+
+// rcFileName - имя файла инициализации интерактивного shell'а в домашнем каталоге.
+const rcFileName = ".goclirc"
+
+// statusSyntaxError - код завершения скрипта при синтаксической ошибке, как в bash.
+const statusSyntaxError = 2
+
+// Приглашения REPL по умолчанию: PS1 - перед новой командой,
+// PS2 - перед строкой продолжения незавершенного ввода.
+const (
+	defaultPS1 = "> "
+	defaultPS2 = "... "
+)
+
 // Shell представляет основную структуру командной оболочки.
 // Содержит все необходимые компоненты для обработки пользовательского ввода:
-// лексер для токенизации, парсер для построения AST, expander для подстановок
-// и исполнитель для выполнения команд.
+// инкрементальный парсер, накапливающий строки до полной программы, expander
+// для подстановок и исполнитель для выполнения команд.
 type Shell struct {
-	executor    *executor.Executor       // Исполнитель команд (встроенные и внешние)
-	lexer       *lexer.Lexer             // Лексер для разбора командной строки на токены
-	parser      *parser.Parser           // Парсер для построения абстрактного синтаксического дерева
-	expander    *expander.Expander       // Expander для подстановки переменных
-	environment *environment.Environment // Управление переменными окружения
+	executor    *executor.Executor        // Исполнитель команд (встроенные и внешние)
+	input       *parser.IncrementalParser // Парсер строк ввода в программы (лексер и парсер)
+	inputMu     sync.Mutex                // Защищает input: ошибки фоновых заданий выводятся во время чтения ввода
+	expander    *expander.Expander        // Expander для подстановки переменных
+	environment *environment.Environment  // Управление переменными окружения
+	rcFile      string                    // Файл инициализации интерактивного режима ("" - не читать)
 }
 
 // NewShell создает и инициализирует новый экземпляр командной оболочки.
+// Файл инициализации интерактивного режима - $GOCLI_RC, а если переменная не задана -
+// ~/.goclirc; его можно заменить или отключить через SetRCFile.
 // Возвращает готовую к использованию структуру Shell с настроенными компонентами.
 func NewShell() *Shell {
 	exec := executor.NewExecutor()
 	env := environment.NewEnvironment()
 	exec.SetEnvironment(env)
 	exp := expander.NewExpander(env)
+	exec.SetExpander(exp)
 
-	return &Shell{
+	shell := &Shell{
 		executor:    exec,
-		lexer:       lexer.NewLexer(),
-		parser:      parser.NewParser(),
+		input:       parser.NewIncrementalParser(),
 		expander:    exp,
 		environment: env,
 	}
+
+	if rcFile, ok := env.Get("GOCLI_RC"); ok {
+		shell.rcFile = rcFile
+	} else if home, err := os.UserHomeDir(); err == nil {
+		shell.rcFile = filepath.Join(home, rcFileName)
+	}
+
+	exec.SetErrorFormatter(shell.formatError)
+
+	env.SetSpecial("$", strconv.Itoa(os.Getpid()))
+	env.SetSpecial("?", "0")
+	shell.SetArgs(defaultName, nil)
+
+	return shell
+}
+
+// SetArgs задает имя shell'а или скрипта ($0) и позиционные параметры ($1, $2, ...).
+func (s *Shell) SetArgs(name string, args []string) {
+	s.environment.SetSpecial("0", name)
+	s.environment.SetPositional(args)
 }
 
-// Run запускает основной цикл командной оболочки (Read-Eval-Print Loop).
+// SetRCFile задает файл инициализации, выполняемый при запуске интерактивного режима
+// (gocli --rcfile file); пустой путь отключает чтение файла (gocli --norc).
+func (s *Shell) SetRCFile(path string) {
+	s.rcFile = path
+}
+
+// Run запускает основной цикл командной оболочки (Read-Eval-Print Loop) в интерактивном режиме.
 // Читает пользовательский ввод, обрабатывает команды и выводит результаты.
-// Возвращает ошибку при завершении работы или при критических ошибках.
-func (s *Shell) Run() error {
-	scanner := bufio.NewScanner(os.Stdin)
+// Перед каждым приглашением сообщает о завершившихся фоновых заданиях, как bash.
+// Если команда не завершена (например, ожидается тело here-document, не закрыты
+// кавычки, строка заканчивается обратным слешем или конструкция if не закрыта словом fi),
+// выводится приглашение PS2, и следующие строки дописываются к ней, пока ввод не станет полным.
+// Перед первым приглашением выполняется файл инициализации (см. loadRC).
+// Возвращает код завершения shell'а (аргумент exit или код последней команды)
+// и ошибку чтения ввода.
+func (s *Shell) Run() (int, error) {
+	s.executor.SetInteractive(true)
+	if status, exited := s.loadRC(); exited {
+		return status, nil
+	}
+	return s.run(os.Stdin, true)
+}
+
+// loadRC выполняет файл инициализации в текущем shell'е, как команда source, чтобы
+// заданные в нем переменные, функции и смена каталога действовали в сессии.
+// Отсутствующий файл пропускается, ошибки выполнения (кроме ненулевого кода возврата)
+// выводятся в stderr.
+// Если файл вызвал exit, возвращает код завершения и true.
+func (s *Shell) loadRC() (int, bool) {
+	if s.rcFile == "" {
+		return 0, false
+	}
+	if _, err := os.Stat(s.rcFile); errors.Is(err, fs.ErrNotExist) {
+		return 0, false
+	}
+
+	err := s.executor.Source(s.rcFile)
+	var exit *executor.ShellExit
+	if errors.As(err, &exit) {
+		s.reportExit(exit)
+		return exit.Status, true
+	}
+	if err != nil && !executor.IsStatusOnly(err) {
+		fmt.Fprint(os.Stderr, s.formatError(err))
+	}
+	return 0, false
+}
+
+// RunScript выполняет скрипт, читая его из r построчно, без приглашений и сообщений
+// о фоновых заданиях. Синтаксическая ошибка прерывает скрипт с кодом 2, как в bash;
+// ошибки выполнения команд выводятся, и скрипт продолжается, кроме ошибки подстановки
+// ${VAR:?word}, которая завершает скрипт с кодом 1.
+// Возвращает код завершения: аргумент exit или код последней выполненной команды.
+func (s *Shell) RunScript(r io.Reader) (int, error) {
+	return s.run(r, false)
+}
+
+// RunCommand выполняет команды из строки (режим gocli -c) так же, как скрипт.
+func (s *Shell) RunCommand(command string) (int, error) {
+	return s.RunScript(strings.NewReader(command))
+}
+
+// run читает ввод построчно, накапливает строки до полной программы и выполняет ее.
+// В интерактивном режиме выводит приглашения PS1/PS2 и сообщает о завершившихся
+// фоновых заданиях. Команда exit завершает чтение с заданным кодом.
+func (s *Shell) run(r io.Reader, interactive bool) (int, error) {
+	scanner := bufio.NewScanner(r)
 
 	for {
-		fmt.Print("> ")
+		if interactive {
+			if !s.input.Incomplete() {
+				s.executor.Jobs().ReportDone(os.Stderr)
+			}
+			fmt.Print(s.prompt())
+		}
 
 		if !scanner.Scan() {
 			break
 		}
 
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
+		// Пробелы в конце строки не отбрасываются: они могут быть экранированы (echo a\ )
+		s.inputMu.Lock()
+		program, err := s.input.Feed(scanner.Text())
+		s.inputMu.Unlock()
+		if errors.Is(err, lexer.ErrIncomplete) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprint(os.Stderr, s.formatError(err))
+			if !interactive {
+				return statusSyntaxError, nil
+			}
 			continue
 		}
 
-		if err := s.processCommand(line); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		err = s.execute(program)
+		var exit *executor.ShellExit
+		if errors.As(err, &exit) {
+			s.reportExit(exit)
+			return exit.Status, nil
+		}
+		// Ненулевой код возврата не выводится: команда уже сообщила о проблеме сама
+		if err != nil && !executor.IsStatusOnly(err) {
+			fmt.Fprint(os.Stderr, s.formatError(err))
 		}
 	}
 
-	return scanner.Err()
+	if s.input.Incomplete() {
+		fmt.Fprintln(os.Stderr, "Error: unexpected end of file")
+		return statusSyntaxError, scanner.Err()
+	}
+
+	return s.executor.LastStatus(), scanner.Err()
 }
 
-// processCommand обрабатывает одну команду пользователя.
-// Выполняет полный цикл обработки: токенизация → парсинг → подстановка переменных → выполнение.
-// Возвращает ошибку при любой ошибке на этапах обработки или выполнения.
-func (s *Shell) processCommand(line string) error {
-	tokens, err := s.lexer.Tokenize(line)
-	if err != nil {
-		return fmt.Errorf("lexical analysis failed: %w", err)
+// reportExit выводит ошибку, завершившую shell (например, ${VAR:?word} в скрипте).
+// Команда exit завершает shell без сообщения.
+func (s *Shell) reportExit(exit *executor.ShellExit) {
+	if exit.Err != nil {
+		fmt.Fprint(os.Stderr, s.formatError(exit.Err))
 	}
+}
 
-	ast, err := s.parser.Parse(tokens)
-	if err != nil {
-		return fmt.Errorf("parsing failed: %w", err)
+// prompt возвращает приглашение: значение PS1 перед новой командой и PS2 при продолжении
+// незавершенного ввода (если переменные не заданы - значения по умолчанию).
+func (s *Shell) prompt() string {
+	name, fallback := "PS1", defaultPS1
+	if s.input.Incomplete() {
+		name, fallback = "PS2", defaultPS2
+	}
+	if value, ok := s.environment.Get(name); ok {
+		return value
+	}
+	return fallback
+}
+
+// formatError возвращает сообщение об ошибке для вывода в stderr. Ошибка с позицией
+// в исходном тексте (*lexer.PosError: ошибки лексера, парсера и подстановок) выводится
+// с префиксом имя:строка:столбец, где имя - $0 (shell или скрипт), а затем
+// исходная строка и указатель ^ под ошибочным символом. Тот же формат исполнитель
+// использует для ошибок, которые выводит сам (см. executor.SetErrorFormatter).
+func (s *Shell) formatError(err error) string {
+	var posErr *lexer.PosError
+	if !errors.As(err, &posErr) || !posErr.Pos.IsValid() {
+		return fmt.Sprintf("Error: %v\n", err)
 	}
 
-	// Выполняем подстановку переменных
-	expandedAST, err := s.expander.Expand(ast)
-	if err != nil {
-		return fmt.Errorf("variable expansion failed: %w", err)
+	name, _ := s.environment.GetSpecial("0")
+	result := fmt.Sprintf("%s:%d:%d: %v\n", name, posErr.Pos.Line, posErr.Pos.Column, err)
+	s.inputMu.Lock()
+	line, ok := s.input.SourceLine(posErr.Pos.Line)
+	s.inputMu.Unlock()
+	if !ok {
+		return result
 	}
 
-	return s.executor.Execute(expandedAST)
+	// Табуляции сохраняются, чтобы указатель оказался под нужным символом
+	var caret strings.Builder
+	for i, r := range []rune(line) {
+		if i >= posErr.Pos.Column-1 {
+			break
+		}
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	return result + line + "\n" + caret.String() + "^\n"
+}
+
+// execute выполняет разобранную программу. Пустая программа не выполняется
+// и не изменяет код возврата последней команды ($?).
+func (s *Shell) execute(program *parser.Program) error {
+	if program.IsEmpty() {
+		return nil
+	}
+	return s.executor.Execute(program)
 }
@@ -1,28 +1,65 @@
 package shell
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+
+	"gocli/internal/executor"
+	"gocli/internal/lexer"
 )
 
 // TestNewShell тестирует создание нового экземпляра shell.
-// Проверяет, что все компоненты (executor, lexer, parser, environment) инициализированы корректно.
+// Проверяет, что все компоненты (executor, парсер ввода, environment) инициализированы корректно.
 func TestNewShell(t *testing.T) {
 	sh := NewShell()
 
 	if sh.executor == nil {
 		t.Error("Shell executor should be initialized")
 	}
-	if sh.lexer == nil {
-		t.Error("Shell lexer should be initialized")
-	}
-	if sh.parser == nil {
-		t.Error("Shell parser should be initialized")
+	if sh.input == nil {
+		t.Error("Shell input parser should be initialized")
 	}
 	if sh.environment == nil {
 		t.Error("Shell environment should be initialized")
 	}
 }
 
+// runInput разбирает полный ввод из одной или нескольких строк и выполняет его,
+// как цикл чтения shell'а, но возвращает ошибку разбора или выполнения вызывающему коду.
+func runInput(sh *Shell, input string) error {
+	program, err := sh.input.Parse(input)
+	if err != nil {
+		return err
+	}
+	return sh.execute(program)
+}
+
+// runAndRead выполняет ввод, записывающий результат в файл $DIR/out.txt, и возвращает
+// содержимое файла. Файл предварительно удаляется, чтобы команда, не записавшая
+// результат, не прошла проверку с выводом предыдущей команды.
+func runAndRead(t *testing.T, sh *Shell, input string) string {
+	t.Helper()
+	dir, _ := sh.environment.Get("DIR")
+	path := filepath.Join(dir, "out.txt")
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("cannot remove out.txt: %v", err)
+	}
+	if err := runInput(sh, input); err != nil {
+		t.Errorf("runInput(%q) error = %v", input, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("runInput(%q): cannot read out.txt: %v", input, err)
+	}
+	return string(data)
+}
+
 // TestShell_ProcessCommand тестирует обработку команд через shell.
 // Проверяет корректную обработку простых команд, команд с аргументами и обработку ошибок.
 func TestShell_ProcessCommand(t *testing.T) {
@@ -71,7 +108,7 @@ func TestShell_ProcessCommand(t *testing.T) {
 		{
 			name:    "empty command",
 			command: "",
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name:    "invalid syntax - unclosed quote",
@@ -87,10 +124,10 @@ func TestShell_ProcessCommand(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := sh.processCommand(tt.command)
+			err := runInput(sh, tt.command)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Shell.processCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+				t.Errorf("runInput(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
 			}
 		})
 	}
@@ -112,8 +149,10 @@ func TestShell_ProcessCommandWithAssignment(t *testing.T) {
 			wantErr: false,
 		},
 		{
+			// PATTERN задана только для echo: в grep попадает пустой шаблон,
+			// который без кавычек исчез бы при разбиении на поля
 			name:    "grep with variable assignment",
-			command: "PATTERN=test echo test | grep $PATTERN",
+			command: `PATTERN=test echo test | grep "$PATTERN"`,
 			wantErr: false,
 		},
 		{
@@ -130,10 +169,10 @@ func TestShell_ProcessCommandWithAssignment(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := sh.processCommand(tt.command)
+			err := runInput(sh, tt.command)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Shell.processCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+				t.Errorf("runInput(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
 			}
 		})
 	}
@@ -183,10 +222,10 @@ func TestShell_ProcessCommandWithPipeline(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := sh.processCommand(tt.command)
+			err := runInput(sh, tt.command)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Shell.processCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+				t.Errorf("runInput(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
 			}
 		})
 	}
@@ -216,10 +255,10 @@ func TestShell_ProcessCommandLexicalErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := sh.processCommand(tt.command)
+			err := runInput(sh, tt.command)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Shell.processCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+				t.Errorf("runInput(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
 			}
 
 			// Проверяем, что ошибка содержит информацию о лексическом анализе
@@ -245,7 +284,7 @@ func TestShell_ProcessCommandParsingErrors(t *testing.T) {
 		{
 			name:    "empty command after tokenization",
 			command: "   ",
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name:    "standalone assignment (VAR=value syntax)",
@@ -256,10 +295,10 @@ func TestShell_ProcessCommandParsingErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := sh.processCommand(tt.command)
+			err := runInput(sh, tt.command)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Shell.processCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+				t.Errorf("runInput(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
 			}
 		})
 	}
@@ -309,10 +348,10 @@ func TestShell_StandaloneAssignments(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := sh.processCommand(tt.command)
+			err := runInput(sh, tt.command)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Shell.processCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+				t.Errorf("runInput(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
 			}
 
 			if !tt.wantErr && tt.checkVar != "" {
@@ -327,3 +366,881 @@ func TestShell_StandaloneAssignments(t *testing.T) {
 		})
 	}
 }
+
+// TestShell_ProcessCommandWithRedirects тестирует перенаправления ввода-вывода через shell.
+// Проверяет полный цикл: лексер → парсер → expander → executor.
+func TestShell_ProcessCommandWithRedirects(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	commands := []string{
+		`echo hello > $DIR/out.txt`,
+		`echo world >>"$DIR/out.txt"`,
+		`cat <$DIR/out.txt | wc > $DIR/count.txt`,
+		`cat $DIR/missing.txt 2>$DIR/err.txt >$DIR/none.txt`,
+	}
+
+	for i, command := range commands {
+		err := runInput(sh, command)
+		if (err != nil) != (i == len(commands)-1) {
+			t.Fatalf("runInput(%q) error = %v", command, err)
+		}
+	}
+
+	expected := map[string]string{
+		"out.txt":   "hello\nworld\n",
+		"count.txt": "2 2 12\n",
+		"none.txt":  "",
+	}
+	for name, want := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("cannot read %s: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, expected %q", name, string(data), want)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "err.txt"))
+	if err != nil || len(data) == 0 {
+		t.Errorf("expected error message in err.txt, got %q (%v)", string(data), err)
+	}
+}
+
+// TestShell_ProcessCommandList тестирует списки команд с ;, && и ||.
+// Проверяет, что переменные, установленные ранее в списке, видны следующим командам,
+// и что выполнение пайплайнов зависит от кода возврата предыдущих.
+func TestShell_ProcessCommandList(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	tests := []struct {
+		name     string
+		command  string
+		wantErr  bool
+		file     string
+		expected string
+	}{
+		{
+			name:     "assignment visible in next command",
+			command:  "X=first; echo $X > $DIR/a.txt",
+			file:     "a.txt",
+			expected: "first\n",
+		},
+		{
+			name:     "and-or chain",
+			command:  "cat $DIR/none 2>$DIR/err.txt && echo ok > $DIR/b.txt || echo failed > $DIR/b.txt",
+			file:     "b.txt",
+			expected: "failed\n",
+		},
+		{
+			name:     "grep without match fails the chain",
+			command:  "echo hello | grep bye && echo found > $DIR/c.txt; echo done >> $DIR/c.txt",
+			file:     "c.txt",
+			expected: "done\n",
+		},
+		{
+			name:    "last command status is returned",
+			command: "echo ok > $DIR/d.txt; cat $DIR/none 2>$DIR/err.txt",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runInput(sh, tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runInput(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+			if tt.file == "" {
+				return
+			}
+			data, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("cannot read %s: %v", tt.file, err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("%s = %q, expected %q", tt.file, string(data), tt.expected)
+			}
+		})
+	}
+}
+
+// TestShell_ProcessCommandHeredoc тестирует here-document и here-string.
+// Тело документа раскрывается, если разделитель не в кавычках,
+// а незавершенный документ сообщается ошибкой lexer.ErrIncomplete.
+func TestShell_ProcessCommandHeredoc(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+	sh.environment.Set("NAME", "world")
+
+	commands := []string{
+		"cat <<EOF > $DIR/expanded.txt\nhello $NAME\nEOF",
+		"cat <<'EOF' > $DIR/literal.txt\nhello $NAME\nEOF",
+		"cat <<-EOF > $DIR/stripped.txt\n\thello\n\tEOF",
+		`wc <<< "hello $NAME" > $DIR/herestring.txt`,
+	}
+	for _, command := range commands {
+		if err := runInput(sh, command); err != nil {
+			t.Fatalf("runInput(%q) error = %v", command, err)
+		}
+	}
+
+	expected := map[string]string{
+		"expanded.txt":   "hello world\n",
+		"literal.txt":    "hello $NAME\n",
+		"stripped.txt":   "hello\n",
+		"herestring.txt": "1 2 12\n",
+	}
+	for name, want := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("cannot read %s: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, expected %q", name, string(data), want)
+		}
+	}
+
+	if err := runInput(sh, "cat <<EOF\nunfinished"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Errorf("expected ErrIncomplete for unterminated here-document, got %v", err)
+	}
+}
+
+// TestShell_ProcessCommandSubstitution тестирует подстановку команд $(...) и `...`.
+// Проверяет пайплайны внутри подстановки, вложенные подстановки, кавычки
+// и удаление завершающих переводов строки.
+func TestShell_ProcessCommandSubstitution(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`echo $(echo hello | wc) > $DIR/out.txt`, "1 1 6\n"},
+		{`echo "[$(echo "a  b")]" > $DIR/out.txt`, "[a  b]\n"},
+		{`echo $(echo $(echo nested)) > $DIR/out.txt`, "nested\n"},
+		{"echo `echo back` > $DIR/out.txt", "back\n"},
+		{`echo "$(echo one; echo two)" > $DIR/out.txt`, "one\ntwo\n"},
+		{`X=$(echo value); echo $X > $DIR/out.txt`, "value\n"},
+		// Команда только из присваиваний получает код последней подстановки команды
+		{`X=$(false); echo $? > $DIR/out.txt`, "1\n"},
+		{`if X=$(exit 3); then echo yes; else echo no $?; fi > $DIR/out.txt`, "no 3\n"},
+		{`X=$(false) Y=$(true); echo $? > $DIR/out.txt`, "0\n"},
+		{`X=$(exit 2) true; echo $? > $DIR/out.txt`, "0\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	if err := runInput(sh, `echo $(| wc)`); err == nil {
+		t.Error("expected error for syntax error inside substitution")
+	}
+}
+
+// TestShell_ProcessCommandSpecialParameters тестирует специальные параметры
+// $?, $$, $0, $#, $@, $* и позиционные параметры.
+func TestShell_ProcessCommandSpecialParameters(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+	sh.SetArgs("script.sh", []string{"one", "two words", "three"})
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`cat $DIR/none.txt 2> $DIR/err.txt; echo $? > $DIR/out.txt`, "1\n"},
+		{`echo ok > $DIR/out.txt && echo $? > $DIR/out.txt`, "0\n"},
+		{`echo $$ > $DIR/out.txt`, strconv.Itoa(os.Getpid()) + "\n"},
+		{`echo $0 $# > $DIR/out.txt`, "script.sh 3\n"},
+		{`echo $1 ${2} $3 $4 > $DIR/out.txt`, "one two words three\n"},
+		{`echo "[$*]" > $DIR/out.txt`, "[one two words three]\n"},
+		{`echo "${#@}" "${10:-none}" > $DIR/out.txt`, "3 none\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+}
+
+// TestShell_ProcessCommandFieldSplitting тестирует разбиение результатов подстановок на слова.
+func TestShell_ProcessCommandFieldSplitting(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+	for name, content := range map[string]string{"1.txt": "first\n", "2.txt": "second\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`FILES="$DIR/1.txt $DIR/2.txt"; cat $FILES > $DIR/out.txt`, "first\nsecond\n"},
+		{`X="one   two"; echo $X > $DIR/out.txt`, "one two\n"},
+		{`X="one   two"; echo "$X" > $DIR/out.txt`, "one   two\n"},
+		{`IFS=:; X=a:b; echo $X > $DIR/out.txt; IFS=" "`, "a b\n"},
+		{`echo $(echo "x   y") > $DIR/out.txt`, "x y\n"},
+		{`echo ${UNSET:-"x  y"} ${UNSET:-a  b} > $DIR/out.txt`, "x  y a b\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+}
+
+// TestShell_ProcessCommandBackslashEscapes проверяет экранирование обратным слешем
+// вне кавычек и в двойных кавычках, а также продолжение строки.
+func TestShell_ProcessCommandBackslashEscapes(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+	sh.environment.Set("X", "5")
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`echo a\ b c > $DIR/out.txt`, "a b c\n"},
+		{`echo \| \; \& \> > $DIR/out.txt`, "| ; & >\n"},
+		{`echo "say \"hi\"" > $DIR/out.txt`, "say \"hi\"\n"},
+		{`echo \$X "\$X" "\\$X" "a\b" > $DIR/out.txt`, "$X $X \\5 a\\b\n"},
+		{`echo \* \~ \{a,b\} > $DIR/out.txt`, "* ~ {a,b}\n"},
+		{"echo one \\\ntwo > $DIR/out.txt", "one two\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+}
+
+// TestShell_ProcessCommandComments проверяет, что комментарии отбрасываются,
+// а строка из одного комментария не считается ошибкой.
+func TestShell_ProcessCommandComments(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	if err := runInput(sh, "# only a comment"); err != nil {
+		t.Errorf("runInput() on comment line error = %v", err)
+	}
+
+	if got := runAndRead(t, sh, `echo a#b "#c" > $DIR/out.txt # trailing comment`); got != "a#b #c\n" {
+		t.Errorf("output = %q, expected %q", got, "a#b #c\n")
+	}
+}
+
+// TestShell_ProcessCommandCompositeWords проверяет составные слова из частей в кавычках
+// и распознавание присваиваний только в начале команды.
+func TestShell_ProcessCommandCompositeWords(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`echo foo"bar"'baz' "a b"c > $DIR/out.txt`, "foobarbaz a bc\n"},
+		{`echo a=b > $DIR/out.txt`, "a=b\n"},
+		{`X="one two"three; echo "$X" > $DIR/out.txt`, "one twothree\n"},
+		{`X= echo "[$X]" > $DIR/out.txt`, "[]\n"},
+		{`CMD="echo split name"; $CMD > $DIR/out.txt`, "split name\n"},
+		{`"ec"'ho' quoted name > $DIR/out.txt`, "quoted name\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	if _, ok := sh.environment.Get("a"); ok {
+		t.Error("echo a=b should not assign variable a")
+	}
+}
+
+// TestShell_ProcessCommandIf проверяет условную конструкцию if: выбор ветви
+// по коду возврата условия, многострочную запись, вложенность и перенаправления.
+func TestShell_ProcessCommandIf(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`if echo > /dev/null; then echo yes; else echo no; fi > $DIR/out.txt`, "yes\n"},
+		{`if cat $DIR/missing 2>/dev/null; then echo yes; else echo no; fi > $DIR/out.txt`, "no\n"},
+		{
+			"if cat $DIR/missing 2>/dev/null\nthen\n  echo one\nelif X=2; cat $DIR/missing 2>/dev/null\nthen\n  echo two\nelse\n  echo three $X\nfi > $DIR/out.txt",
+			"three 2\n",
+		},
+		{`if echo > /dev/null; then if cat $DIR/missing 2>/dev/null; then echo a; else echo b; fi; fi > $DIR/out.txt`, "b\n"},
+		{`if echo > /dev/null; then echo a; echo b; fi | wc > $DIR/out.txt`, "2 2 4\n"},
+		{`Y=1; if echo > /dev/null; then Y=2; fi; echo $Y > $DIR/out.txt`, "2\n"},
+		{`if cat $DIR/missing 2>/dev/null; then echo a; fi; echo $? > $DIR/out.txt`, "0\n"},
+		{`echo if then fi > $DIR/out.txt`, "if then fi\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	// Незавершенная конструкция требует продолжения ввода на следующих строках
+	for _, command := range []string{"if echo", "if echo; then", "if echo\nthen\necho a"} {
+		if err := runInput(sh, command); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("runInput(%q) error = %v, expected ErrIncomplete", command, err)
+		}
+	}
+}
+
+// TestShell_ProcessCommandLoops проверяет циклы for, while и until с подстановками,
+// пайплайнами и встроенными командами в теле, а также break и continue во вложенных циклах.
+func TestShell_ProcessCommandLoops(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`for x in a "b c" {1..2}; do echo "[$x]"; done > $DIR/out.txt`, "[a]\n[b c]\n[1]\n[2]\n"},
+		{`L="x y"; for w in $L; do echo $w | wc; done > $DIR/out.txt`, "1 1 2\n1 1 2\n"},
+		{"for ((i = 0; i < 3; i++))\ndo\n  echo $i\ndone > $DIR/out.txt", "0\n1\n2\n"},
+		{`n=0; while echo > /dev/null; do n=$((n+1)); if echo $n | grep 3 > /dev/null; then break; fi; done; echo $n > $DIR/out.txt`, "3\n"},
+		{`n=0; until echo $n | grep 2 > /dev/null; do n=$((n+1)); done; echo $n > $DIR/out.txt`, "2\n"},
+		{`for a in 1 2 3; do for b in x y; do if echo $a$b | grep 2y > /dev/null; then break 2; fi; echo $a$b; done; done > $DIR/out.txt`, "1x\n1y\n2x\n"},
+		{`for a in 1 2; do for b in x y; do echo $a$b; continue 2; echo no; done; echo no; done > $DIR/out.txt`, "1x\n2x\n"},
+		{`for ((i = 0; i < 10; i++)); do if echo $i | grep 3 > /dev/null; then break; fi; done; echo $i > $DIR/out.txt`, "3\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	// Без in цикл перебирает позиционные параметры
+	sh.environment.SetPositional([]string{"p1", "p 2"})
+	if got := runAndRead(t, sh, `for p; do echo "<$p>"; done > $DIR/out.txt`); got != "<p1>\n<p 2>\n" {
+		t.Errorf("for without in output = %q, expected %q", got, "<p1>\n<p 2>\n")
+	}
+}
+
+// TestShell_ProcessCommandCase проверяет конструкцию case: подстановки в слове и шаблонах,
+// кавычки в шаблонах, альтернативы, операторы завершения ветвей и многострочную запись.
+func TestShell_ProcessCommandCase(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+	sh.environment.SetPositional([]string{"stop", "a b"})
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`case "$1" in start|stop) echo "do $1";; *) echo usage;; esac > $DIR/out.txt`, "do stop\n"},
+		{`case "$2" in a*b) echo glob;; esac > $DIR/out.txt`, "glob\n"},
+		{`P='*'; case x in "$P") echo quoted;; $P) echo unquoted;; esac > $DIR/out.txt`, "unquoted\n"},
+		{`case '*' in "*") echo literal;; esac > $DIR/out.txt`, "literal\n"},
+		{`case a.go in *.\go) echo escaped;; esac > $DIR/out.txt`, "escaped\n"},
+		{`case ab in a*) echo a;;& *b) echo b;& x) echo x;; y) echo y;; esac > $DIR/out.txt`, "a\nb\nx\n"},
+		{"case $1 in\n  start)\n    echo starting\n    ;;\n  stop | restart)\n    echo stopping\n    ;;\nesac > $DIR/out.txt", "stopping\n"},
+		{`for f in a.txt b.go c; do case $f in *.txt) echo text;; *.go) echo go;; *) echo other; esac; done > $DIR/out.txt`, "text\ngo\nother\n"},
+		{`for f in a b c; do case $f in b) break;; esac; echo $f; done > $DIR/out.txt`, "a\n"},
+		{`case x in y) echo;; esac; echo $? > $DIR/out.txt`, "0\n"},
+		{`echo $(case a in a) echo paren;; esac) > $DIR/out.txt`, "paren\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	for _, command := range []string{"case $1 in", "case $1 in\nstart) echo a;;"} {
+		if err := runInput(sh, command); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("runInput(%q) error = %v, expected ErrIncomplete", command, err)
+		}
+	}
+}
+
+// TestShell_ProcessCommandFunctions проверяет функции shell'а: позиционные параметры
+// вызова, локальные переменные, return, рекурсию, многострочные определения,
+// а также вывод и удаление функций командами declare -f и unset -f.
+func TestShell_ProcessCommandFunctions(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+	sh.environment.SetPositional([]string{"outer"})
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`greet() { echo "hello $1 ($#)"; }; greet world > $DIR/out.txt`, "hello world (1)\n"},
+		{`function add { echo $(($1 + $2)); }; add 2 3 > $DIR/out.txt`, "5\n"},
+		{`echo $1 > $DIR/out.txt`, "outer\n"},
+		{`x=global; f() { local x=local; echo $x; }; f > $DIR/out.txt; echo $x >> $DIR/out.txt`, "local\nglobal\n"},
+		{`x=1; f() { x=2; }; f; echo $x > $DIR/out.txt`, "2\n"},
+		{`f() { return 3; echo skipped; }; f; echo $? > $DIR/out.txt`, "3\n"},
+		{`f() { echo a > /dev/null; return; }; f && echo ok > $DIR/out.txt`, "ok\n"},
+		{`f() { for i in 1 2; do return $i; done; }; f || echo $? > $DIR/out.txt`, "1\n"},
+		{`fib() { case $1 in 0|1) echo $1; return;; esac; echo $(($(fib $(($1 - 1))) + $(fib $(($1 - 2))))); }; fib 10 > $DIR/out.txt`, "55\n"},
+		{"multi()\n{\n  echo one\n  echo two\n}\nmulti > $DIR/out.txt", "one\ntwo\n"},
+		{`log() { echo "$@"; } >> $DIR/out.txt; echo start > $DIR/out.txt; log a b; log c`, "start\na b\nc\n"},
+		{`f() { echo f; }; f | wc > $DIR/out.txt`, "1 1 2\n"},
+		{`greet() { echo hi; }; declare -f greet > $DIR/out.txt`, "greet () { echo hi; }\n"},
+		{`greet() { echo hi; }; unset -f greet; greet 2> /dev/null; echo $? > $DIR/out.txt`, "127\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	for _, command := range []string{"f() {", "f()", "function f {\necho a"} {
+		if err := runInput(sh, command); !errors.Is(err, lexer.ErrIncomplete) {
+			t.Errorf("runInput(%q) error = %v, expected ErrIncomplete", command, err)
+		}
+	}
+}
+
+// TestShell_ProcessCommandSubshell проверяет подоболочки и группы команд: изоляцию
+// переменных и рабочего каталога (в том числе в подстановке $(...)), exit в подоболочке,
+// общие перенаправления и использование в пайплайнах.
+func TestShell_ProcessCommandSubshell(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "build"), 0o755); err != nil {
+		t.Fatalf("cannot create build directory: %v", err)
+	}
+	sh.environment.Set("DIR", dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd() error = %v", err)
+	}
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`x=1; (x=2; echo $x) > $DIR/out.txt; echo $x >> $DIR/out.txt`, "2\n1\n"},
+		{`(cd $DIR/build && pwd) | cat > $DIR/out.txt`, filepath.Join(dir, "build") + "\n"},
+		{`y=$(cd $DIR/build; z=1; pwd); echo $y $z > $DIR/out.txt`, filepath.Join(dir, "build") + "\n"},
+		{`(exit 3); echo $? > $DIR/out.txt`, "3\n"},
+		{`(echo a; exit; echo b) > $DIR/out.txt`, "a\n"},
+		{`{ echo a; echo b; } | wc > $DIR/out.txt`, "2 2 4\n"},
+		{`x=1; { x=2; }; echo $x > $DIR/out.txt`, "2\n"},
+		{`{ echo a; echo b >&2; } > $DIR/out.txt 2>&1`, "a\nb\n"},
+		{"(\n  echo one\n  echo two\n) > $DIR/out.txt", "one\ntwo\n"},
+		{`f() ( x=inner; echo $x ); x=outer; f > $DIR/out.txt; echo $x >> $DIR/out.txt`, "inner\nouter\n"},
+		{`(f() { echo f; }); g() { echo g; }; (g) > $DIR/out.txt`, "g\n"},
+		// Каждая команда пайплайна выполняется в подоболочке
+		{`{ X=5; } | cat; echo "[$X]" > $DIR/out.txt`, "[]\n"},
+		{`f() { Y=7; }; f | cat; echo "[$Y]" > $DIR/out.txt`, "[]\n"},
+		{`cd / | cat; pwd > $DIR/out.txt`, wd + "\n"},
+		{`echo a | Z=1 | cat; echo "[$Z]" > $DIR/out.txt`, "[]\n"},
+		// cd в фоновой подоболочке не меняет каталог команд, выполняющихся одновременно с ней
+		{`(cd /; sleep 0.3) & sleep 0.1; pwd > $DIR/out.txt; wait`, wd + "\n"},
+		// Относительные пути отсчитываются от рабочего каталога shell'а, а не процесса
+		{"cd $DIR/build; echo rel > f.txt; cat f.txt *.txt > ../out.txt; [ -f f.txt ] && wc f.txt >> ../out.txt; sh -c pwd >> ../out.txt",
+			"rel\nrel\n1 1 4 f.txt\n" + filepath.Join(dir, "build") + "\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+		if current, _ := os.Getwd(); current != wd {
+			t.Fatalf("runInput(%q) changed working directory to %q", tt.command, current)
+		}
+	}
+
+	if err := runInput(sh, "(echo a"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Errorf("runInput(%q) error = %v, expected ErrIncomplete", "(echo a", err)
+	}
+}
+
+// TestShell_ProcessCommandConditional проверяет команды test и [ и выражения [[ ]]
+// от разбора строки до результата: шаблоны, регулярные выражения и BASH_REMATCH.
+func TestShell_ProcessCommandConditional(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{`test -d $DIR && echo dir > $DIR/out.txt`, "dir\n"},
+		{`echo > $DIR/f.txt; [ -f $DIR/f.txt -a ! -s $DIR/missing ] && echo yes > $DIR/out.txt`, "yes\n"},
+		{`[ 2 -lt 10 ]; echo $? > $DIR/out.txt`, "0\n"},
+		{`[ abc = abd ] || echo differ > $DIR/out.txt`, "differ\n"},
+		{`v="a b"; [[ $v == "a b" && $v == a* ]] && echo match > $DIR/out.txt`, "match\n"},
+		{`v=abc; [[ $v == "a*" ]]; echo $? > $DIR/out.txt`, "1\n"},
+		{`v=v1.25; [[ $v =~ ^v([0-9]+)\.([0-9]+)$ ]] && echo ${BASH_REMATCH[1]} ${BASH_REMATCH[2]} > $DIR/out.txt`, "1 25\n"},
+		{`[[ abc =~ a(x|b)c ]] && echo $BASH_REMATCH ${#BASH_REMATCH[@]} > $DIR/out.txt`, "abc 2\n"},
+		{`re='^[0-9]+$'; [[ 42 =~ $re ]] && echo number > $DIR/out.txt`, "number\n"},
+		{`[[ a.c =~ "a.c" ]] && [[ abc =~ "a.c" ]] || echo literal > $DIR/out.txt`, "literal\n"},
+		{`if [[ ( -d $DIR || -f $DIR ) && ! -e $DIR/missing ]]; then echo ok; fi > $DIR/out.txt`, "ok\n"},
+		{"[[ a &&\n  b ]] && echo multiline > $DIR/out.txt", "multiline\n"},
+	}
+
+	for _, tt := range tests {
+		if got := runAndRead(t, sh, tt.command); got != tt.expected {
+			t.Errorf("runInput(%q) output = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	if err := runInput(sh, "[[ a"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Errorf("runInput(%q) error = %v, expected ErrIncomplete", "[[ a", err)
+	}
+}
+
+// TestShell_FeedMultilineProgram проверяет построчный ввод программы, как в REPL:
+// незавершенные строки накапливаются с приглашением PS2, пустые строки и комментарии
+// не выполняются и не меняют $?.
+func TestShell_FeedMultilineProgram(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	lines := []string{
+		"# программа из нескольких строк",
+		"",
+		"msg='first",
+		"second'",
+		"for i in 1 2; do",
+		`  echo "$msg $i"`,
+		"done > $DIR/out.txt",
+		"test a = b",
+		"",
+		"echo $? >> $DIR/out.txt",
+	}
+	prompts := []string{"> ", "> ", "> ", "... ", "> ", "... ", "... ", "> ", "> ", "> "}
+
+	for i, line := range lines {
+		if prompt := sh.prompt(); prompt != prompts[i] {
+			t.Errorf("line %d: prompt = %q, expected %q", i, prompt, prompts[i])
+		}
+		program, err := sh.input.Feed(line)
+		if errors.Is(err, lexer.ErrIncomplete) {
+			continue
+		}
+		if err == nil {
+			err = sh.execute(program)
+		}
+		var exitErr *executor.ExitStatusError
+		if err != nil && !errors.As(err, &exitErr) {
+			t.Fatalf("line %d (%q): error = %v", i, line, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("cannot read out.txt: %v", err)
+	}
+	expected := "first\nsecond 1\nfirst\nsecond 2\n1\n"
+	if string(data) != expected {
+		t.Errorf("output = %q, expected %q", string(data), expected)
+	}
+
+	sh.environment.Set("PS2", "more> ")
+	if _, err := sh.input.Feed("if true; then"); !errors.Is(err, lexer.ErrIncomplete) {
+		t.Fatalf("Feed(%q) error = %v, expected ErrIncomplete", "if true; then", err)
+	}
+	if prompt := sh.prompt(); prompt != "more> " {
+		t.Errorf("prompt = %q, expected %q", prompt, "more> ")
+	}
+}
+
+// TestShell_FormatError проверяет диагностику ошибок с позицией: префикс имя:строка:столбец,
+// исходную строку и указатель ^ под ошибочным символом (с учетом табуляций).
+func TestShell_FormatError(t *testing.T) {
+	sh := NewShell()
+	sh.SetArgs("script.sh", nil)
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{"echo a | | wc", "script.sh:1:10: parsing failed: empty command before pipe\necho a | | wc\n         ^\n"},
+		{"echo ok\n\tls ; ;", "script.sh:3:7: parsing failed: syntax error near unexpected token \";\"\n\tls ; ;\n\t     ^\n"},
+		{"echo ${X:?unset} > /dev/null", "script.sh:4:6: variable expansion failed: failed to expand argument: X: unset\necho ${X:?unset} > /dev/null\n     ^\n"},
+	}
+
+	for _, tt := range tests {
+		err := runInput(sh, tt.command)
+		if err == nil {
+			t.Fatalf("runInput(%q) expected error", tt.command)
+		}
+		if got := sh.formatError(err); got != tt.expected {
+			t.Errorf("Shell.formatError() for %q = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+
+	if got := sh.formatError(errors.New("plain")); got != "Error: plain\n" {
+		t.Errorf("Shell.formatError() = %q, expected %q", got, "Error: plain\n")
+	}
+}
+
+// TestShell_ReportedErrorFormat проверяет, что ошибки, которые исполнитель выводит сам
+// (промежуточные команды списка, тела циклов, if и функций), выводятся в том же формате,
+// что и ошибки, возвращаемые shell'у: с позицией и исходной строкой.
+func TestShell_ReportedErrorFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		program string
+		line    string
+		pos     string
+	}{
+		{"list item", "echo ${X:?unset}; echo ok", "echo ${X:?unset}; echo ok", "1:6"},
+		{"loop body", "for i in 1; do\n  echo ${X:?unset}\ndone", "  echo ${X:?unset}", "2:8"},
+		{"if body", "if true; then\n  echo ${X:?unset}\n  echo ok\nfi", "  echo ${X:?unset}", "2:8"},
+		{"function body", "f() {\n  echo ${X:?unset}\n  echo ok\n}\nf", "  echo ${X:?unset}", "2:8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// В интерактивном режиме ошибка ${X:?} не завершает shell и выводится исполнителем
+			sh := NewShell()
+			sh.SetArgs("script.sh", nil)
+			sh.executor.SetInteractive(true)
+
+			// Ошибку последней команды выводит shell, как в цикле чтения
+			stderr := captureStderr(t, func() {
+				if err := runInput(sh, tt.program); err != nil {
+					fmt.Fprint(os.Stderr, sh.formatError(err))
+				}
+			})
+
+			caret := strings.Repeat(" ", strings.Index(tt.line, "$")) + "^"
+			expected := "script.sh:" + tt.pos + ": variable expansion failed: failed to expand argument: X: unset\n" + tt.line + "\n" + caret + "\n"
+			if stderr != expected {
+				t.Errorf("stderr = %q, expected %q", stderr, expected)
+			}
+		})
+	}
+}
+
+// TestShell_RunScript проверяет выполнение скрипта: строка #! считается комментарием,
+// позиционные параметры доступны через $0, $1 и $#, exit завершает скрипт с заданным
+// кодом, без exit код завершения - код последней команды, а синтаксическая ошибка
+// прерывает скрипт с кодом 2.
+func TestShell_RunScript(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		args       []string
+		wantStatus int
+		wantOutput string
+	}{
+		{
+			name:       "positional parameters",
+			script:     "#!/usr/bin/env gocli\necho \"$0 $# $1 $2\" > $DIR/out.txt\n",
+			args:       []string{"a", "b"},
+			wantOutput: "script.sh 2 a b\n",
+		},
+		{
+			name:       "multiline program",
+			script:     "for i in 1 2; do\n  echo $i\ndone > $DIR/out.txt\n",
+			wantOutput: "1\n2\n",
+		},
+		{
+			name:       "exit stops script",
+			script:     "echo before > $DIR/out.txt\nexit 3\necho after >> $DIR/out.txt\n",
+			wantStatus: 3,
+			wantOutput: "before\n",
+		},
+		{
+			name:       "exit inside function",
+			script:     "f() {\n  exit 4\n}\nf\necho after > $DIR/out.txt\n",
+			wantStatus: 4,
+		},
+		{
+			name:       "last command status",
+			script:     "echo ok > $DIR/out.txt\ntest a = b\n",
+			wantStatus: 1,
+			wantOutput: "ok\n",
+		},
+		{
+			name:       "syntax error",
+			script:     "echo before > $DIR/out.txt\necho a | | wc\necho after >> $DIR/out.txt\n",
+			wantStatus: 2,
+			wantOutput: "before\n",
+		},
+		{
+			name:       "unset parameter error",
+			script:     "echo before > $DIR/out.txt\necho ${X:?unset}\necho after >> $DIR/out.txt\n",
+			wantStatus: 1,
+			wantOutput: "before\n",
+		},
+		{
+			name:       "unset parameter error in subshell",
+			script:     "(echo ${X:?unset})\necho after $? > $DIR/out.txt\n",
+			wantOutput: "after 1\n",
+		},
+		{
+			name:       "unexpected end of file",
+			script:     "echo before > $DIR/out.txt\nif true; then\n",
+			wantStatus: 2,
+			wantOutput: "before\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh := NewShell()
+			dir := t.TempDir()
+			sh.environment.Set("DIR", dir)
+			sh.SetArgs("script.sh", tt.args)
+
+			status, err := sh.RunScript(strings.NewReader(tt.script))
+			if err != nil {
+				t.Fatalf("Shell.RunScript() error = %v", err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("Shell.RunScript() status = %d, expected %d", status, tt.wantStatus)
+			}
+
+			data, _ := os.ReadFile(filepath.Join(dir, "out.txt"))
+			if string(data) != tt.wantOutput {
+				t.Errorf("output = %q, expected %q", string(data), tt.wantOutput)
+			}
+		})
+	}
+}
+
+// TestShell_RunScriptStatusNotReported проверяет, что ненулевой код возврата последней
+// команды строки не выводится как ошибка: команда сообщает о проблеме сама.
+func TestShell_RunScriptStatusNotReported(t *testing.T) {
+	sh := NewShell()
+	sh.SetArgs("script.sh", nil)
+
+	var status int
+	stderr := captureStderr(t, func() {
+		var err error
+		status, err = sh.RunScript(strings.NewReader("sh -c 'exit 3' &\nwait %1\ntest a = b\n"))
+		if err != nil {
+			t.Errorf("Shell.RunScript() error = %v", err)
+		}
+	})
+	if status != 1 {
+		t.Errorf("Shell.RunScript() status = %d, expected 1", status)
+	}
+	if stderr != "" {
+		t.Errorf("stderr = %q, expected empty", stderr)
+	}
+}
+
+// captureStderr выполняет fn, перенаправив os.Stderr в канал, и возвращает выведенный текст.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	saved := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = saved }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// TestShell_RunCommand проверяет выполнение команд из строки (gocli -c).
+func TestShell_RunCommand(t *testing.T) {
+	sh := NewShell()
+	dir := t.TempDir()
+	sh.environment.Set("DIR", dir)
+
+	status, err := sh.RunCommand("x=1; echo $x > $DIR/out.txt; exit 5")
+	if err != nil {
+		t.Fatalf("Shell.RunCommand() error = %v", err)
+	}
+	if status != 5 {
+		t.Errorf("Shell.RunCommand() status = %d, expected 5", status)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out.txt")); string(data) != "1\n" {
+		t.Errorf("output = %q, expected %q", string(data), "1\n")
+	}
+}
+
+// TestShell_LoadRC проверяет файл инициализации интерактивного режима: файл из $GOCLI_RC
+// выполняется в текущем shell'е, отсутствующий или отключенный файл пропускается,
+// а exit в файле завершает shell.
+func TestShell_LoadRC(t *testing.T) {
+	dir := t.TempDir()
+	rcFile := filepath.Join(dir, "rc")
+	content := "greeting=hello\ngreet() {\n  echo $greeting\n}\ncd " + dir + "\n"
+	if err := os.WriteFile(rcFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("cannot write rc file: %v", err)
+	}
+	t.Setenv("GOCLI_RC", rcFile)
+	sh := NewShell()
+	if status, exited := sh.loadRC(); exited {
+		t.Fatalf("Shell.loadRC() exited with status %d", status)
+	}
+	if value, _ := sh.environment.Get("greeting"); value != "hello" {
+		t.Errorf("greeting = %q, expected %q", value, "hello")
+	}
+	if _, exists := sh.environment.Function("greet"); !exists {
+		t.Error("function from rc file should be defined")
+	}
+	if current := sh.environment.Dir(); current != dir {
+		t.Errorf("working directory = %q, expected %q", current, dir)
+	}
+
+	// --norc и отсутствующий файл
+	for _, path := range []string{"", filepath.Join(dir, "missing")} {
+		sh := NewShell()
+		sh.SetRCFile(path)
+		if _, exited := sh.loadRC(); exited {
+			t.Errorf("Shell.loadRC() with %q should not exit", path)
+		}
+		if _, exists := sh.environment.Get("greeting"); exists {
+			t.Errorf("rc file should not be read with %q", path)
+		}
+	}
+
+	exitFile := filepath.Join(dir, "exit")
+	if err := os.WriteFile(exitFile, []byte("exit 4\n"), 0o644); err != nil {
+		t.Fatalf("cannot write rc file: %v", err)
+	}
+	sh = NewShell()
+	sh.SetRCFile(exitFile)
+	if status, exited := sh.loadRC(); !exited || status != 4 {
+		t.Errorf("Shell.loadRC() = (%d, %v), expected (4, true)", status, exited)
+	}
+}